@@ -15,84 +15,180 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/watchlist/add": {
-            "post": {
-                "description": "Adds a new watchlist entry to the database",
-                "consumes": [
-                    "application/json"
+        "/v1/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
                 ],
+                "description": "Lists the writes of the entries, newest first. before and after only hold the fields which changed.\nPage with before_id set to the event_id of the last event read",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "audit"
                 ],
-                "summary": "Create a new watchlist item",
+                "summary": "Search the audit",
                 "parameters": [
                     {
-                        "description": "Watchlist Data",
-                        "name": "watchlist",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.WatchListAddRequestExample"
-                        }
+                        "type": "string",
+                        "description": "Basic auth user, anonymous or system",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "create, update, delete, transition, progress, move, restore, purge, rebalance, revert, undo, review, viewing, season, episodes or tag",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "watchlist",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the entity",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Correlation ID of the request",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time, included",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time, excluded",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only events older than this one",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1 to 500, 100 by default",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Watchlist"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEvent"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid WatchList Data",
+                        "description": "Invalid Query",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to add WatchList data",
+                        "description": "Failed to get Audit Events",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/watchlist/all": {
+        "/v1/admin/jobs": {
             "get": {
-                "description": "Retrieves all watchlists from the database.",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Lists a page of the background jobs, newest first, optionally filtered by state\nSucceeded and cancelled jobs are purged JOB_RETENTION after they finished",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "jobs"
+                ],
+                "summary": "List background jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "queued, running, succeeded, dead or cancelled",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1 to 500, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of jobs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all Watchlists",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Watchlist"
+                                "$ref": "#/definitions/models.Job"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid Query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to get All WatchList",
+                        "description": "Failed to get Jobs",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/watchlist/delete": {
-            "delete": {
-                "description": "Removes a watchlist from the database based on the provided watchlist ID",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Adds a job to the queue, the kind must have a registered handler",
                 "consumes": [
                     "application/json"
                 ],
@@ -100,74 +196,166 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "jobs"
                 ],
-                "summary": "Delete a watchlist entry",
+                "summary": "Enqueue a background job",
                 "parameters": [
                     {
-                        "description": "Delete Request (watchlist_id)",
+                        "description": "Job",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.WatchListDeleteRequest"
+                            "$ref": "#/definitions/models.JobEnqueueRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "WatchList deleted successfully",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.Job"
                         }
                     },
                     "400": {
-                        "description": "Invalid WatchList ID",
+                        "description": "Invalid Job",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete WatchList",
+                        "description": "Failed to enqueue Job",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/watchlist/notwatched": {
+        "/v1/admin/jobs/{job_id}": {
             "get": {
-                "description": "Returns all watchlists with a \"not watched\" status from the database",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Returns a job with its attempts and last error",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "jobs"
+                ],
+                "summary": "Inspect a background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Retrieve watchlists that are not watched",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Watchlist"
-                            }
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to get Watching List",
+                        "description": "Failed to get Job by ID",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/watchlist/update": {
-            "patch": {
-                "description": "Updates an existing watchlist with new data",
+        "/v1/admin/jobs/{job_id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Cancels a queued or running job",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancel a background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Job already finished",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to cancel Job",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/watchlist/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Enqueues one \"watchlist.refresh\" job per entry, the job re-fetches the entry metadata from the provider\nAn empty watchlist_ids list refreshes every entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -175,81 +363,148 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "jobs"
                 ],
-                "summary": "Update an existing watchlist entry",
+                "summary": "Refresh watchlist entries in the background",
                 "parameters": [
                     {
-                        "description": "Updated WatchList Data",
+                        "description": "Entries to refresh",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.WatchListUpdateRequestExample"
+                            "$ref": "#/definitions/models.WatchListRefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid refresh request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to enqueue refresh",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
+                }
+            }
+        },
+        "/v1/diary": {
+            "get": {
+                "description": "Lists the viewings of every watchlist between two dates, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Retrieve the watch diary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (inclusive), YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "WatchList updated successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Viewing"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid WatchList Data",
+                        "description": "Invalid date",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to update WatchList",
+                        "description": "Failed to get Diary",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/watchlist/watched": {
+        "/v1/genres": {
             "get": {
-                "description": "Fetches all watchlists with a \"watched\" status from the database",
+                "description": "Lists every genre with the number of watchlist entries tagged with it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "genres"
                 ],
-                "summary": "Retrieve watched watchlists",
+                "summary": "Retrieve all genres",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Watchlist"
+                                "$ref": "#/definitions/models.Genre"
                             }
                         }
                     },
                     "500": {
-                        "description": "Failed to get Watched List",
+                        "description": "Failed to get Genres",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/watchlist/watching": {
+        "/v1/genres/{genre_id}/watchlist": {
             "get": {
-                "description": "Returns all watchlists with a \"watching\" status from the database",
+                "description": "Lists the watchlist entries tagged with the genre whose ID is provided in the path",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "genres"
+                ],
+                "summary": "Retrieve the watchlist of a genre",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Genre ID",
+                        "name": "genre_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Retrieve watchlists with \"watching\" status",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -260,30 +515,36 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Genre not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to get Watching List",
+                        "description": "Failed to get WatchList by genre",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/watchlist/{watchlist_id}": {
+        "/v1/import/jobs/{job_id}": {
             "get": {
-                "description": "Fetches the watchlist whose ID is provided in the path",
+                "description": "Returns the progress of a background import started by /import/trakt",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "watchlists"
+                    "import"
                 ],
-                "summary": "Retrieve a watchlist by ID",
+                "summary": "Get the status of an import job",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Watchlist ID",
-                        "name": "watchlist_id",
+                        "type": "integer",
+                        "description": "Import Job ID",
+                        "name": "job_id",
                         "in": "path",
                         "required": true
                     }
@@ -292,31 +553,4440 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Watchlist"
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Import job not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to get WatchList by ID",
+                        "description": "Failed to get Import job",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "gin.H": {
-            "type": "object",
-            "additionalProperties": {}
         },
-        "models.WatchListAddRequestExample": {
-            "type": "object",
-            "required": [
-                "director",
-                "genre",
-                "release_year",
-                "status",
+        "/v1/import/trakt": {
+            "post": {
+                "description": "Starts a background import of a Trakt JSON export (history, watchlist, ratings).\nSend the files either as a JSON body or as multipart form files named history, watchlist and ratings.\nTitles already present in the watchlist are counted as duplicates and skipped.\nThe import is a watchlist.import job, it also shows up in /admin/jobs",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import a Trakt export",
+                "parameters": [
+                    {
+                        "description": "Trakt export files",
+                        "name": "export",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TraktImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Trakt export",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to start the import",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/lists": {
+            "get": {
+                "description": "Lists every list with its number of entries, whatever its visibility",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Retrieve all lists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.List"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Lists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a list, names are unique case-insensitively and the visibility defaults to private\na list created with a filter is a smart list, its entries are the watchlist entries matching the filter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Create a list",
+                "parameters": [
+                    {
+                        "description": "List",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid List Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "List already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/lists/{list_id}": {
+            "get": {
+                "description": "Returns the list with its entries in order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Retrieve a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.List"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the list, its entries stay in the watchlist",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Delete a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes the name, description, visibility, filter or pin of a list, fields which are not sent are kept\nonly smart lists take a filter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Update a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ListUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid List Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "List already exists or is not a smart list",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/lists/{list_id}/entries": {
+            "get": {
+                "description": "Returns a page of the entries of a list, a smart list is evaluated against the watchlist on every call",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Retrieve the entries of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page, 20 by default and at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListEntriesPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get List Entries",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a watchlist entry at the position, or at the end, the entries from the position on move down by one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Add an entry to a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Entry",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ListEntryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid List Entry Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "List or WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Entry is already in the List or the List is a smart list",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add List Entry",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/lists/{list_id}/entries/{watchlist_id}": {
+            "delete": {
+                "description": "Removes the entry from the list only, the entries after it move up by one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Remove an entry from a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List Entry removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove List Entry",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/lists/{list_id}/order": {
+            "put": {
+                "description": "Gives the entries of the list the order sent, every entry of the list must be sent once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Reorder a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "list_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Entries in their new order",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ListOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid List Order",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "List is a smart list",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reorder List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/metadata/lookup": {
+            "get": {
+                "description": "Fetches canonical title, release year, genres, directors, runtime, poster path and external IDs from the metadata provider",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metadata"
+                ],
+                "summary": "Look up metadata for a title",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Title to look up",
+                        "name": "title",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Release year",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Metadata"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid lookup",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No metadata found for title",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Failed to fetch metadata",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/people/{person_id}/watchlist": {
+            "get": {
+                "description": "Lists the watchlist entries the person is credited on, optionally for a single role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Retrieve the watchlist of a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "person_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "director, writer or actor",
+                        "name": "role",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get WatchList by person",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/shared/lists": {
+            "get": {
+                "description": "Lists the public lists, unlisted lists are only opened with their share token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Retrieve the public lists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.List"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Lists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/shared/lists/{share_token}": {
+            "get": {
+                "description": "Returns an unlisted or public list by its share token, private lists are not found",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Retrieve a shared list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token of the list",
+                        "name": "share_token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.List"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/shared/lists/{share_token}/entries": {
+            "get": {
+                "description": "Returns a page of the entries of an unlisted or public list by its share token, private lists are not found",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lists"
+                ],
+                "summary": "Retrieve the entries of a shared list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token of the list",
+                        "name": "share_token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page, 20 by default and at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListEntriesPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get List Entries",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tags": {
+            "get": {
+                "description": "Lists every tag with the number of watchlist entries tagged with it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Retrieve all tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Tags",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a tag, names are unique case-insensitively",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create a tag",
+                "parameters": [
+                    {
+                        "description": "Tag",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Tag Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Tag already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add Tag",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tags/{tag_id}": {
+            "put": {
+                "description": "Renames the tag on every entry, merge the tags instead when the name is taken",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Rename a tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Tag Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Tag already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to rename Tag",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the tag from every entry and deletes it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Delete a tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete Tag",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tags/{tag_id}/merge": {
+            "post": {
+                "description": "Moves the entries of the listed tags to the tag of the path and deletes the listed tags",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Merge tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag ID to keep",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags to merge",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TagMergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Tag Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to merge Tags",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash": {
+            "get": {
+                "description": "Fetches the deleted entries which are not purged yet, the last deleted first.\nAn entry is purged TRASH_RETENTION after it was deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Retrieve the trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Trash",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/undo/{undo_token}": {
+            "post": {
+                "description": "Puts the entries a request wrote back as they were before it, with the X-Undo-Token of its response.\nA created entry goes to the trash and a deleted one comes back. The token can be used for UNDO_TTL,\nthe undo has its own token so it can be undone too",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "Undo the writes of a request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "X-Undo-Token of the response",
+                        "name": "undo_token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "X-Undo-Token": {
+                                "type": "string",
+                                "description": "Token which undoes the undo"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Undo token not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList changed since",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to undo",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist": {
+            "get": {
+                "description": "Retrieves all watchlists from the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Get all Watchlists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating (0.5 to 5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum average rating (0.5 to 5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query like genre:action year:\u003e2000 status:watched",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "W/\\\"7-5f0c2a9b\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid list query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get All WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/add": {
+            "post": {
+                "description": "Adds a new watchlist entry to the database\nWith enrich=true only the title is required, the other fields are fetched from the metadata provider (models.WatchListEnrichRequest)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Create a new watchlist item",
+                "parameters": [
+                    {
+                        "description": "Watchlist Data",
+                        "name": "watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListAddRequestExample"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fill missing fields from the metadata provider",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key to retry the request safely, a retry gets the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid WatchList Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No metadata found for title",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key sent with another request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add WatchList data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Failed to fetch metadata",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/all": {
+            "get": {
+                "description": "Retrieves all watchlists from the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Get all Watchlists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating (0.5 to 5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum average rating (0.5 to 5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query like genre:action year:\u003e2000 status:watched",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "W/\\\"7-5f0c2a9b\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid list query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get All WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/by-external/{provider}/{external_id}": {
+            "get": {
+                "description": "Fetches the watchlist linked to an IMDb, TMDb or Wikidata ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Retrieve a watchlist by external ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "imdb, tmdb or wikidata",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID on the provider (tt2380307, 354912, Q27188178)",
+                        "name": "external_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid provider",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get WatchList by external ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/continue": {
+            "get": {
+                "description": "Lists the entries being watched, the most recently updated progress first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Continue watching",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating (0.5 to 5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum average rating (0.5 to 5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query like genre:action year:\u003e2000 status:watched",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "W/\\\"7-5f0c2a9b\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid list query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Continue Watching",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/delete": {
+            "delete": {
+                "description": "Moves the watchlist with the provided ID to the trash, it can be restored until it is purged TRASH_RETENTION later",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Delete a watchlist entry",
+                "parameters": [
+                    {
+                        "description": "Delete Request (watchlist_id)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListDeleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "WatchList deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid WatchList ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/notwatched": {
+            "get": {
+                "description": "Returns all watchlists with a \"not watched\" status from the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Retrieve watchlists that are not watched",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating (0.5 to 5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum average rating (0.5 to 5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query like genre:action year:\u003e2000 status:watched",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "W/\\\"7-5f0c2a9b\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid list query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Watching List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/tags": {
+            "post": {
+                "description": "Adds the tags to every listed entry, missing tags are created and unknown entries are skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag many watchlist entries",
+                "parameters": [
+                    {
+                        "description": "Entries and tags",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags added successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Tag Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to tag WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the tags from every listed entry, the tags themselves are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Untag many watchlist entries",
+                "parameters": [
+                    {
+                        "description": "Entries and tags",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Tag Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to untag WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/update": {
+            "patch": {
+                "description": "Updates an existing watchlist with new data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Update an existing watchlist entry",
+                "parameters": [
+                    {
+                        "description": "Updated WatchList Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListUpdateRequestExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "WatchList updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid WatchList Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Status transition not allowed or WatchList already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/watched": {
+            "get": {
+                "description": "Fetches all watchlists with a \"watched\" status from the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Retrieve watched watchlists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating (0.5 to 5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum average rating (0.5 to 5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query like genre:action year:\u003e2000 status:watched",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "W/\\\"7-5f0c2a9b\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid list query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Watched List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/watching": {
+            "get": {
+                "description": "Returns all watchlists with a \"watching\" status from the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Retrieve watchlists with \"watching\" status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating (0.5 to 5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum average rating (0.5 to 5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query like genre:action year:\u003e2000 status:watched",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watchlist"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "W/\\\"7-5f0c2a9b\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid list query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Watching List",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}": {
+            "get": {
+                "description": "Fetches the watchlist whose ID is provided in the path",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Retrieve a watchlist by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached entry",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "\\\"3\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid WatchList ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get WatchList by ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/history": {
+            "get": {
+                "description": "Lists the writes of the entry in the order they happened, also while it is in the trash and after it was purged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Retrieve the history of a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEvent"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get WatchList History",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/move": {
+            "post": {
+                "description": "Places the entry right before the entry before, right after the entry after, or between both when both are sent.\nOnly the moved entry is updated. The order is read with sort=rank, a conflict means it changed and must be reloaded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Move a watchlist entry in the manual order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Anchors",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListMoveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid move",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Order changed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to move WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/progress": {
+            "put": {
+                "description": "Stores where playback stopped. Positions before the stored one are ignored unless reset is true, so updates can be replayed.\nThe entry becomes watching, and watched once the position passes the completion threshold of the runtime",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Save the playback position of a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playback position",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProgressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid progress",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update progress",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/restore": {
+            "post": {
+                "description": "Takes a deleted entry out of the trash with its tags, credits, review and viewings, it goes to the end of the manual order.\nLists it was in are not restored",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Restore a watchlist entry from the trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/revert": {
+            "post": {
+                "description": "Writes the content of the version back as a new version, the place in the manual order is kept.\nThe status and its timeline are written back as they were, without the lifecycle",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "Revert a watchlist entry to an old version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to revert to",
+                        "name": "version",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        },
+                        "headers": {
+                            "X-Undo-Token": {
+                                "type": "string",
+                                "description": "Token which undoes the revert"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList Version not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revert WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/review": {
+            "get": {
+                "description": "Fetches the rating and review of the watchlist with its edit history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Retrieve the review of a watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
+                    },
+                    "404": {
+                        "description": "Review not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Review",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the rating and review text, the previous version is kept in the history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Edit the review of a watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Review Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Review not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update Review",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Rates a watchlist in half stars (0.5 to 5) with an optional review text",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Review a watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Review Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList is already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add Review",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the rating and review of the watchlist with its history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Delete the review of a watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Review deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Review not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete Review",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/seasons": {
+            "get": {
+                "description": "Lists the seasons of the watchlist with their episodes and when they were watched",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Retrieve the seasons of a series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Season"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Seasons",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/seasons/{season_number}": {
+            "put": {
+                "description": "Creates the season with its episodes, or adds the missing episodes when it exists. Watched episodes stay watched",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Create or extend a season",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season number, 0 for specials",
+                        "name": "season_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Season",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SeasonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Season"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Season Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList is not a series",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save Season",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the season and its episodes from the watchlist",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Delete a season",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season number",
+                        "name": "season_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Season deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season number",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete Season",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/seasons/{season_number}/watched": {
+            "post": {
+                "description": "Marks the whole season, or the episodes from and to (inclusive), as watched in one call.\nThe series becomes watching, and watched once every episode is watched",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Mark episodes as watched",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season number",
+                        "name": "season_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Episodes, the whole season when empty",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.EpisodeRangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid episode range",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Season not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to mark Episodes",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Clears the watched date of the whole season, or of the episodes from and to (inclusive)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Mark episodes as not watched",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season number",
+                        "name": "season_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Episodes, the whole season when empty",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.EpisodeRangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid episode range",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Season not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to mark Episodes",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/transition": {
+            "post": {
+                "description": "Statuses follow the lifecycle not watched -\u003e watching -\u003e watched, with on hold and dropped on the side.\nstarted_at, finished_at and status_changed_at are set on the way, at defaults to now",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Move a watchlist entry to another status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListTransitionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Status transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to change WatchList status",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/versions": {
+            "get": {
+                "description": "Lists the content the entry had after each of its writes, oldest first.\nEntries from before the versions start with the one they had before their first write",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "Retrieve the versions of a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WatchListVersion"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get WatchList Versions",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/versions/diff": {
+            "get": {
+                "description": "Returns the fields which differ between the versions, before holds them as in from and after as in to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "Compare two versions of a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListVersionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Query",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList Version not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compare WatchList Versions",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/{watchlist_id}/viewings": {
+            "get": {
+                "description": "Lists every logged viewing of the watchlist, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Retrieve the viewings of a watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Viewing"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get Viewings",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Records a dated viewing of the watchlist and marks it as watched, later viewings count as rewatches",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Log a viewing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Viewing",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ViewingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Viewing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Viewing Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add Viewing",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/watchlist": {
+            "post": {
+                "description": "Creates an entry, the Location header is the URL of the new entry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists v2"
+                ],
+                "summary": "Create a watchlist entry",
+                "parameters": [
+                    {
+                        "description": "Watchlist Data",
+                        "name": "watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListAddRequestExample"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key to retry the request safely, a retry gets the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "\\\"1\\"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "/api/v2/watchlist/{watchlist_id}"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed JSON",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid WatchList Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/watchlist/batch": {
+            "post": {
+                "description": "Runs the operations in order in one transaction. With atomic=true (the default) they are all written or none:\nthe first failing operation is answered with its problem and its index. With atomic=false each operation\nis written or fails on its own and the results hold the status and the problem of each one.\nAn update is a full replace like PUT, version is like If-Match. A batch has at most BATCH_MAX_SIZE operations, 100 by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists v2"
+                ],
+                "summary": "Create, update and delete many watchlist entries",
+                "parameters": [
+                    {
+                        "description": "Operations",
+                        "name": "operations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WatchListBatchOperation"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "All or nothing, true by default",
+                        "name": "atomic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WatchListBatchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed JSON",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList already exists or status transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "WatchList changed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid operation or too many operations",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to run the batch",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/watchlist/{watchlist_id}": {
+            "get": {
+                "description": "The ETag is the version of the entry, with If-None-Match the entry is only sent when it changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists v2"
+                ],
+                "summary": "Retrieve a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached entry",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "\\\"3\\"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the fields of an entry like PATCH /api/v1/watchlist/update, the ID comes from the path",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists v2"
+                ],
+                "summary": "Replace a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the entry",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "WatchList Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListUpdateRequestExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "\\\"4\\"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed JSON",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "WatchList already exists or status transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "WatchList changed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid WatchList Data",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Moves the entry to the trash, see POST /v1/watchlist/{watchlist_id}/restore",
+                "tags": [
+                    "watchlists v2"
+                ],
+                "summary": "Delete a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the entry",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "WatchList changed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a merge patch (application/merge-patch+json, RFC 7396) or a JSON patch (application/json-patch+json, RFC 6902)\nto the entry and validates the result, application/json is read as a merge patch.\nThe patch applies to the fields of models.WatchListDocument, any other field is rejected.\nChanging genre without genres or director without credits replaces them",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists v2"
+                ],
+                "summary": "Update some fields of a watchlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Watchlist ID",
+                        "name": "watchlist_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the entry",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch, or an array of JSON patch operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchListDocument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Watchlist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "\\\"4\\"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed JSON or invalid patch",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "WatchList not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Patch test failed, WatchList already exists or status transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "WatchList changed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported patch format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid WatchList Data or patch path not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update WatchList",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "gin.H": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "saket"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "entity": {
+                    "type": "string",
+                    "example": "watchlist"
+                },
+                "entity_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "5f0c2a9b6d1e4c3a8b7f6e5d4c3b2a19"
+                }
+            }
+        },
+        "models.BulkTagRequest": {
+            "type": "object",
+            "required": [
+                "tags",
+                "watchlist_ids"
+            ],
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "date night",
+                        "with kids"
+                    ]
+                },
+                "watchlist_ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        7
+                    ]
+                }
+            }
+        },
+        "models.Credit": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Lee Unkrich"
+                },
+                "person_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "director",
+                        "writer",
+                        "actor"
+                    ],
+                    "example": "director"
+                }
+            }
+        },
+        "models.Episode": {
+            "type": "object",
+            "properties": {
+                "air_date": {
+                    "type": "string",
+                    "example": "2008-01-20T00:00:00Z"
+                },
+                "episode_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "episode_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "season_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Pilot"
+                },
+                "watched_at": {
+                    "type": "string",
+                    "example": "2025-06-20T21:00:00Z"
+                }
+            }
+        },
+        "models.EpisodeRangeRequest": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2025-06-20T21:00:00Z"
+                },
+                "from": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "to": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4
+                }
+            }
+        },
+        "models.EpisodeRequest": {
+            "type": "object",
+            "required": [
+                "episode_number"
+            ],
+            "properties": {
+                "air_date": {
+                    "type": "string",
+                    "example": "2008-01-20T00:00:00Z"
+                },
+                "episode_number": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Pilot"
+                }
+            }
+        },
+        "models.ExternalIDs": {
+            "type": "object",
+            "properties": {
+                "imdb_id": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "tt2380307"
+                },
+                "tmdb_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 354912
+                },
+                "wikidata_id": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "Q27188178"
+                }
+            }
+        },
+        "models.Genre": {
+            "type": "object",
+            "properties": {
+                "genre_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Animation"
+                },
+                "watchlist_count": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ImportItemError": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportItemError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "job_id": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "result": {
+                    "description": "Result is what the job reported, like the progress of an import",
+                    "type": "object"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JobEnqueueRequest": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "example": "watchlist.refresh"
+                },
+                "max_attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "payload": {
+                    "type": "object"
+                }
+            }
+        },
+        "models.List": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "One scary movie a night"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ListEntry"
+                    }
+                },
+                "entry_count": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "filter": {
+                    "$ref": "#/definitions/models.SmartFilter"
+                },
+                "list_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Horror October"
+                },
+                "pinned": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "share_token": {
+                    "type": "string",
+                    "example": "9f86d081884c7d65"
+                },
+                "smart": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "type": "string",
+                    "example": "unlisted"
+                }
+            }
+        },
+        "models.ListEntriesPage": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ListEntry"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ListEntry": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "watchlist": {
+                    "$ref": "#/definitions/models.Watchlist"
+                }
+            }
+        },
+        "models.ListEntryRequest": {
+            "type": "object",
+            "required": [
+                "watchlist_id"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 7
+                }
+            }
+        },
+        "models.ListOrderRequest": {
+            "type": "object",
+            "required": [
+                "watchlist_ids"
+            ],
+            "properties": {
+                "watchlist_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        7,
+                        2,
+                        5
+                    ]
+                }
+            }
+        },
+        "models.ListRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "One scary movie a night"
+                },
+                "filter": {
+                    "$ref": "#/definitions/models.SmartFilter"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Horror October"
+                },
+                "pinned": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "visibility": {
+                    "type": "string",
+                    "enum": [
+                        "private",
+                        "unlisted",
+                        "public"
+                    ],
+                    "example": "private"
+                }
+            }
+        },
+        "models.ListUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "One scary movie a night"
+                },
+                "filter": {
+                    "$ref": "#/definitions/models.SmartFilter"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Horror October"
+                },
+                "pinned": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "visibility": {
+                    "type": "string",
+                    "enum": [
+                        "private",
+                        "unlisted",
+                        "public"
+                    ],
+                    "example": "public"
+                }
+            }
+        },
+        "models.Metadata": {
+            "type": "object",
+            "properties": {
+                "directors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Lee Unkrich"
+                    ]
+                },
+                "external_ids": {
+                    "$ref": "#/definitions/models.ExternalIDs"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Animation",
+                        "Family"
+                    ]
+                },
+                "poster_path": {
+                    "type": "string",
+                    "example": "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "tmdb"
+                },
+                "release_year": {
+                    "type": "integer",
+                    "example": 2017
+                },
+                "runtime_minutes": {
+                    "type": "integer",
+                    "example": 105
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Coco"
+                }
+            }
+        },
+        "models.ProgressRequest": {
+            "type": "object",
+            "required": [
+                "position_seconds"
+            ],
+            "properties": {
+                "position_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3600
+                },
+                "reset": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "runtime": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 105
+                }
+            }
+        },
+        "models.Review": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReviewRevision"
+                    }
+                },
+                "rating": {
+                    "type": "number",
+                    "example": 4.5
+                },
+                "review_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "spoiler": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "text": {
+                    "type": "string",
+                    "example": "The final act is a masterpiece"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.ReviewRequest": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "rating": {
+                    "type": "number",
+                    "maximum": 5,
+                    "minimum": 0.5,
+                    "example": 4.5
+                },
+                "spoiler": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "text": {
+                    "type": "string",
+                    "example": "The final act is a masterpiece"
+                }
+            }
+        },
+        "models.ReviewRevision": {
+            "type": "object",
+            "properties": {
+                "edited_at": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number",
+                    "example": 4
+                },
+                "revision_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "spoiler": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Great ending"
+                }
+            }
+        },
+        "models.Season": {
+            "type": "object",
+            "properties": {
+                "episodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Episode"
+                    }
+                },
+                "season_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "season_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Season 1"
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.SeasonRequest": {
+            "type": "object",
+            "properties": {
+                "episode_count": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 7
+                },
+                "episodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EpisodeRequest"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Season 1"
+                }
+            }
+        },
+        "models.SeriesProgress": {
+            "type": "object",
+            "properties": {
+                "episode_count": {
+                    "type": "integer",
+                    "example": 62
+                },
+                "next_episode": {
+                    "$ref": "#/definitions/models.Episode"
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 11.3
+                },
+                "watched_count": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.SmartFilter": {
+            "type": "object",
+            "required": [
+                "genres",
+                "tags"
+            ],
+            "properties": {
+                "genres": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Science Fiction"
+                    ]
+                },
+                "kinds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "movie"
+                    ]
+                },
+                "max_rating": {
+                    "type": "number",
+                    "maximum": 5,
+                    "minimum": 0.5,
+                    "example": 5
+                },
+                "max_year": {
+                    "type": "integer",
+                    "maximum": 3000,
+                    "minimum": 1800,
+                    "example": 1999
+                },
+                "min_rating": {
+                    "type": "number",
+                    "maximum": 5,
+                    "minimum": 0.5,
+                    "example": 4
+                },
+                "min_year": {
+                    "type": "integer",
+                    "maximum": 3000,
+                    "minimum": 1800,
+                    "example": 1990
+                },
+                "sort": {
+                    "type": "string",
+                    "enum": [
+                        "rating",
+                        "-rating",
+                        "title",
+                        "-title",
+                        "release_year",
+                        "-release_year",
+                        "added_date",
+                        "-added_date",
+                        "progress_updated_at",
+                        "-progress_updated_at",
+                        "rank",
+                        "-rank"
+                    ],
+                    "example": "-rating"
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "not watched"
+                    ]
+                },
+                "tag_match": {
+                    "type": "string",
+                    "enum": [
+                        "any",
+                        "all"
+                    ],
+                    "example": "all"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "date night"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "star"
+                }
+            }
+        },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "date night"
+                },
+                "tag_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "watchlist_count": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "models.TagMergeRequest": {
+            "type": "object",
+            "required": [
+                "tag_ids"
+            ],
+            "properties": {
+                "tag_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "models.TagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "date night"
+                }
+            }
+        },
+        "models.TraktImportRequest": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "ratings": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "watchlist": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "models.Viewing": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Home"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Watched with the family"
+                },
+                "platform": {
+                    "type": "string",
+                    "example": "Disney+"
+                },
+                "rating": {
+                    "type": "number",
+                    "example": 4.5
+                },
+                "rewatch": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Coco"
+                },
+                "viewing_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "watched_on": {
+                    "type": "string",
+                    "example": "2025-06-20T21:00:00Z"
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.ViewingRequest": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "type": "string",
+                    "example": "Home"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Watched with the family"
+                },
+                "platform": {
+                    "type": "string",
+                    "example": "Disney+"
+                },
+                "rating": {
+                    "type": "number",
+                    "maximum": 5,
+                    "minimum": 0.5,
+                    "example": 4.5
+                },
+                "watched_on": {
+                    "type": "string",
+                    "example": "2025-06-20T21:00:00Z"
+                }
+            }
+        },
+        "models.WatchListAddRequestExample": {
+            "type": "object",
+            "required": [
+                "release_year",
+                "status",
                 "title"
             ],
             "properties": {
@@ -324,28 +4994,126 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-06-20T00:00:00Z"
                 },
+                "credits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Credit"
+                    }
+                },
                 "director": {
                     "type": "string",
                     "example": "Lee Unkrich"
                 },
+                "external_ids": {
+                    "$ref": "#/definitions/models.ExternalIDs"
+                },
                 "genre": {
                     "type": "string",
-                    "example": "Animation"
+                    "example": "Animation, Family"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Animation",
+                        "Family"
+                    ]
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "movie"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Recommended by **Priya**"
+                },
+                "poster_path": {
+                    "type": "string",
+                    "example": "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg"
                 },
                 "release_year": {
                     "type": "integer",
                     "example": 2017
                 },
+                "runtime": {
+                    "type": "integer",
+                    "example": 105
+                },
                 "status": {
                     "type": "string",
                     "example": "not watched"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "date night",
+                        "with kids"
+                    ]
+                },
                 "title": {
                     "type": "string",
                     "example": "Coco"
                 }
             }
         },
+        "models.WatchListBatchOperation": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "item": {
+                    "type": "object"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "version": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 7
+                }
+            }
+        },
+        "models.WatchListBatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "object"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "item": {
+                    "$ref": "#/definitions/models.Watchlist"
+                },
+                "op": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
         "models.WatchListDeleteRequest": {
             "type": "object",
             "required": [
@@ -357,11 +5125,133 @@ const docTemplate = `{
                 }
             }
         },
+        "models.WatchListDocument": {
+            "type": "object",
+            "required": [
+                "added_date",
+                "kind",
+                "release_year",
+                "status",
+                "title"
+            ],
+            "properties": {
+                "added_date": {
+                    "type": "string"
+                },
+                "credits": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "items": {
+                        "$ref": "#/definitions/models.Credit"
+                    }
+                },
+                "director": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "external_ids": {
+                    "$ref": "#/definitions/models.ExternalIDs"
+                },
+                "genre": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "genres": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "movie",
+                        "series",
+                        "miniseries",
+                        "documentary",
+                        "short"
+                    ]
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 20000
+                },
+                "poster_path": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "release_year": {
+                    "type": "integer"
+                },
+                "runtime": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 300
+                }
+            }
+        },
+        "models.WatchListMoveRequest": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "before": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.WatchListRefreshRequest": {
+            "type": "object",
+            "properties": {
+                "watchlist_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "models.WatchListTransitionRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2025-06-20T21:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "watching"
+                }
+            }
+        },
         "models.WatchListUpdateRequestExample": {
             "type": "object",
             "required": [
-                "director",
-                "genre",
                 "release_year",
                 "status",
                 "title",
@@ -372,22 +5262,67 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-06-20T00:00:00Z"
                 },
+                "credits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Credit"
+                    }
+                },
                 "director": {
                     "type": "string",
                     "example": "Lee Unkrich"
                 },
+                "external_ids": {
+                    "$ref": "#/definitions/models.ExternalIDs"
+                },
                 "genre": {
                     "type": "string",
                     "example": "Animation"
                 },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Animation",
+                        "Family"
+                    ]
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "movie"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Recommended by **Priya**"
+                },
+                "poster_path": {
+                    "type": "string",
+                    "example": "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg"
+                },
                 "release_year": {
                     "type": "integer",
                     "example": 2017
                 },
+                "runtime": {
+                    "type": "integer",
+                    "example": 105
+                },
                 "status": {
                     "type": "string",
                     "example": "watching"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "date night",
+                        "with kids"
+                    ]
+                },
                 "title": {
                     "type": "string",
                     "example": "Coco"
@@ -398,39 +5333,245 @@ const docTemplate = `{
                 }
             }
         },
+        "models.WatchListVersion": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "anonymous"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entry": {
+                    "$ref": "#/definitions/models.Watchlist"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.WatchListVersionDiff": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "watchlist_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
         "models.Watchlist": {
             "type": "object",
             "required": [
-                "added_date",
-                "director",
-                "genre",
                 "release_year",
                 "status",
                 "title"
             ],
             "properties": {
                 "added_date": {
+                    "description": "defaults to today",
+                    "type": "string"
+                },
+                "average_rating": {
+                    "description": "aggregates of the reviews and viewings, they are read-only",
+                    "type": "number"
+                },
+                "credits": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "items": {
+                        "$ref": "#/definitions/models.Credit"
+                    }
+                },
+                "deleted_at": {
+                    "description": "set while the entry is in the trash, it is purged TRASH_RETENTION after",
                     "type": "string"
                 },
                 "director": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "external_ids": {
+                    "$ref": "#/definitions/models.ExternalIDs"
+                },
+                "finished_at": {
                     "type": "string"
                 },
                 "genre": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "genres": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kind": {
+                    "description": "defaults to movie",
+                    "type": "string",
+                    "enum": [
+                        "movie",
+                        "series",
+                        "miniseries",
+                        "documentary",
+                        "short"
+                    ]
+                },
+                "notes": {
+                    "description": "markdown",
+                    "type": "string",
+                    "maxLength": 20000
+                },
+                "position_seconds": {
+                    "type": "integer"
+                },
+                "poster_path": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "progress": {
+                    "description": "only set on series, miniseries and documentaries, it is read-only",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SeriesProgress"
+                        }
+                    ]
+                },
+                "progress_updated_at": {
                     "type": "string"
                 },
+                "rank": {
+                    "description": "rank is the key of the manual order, it is set through POST /watchlist/{id}/move",
+                    "type": "string"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
                 "release_year": {
                     "type": "integer"
                 },
+                "rewatch_count": {
+                    "type": "integer"
+                },
+                "runtime": {
+                    "description": "runtime is in minutes, 0 when unknown\nposter_path is the poster on the image server of the metadata provider, empty when unknown\nposition_seconds is where playback stopped, it is set through PUT /watchlist/{id}/progress",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "started_at": {
+                    "description": "timeline of the status lifecycle, they are read-only",
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 },
-                "title": {
+                "status_changed_at": {
                     "type": "string"
                 },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 300
+                },
+                "version": {
+                    "description": "version goes up by one on every write of the entry but a move, it is the ETag of the v2 routes and it is read-only",
+                    "type": "integer"
+                },
+                "viewing_count": {
+                    "type": "integer"
+                },
                 "watchlist_id": {
                     "type": "integer"
                 }
             }
+        },
+        "problem.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "release_year"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "release_year is required"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "problem.Problem": {
+            "type": "object",
+            "properties": {
+                "correlation_id": {
+                    "type": "string",
+                    "example": "5f0c2a9b6d1e4c3a8b7f6e5d4c3b2a19"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Invalid WatchList Data"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/watchlist/add"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Validation failed"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "https://github.com/saketV8/cine-dots/blob/main/docs/problems.md#validation"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BasicAuth": {
+            "type": "basic"
         }
     }
 }`
@@ -439,7 +5580,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:9090",
-	BasePath:         "/",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "Cine-Dots WatchList API",
 	Description:      "A watchlist tracker application built with the Gin framework.",
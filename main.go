@@ -3,11 +3,18 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/saketV8/cine-dots/pkg/database"
 	"github.com/saketV8/cine-dots/pkg/handlers"
+	"github.com/saketV8/cine-dots/pkg/importer"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/metadata"
 	"github.com/saketV8/cine-dots/pkg/repositories"
 	"github.com/saketV8/cine-dots/pkg/router"
+	"github.com/saketV8/cine-dots/pkg/utils"
 
 	// log "github.com/sirupsen/logrus"
 
@@ -18,7 +25,8 @@ import (
 // @version         1.0
 // @description     A watchlist tracker application built with the Gin framework.
 // @host            localhost:9090
-// @BasePath        /
+// @BasePath        /api
+// @securityDefinitions.basic  BasicAuth
 func main() {
 	// log.SetReportCaller(true)
 
@@ -34,12 +42,135 @@ func main() {
 	}
 
 	// passing the DB via dependency injection
+	if threshold, err := strconv.ParseFloat(os.Getenv("COMPLETION_THRESHOLD"), 64); err == nil && threshold > 0 && threshold <= 1 {
+		utils.COMPLETION_THRESHOLD = threshold
+	}
+	if size, err := strconv.Atoi(os.Getenv("BATCH_MAX_SIZE")); err == nil && size > 0 {
+		utils.BATCH_MAX_SIZE = size
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("UNDO_TTL")); err == nil && ttl > 0 {
+		utils.UNDO_TTL = ttl
+	}
+	watchListModel := &repositories.WatchListModel{
+		DB:                  db.DB,
+		CompletionThreshold: utils.COMPLETION_THRESHOLD,
+		MaxBatchSize:        utils.BATCH_MAX_SIZE,
+		UndoTTL:             utils.UNDO_TTL,
+	}
+
+	// metadata enrichment is only enabled when a TMDb API key is provided
+	var metadataProvider metadata.MetadataProvider
+	if apiKey := os.Getenv("TMDB_API_KEY"); apiKey != "" {
+		metadataProvider = metadata.NewTMDbProvider(apiKey, &repositories.MetadataCacheModel{
+			DB: db.DB,
+		})
+	}
+
+	// background jobs, the refresh job needs the metadata provider
+	jobModel := &repositories.JobModel{
+		DB: db.DB,
+	}
+	if workers, err := strconv.Atoi(os.Getenv("JOB_WORKERS")); err == nil && workers > 0 {
+		utils.JOB_WORKERS = workers
+	}
+	jobPool := jobs.NewPool(jobModel, utils.JOB_WORKERS)
+	jobPool.Register(jobs.WatchListImportKind, jobs.NewWatchListImportHandler(importer.Stores{
+		WatchLists: watchListModel,
+		Reviews:    &repositories.ReviewModel{DB: db.DB},
+		Viewings:   &repositories.ViewingModel{DB: db.DB},
+	}, jobModel))
+	// finished jobs are kept for JOB_RETENTION, the scheduled jobs add one row every run
+	if retention, err := time.ParseDuration(os.Getenv("JOB_RETENTION")); err == nil && retention >= 0 {
+		utils.JOB_RETENTION = retention
+	}
+	jobPool.Register(jobs.JobPurgeKind, jobs.NewJobPurgeHandler(jobModel, utils.JOB_RETENTION))
+	jobPool.Every(jobs.JobPurgeKind, utils.JOB_PURGE_INTERVAL)
+
+	jobPool.Register(jobs.WatchListRebalanceKind, jobs.NewWatchListRebalanceHandler(watchListModel))
+	if interval, err := time.ParseDuration(os.Getenv("RANK_REBALANCE_INTERVAL")); err == nil && interval > 0 {
+		utils.RANK_REBALANCE_INTERVAL = interval
+	}
+	jobPool.Every(jobs.WatchListRebalanceKind, utils.RANK_REBALANCE_INTERVAL)
+
+	// deleted entries stay in the trash for TRASH_RETENTION, then they are purged for good
+	if retention, err := time.ParseDuration(os.Getenv("TRASH_RETENTION")); err == nil && retention >= 0 {
+		utils.TRASH_RETENTION = retention
+	}
+	jobPool.Register(jobs.WatchListPurgeKind, jobs.NewWatchListPurgeHandler(watchListModel, utils.TRASH_RETENTION))
+	jobPool.Every(jobs.WatchListPurgeKind, utils.TRASH_PURGE_INTERVAL)
+
+	// the responses of the requests sent with an Idempotency-Key, the expired ones are purged
+	idempotencyModel := &repositories.IdempotencyModel{
+		DB: db.DB,
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL")); err == nil && ttl > 0 {
+		utils.IDEMPOTENCY_TTL = ttl
+	}
+	jobPool.Register(jobs.IdempotencyPurgeKind, jobs.NewIdempotencyPurgeHandler(idempotencyModel))
+	jobPool.Every(jobs.IdempotencyPurgeKind, utils.IDEMPOTENCY_PURGE_INTERVAL)
+
+	if metadataProvider != nil {
+		jobPool.Register(jobs.WatchListRefreshKind, jobs.NewWatchListRefreshHandler(watchListModel, metadataProvider))
+	}
+
 	app := &router.App{
 		WatchListHandler: &handlers.WatchListHandler{
-			WatchListModel: &repositories.WatchListModel{
+			WatchListModel:   watchListModel,
+			MetadataProvider: metadataProvider,
+		},
+		ImportHandler: &handlers.ImportHandler{
+			JobModel: jobModel,
+		},
+		MetadataHandler: &handlers.MetadataHandler{
+			MetadataProvider: metadataProvider,
+		},
+		JobHandler: &handlers.JobHandler{
+			JobModel:       jobModel,
+			WatchListModel: watchListModel,
+			Pool:           jobPool,
+		},
+		GenreHandler: &handlers.GenreHandler{
+			GenreModel: &repositories.GenreModel{
+				DB: db.DB,
+			},
+		},
+		PersonHandler: &handlers.PersonHandler{
+			PersonModel: &repositories.PersonModel{
+				DB: db.DB,
+			},
+		},
+		ReviewHandler: &handlers.ReviewHandler{
+			ReviewModel: &repositories.ReviewModel{
+				DB: db.DB,
+			},
+		},
+		ViewingHandler: &handlers.ViewingHandler{
+			ViewingModel: &repositories.ViewingModel{
+				DB: db.DB,
+			},
+		},
+		SeriesHandler: &handlers.SeriesHandler{
+			SeriesModel: &repositories.SeriesModel{
+				DB: db.DB,
+			},
+		},
+		TagHandler: &handlers.TagHandler{
+			TagModel: &repositories.TagModel{
+				DB: db.DB,
+			},
+		},
+		ListHandler: &handlers.ListHandler{
+			ListModel: &repositories.ListModel{
+				DB: db.DB,
+			},
+		},
+		AuditHandler: &handlers.AuditHandler{
+			AuditModel: &repositories.AuditModel{
 				DB: db.DB,
 			},
 		},
+		IdempotencyStore: idempotencyModel,
+		JobPool:          jobPool,
 	}
 
 	router.SetupRouter(app)
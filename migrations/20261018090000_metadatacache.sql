@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Responses of the metadata providers (TMDb), keyed by provider + title + year
+CREATE TABLE metadata_cache (
+    cache_key TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    response TEXT NOT NULL,
+    fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE metadata_cache;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Background jobs processed by the worker pool (pkg/jobs)
+-- failed jobs are retried with exponential backoff until max_attempts, then they are "dead"
+CREATE TABLE jobs (
+    job_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}',
+    state TEXT CHECK(state IN ('queued', 'running', 'succeeded', 'dead', 'cancelled')) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX jobs_state_run_at_idx ON jobs (state, run_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX jobs_state_run_at_idx;
+DROP TABLE jobs;
+-- +goose StatementEnd
@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+-- UNIQUE(title) is relaxed to UNIQUE(title, release_year) so remakes can coexist
+-- SQLite can not drop a constraint, so the table is rebuilt
+CREATE TABLE Watchlist_new (
+    watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    release_year INTEGER,
+    genre TEXT,
+    director TEXT,
+    status TEXT CHECK(status IN ('watched', 'not watched', 'watching')) DEFAULT 'not watched',
+    added_date DATE DEFAULT (date('now')),
+    UNIQUE(title, release_year)
+);
+
+INSERT INTO Watchlist_new (watchlist_id, title, release_year, genre, director, status, added_date)
+SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist;
+
+DROP TABLE Watchlist;
+ALTER TABLE Watchlist_new RENAME TO Watchlist;
+
+-- IMDb, TMDb and Wikidata IDs of an entry, an ID can only belong to one entry
+CREATE TABLE watchlist_external_ids (
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    provider TEXT NOT NULL CHECK(provider IN ('imdb', 'tmdb', 'wikidata')),
+    external_id TEXT NOT NULL,
+    PRIMARY KEY (watchlist_id, provider),
+    UNIQUE (provider, external_id)
+);
+
+-- Sample Data
+INSERT INTO watchlist_external_ids (watchlist_id, provider, external_id)
+SELECT watchlist_id, 'imdb', 'tt1375666' FROM Watchlist WHERE title = 'Inception';
+
+INSERT INTO watchlist_external_ids (watchlist_id, provider, external_id)
+SELECT watchlist_id, 'imdb', 'tt0133093' FROM Watchlist WHERE title = 'The Matrix';
+
+INSERT INTO watchlist_external_ids (watchlist_id, provider, external_id)
+SELECT watchlist_id, 'imdb', 'tt0068646' FROM Watchlist WHERE title = 'The Godfather';
+
+INSERT INTO watchlist_external_ids (watchlist_id, provider, external_id)
+SELECT watchlist_id, 'imdb', 'tt6751668' FROM Watchlist WHERE title = 'Parasite';
+
+INSERT INTO watchlist_external_ids (watchlist_id, provider, external_id)
+SELECT watchlist_id, 'imdb', 'tt4154796' FROM Watchlist WHERE title = 'Avengers: Endgame';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE watchlist_external_ids;
+
+CREATE TABLE Watchlist_old (
+    watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL UNIQUE,
+    release_year INTEGER,
+    genre TEXT,
+    director TEXT,
+    status TEXT CHECK(status IN ('watched', 'not watched', 'watching')) DEFAULT 'not watched',
+    added_date DATE DEFAULT (date('now'))
+);
+
+INSERT INTO Watchlist_old (watchlist_id, title, release_year, genre, director, status, added_date)
+SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist;
+
+DROP TABLE Watchlist;
+ALTER TABLE Watchlist_old RENAME TO Watchlist;
+-- +goose StatementEnd
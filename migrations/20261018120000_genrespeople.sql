@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+-- genres and people are shared by every entry, names are unique case-insensitively
+CREATE TABLE genres (
+    genre_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE
+);
+
+CREATE TABLE people (
+    person_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE
+);
+
+CREATE TABLE watchlist_genres (
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    genre_id INTEGER NOT NULL REFERENCES genres(genre_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (watchlist_id, genre_id)
+);
+
+CREATE TABLE watchlist_credits (
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    person_id INTEGER NOT NULL REFERENCES people(person_id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK(role IN ('director', 'writer', 'actor')),
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (watchlist_id, person_id, role)
+);
+
+CREATE INDEX watchlist_genres_genre_idx ON watchlist_genres (genre_id);
+CREATE INDEX watchlist_credits_person_idx ON watchlist_credits (person_id, role);
+
+-- split the comma-separated genre and director columns of the existing entries
+-- "Lana Wachowski, Lilly Wachowski" becomes two people credited as director
+CREATE TEMP TABLE split_names AS
+WITH RECURSIVE split(watchlist_id, kind, name, rest, position) AS (
+    SELECT watchlist_id, 'genre', NULL, IFNULL(genre, '') || ',', -1 FROM Watchlist
+    UNION ALL
+    SELECT watchlist_id, 'director', NULL, IFNULL(director, '') || ',', -1 FROM Watchlist
+    UNION ALL
+    SELECT watchlist_id, kind, TRIM(SUBSTR(rest, 1, INSTR(rest, ',') - 1)), SUBSTR(rest, INSTR(rest, ',') + 1), position + 1
+    FROM split WHERE rest <> ''
+)
+SELECT watchlist_id, kind, name, position FROM split WHERE name IS NOT NULL AND name <> '';
+
+INSERT OR IGNORE INTO genres (name)
+SELECT name FROM split_names WHERE kind = 'genre' ORDER BY watchlist_id, position;
+
+INSERT OR IGNORE INTO people (name)
+SELECT name FROM split_names WHERE kind = 'director' ORDER BY watchlist_id, position;
+
+INSERT OR IGNORE INTO watchlist_genres (watchlist_id, genre_id, position)
+SELECT split_names.watchlist_id, genres.genre_id, split_names.position
+FROM split_names JOIN genres ON genres.name = split_names.name
+WHERE split_names.kind = 'genre';
+
+INSERT OR IGNORE INTO watchlist_credits (watchlist_id, person_id, role, position)
+SELECT split_names.watchlist_id, people.person_id, 'director', split_names.position
+FROM split_names JOIN people ON people.name = split_names.name
+WHERE split_names.kind = 'director';
+
+DROP TABLE split_names;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- the legacy genre and director columns are kept in sync, so nothing is lost
+DROP TABLE watchlist_credits;
+DROP TABLE watchlist_genres;
+DROP TABLE people;
+DROP TABLE genres;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one review per entry, ratings are half stars between 0.5 and 5
+CREATE TABLE reviews (
+    review_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    watchlist_id INTEGER NOT NULL UNIQUE REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    rating REAL NOT NULL CHECK(rating BETWEEN 0.5 AND 5 AND rating * 2 = CAST(rating * 2 AS INTEGER)),
+    review_text TEXT NOT NULL DEFAULT '',
+    spoiler BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- previous versions of a review, one row per edit
+CREATE TABLE review_revisions (
+    revision_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    review_id INTEGER NOT NULL REFERENCES reviews(review_id) ON DELETE CASCADE,
+    watchlist_id INTEGER NOT NULL,
+    rating REAL NOT NULL,
+    review_text TEXT NOT NULL DEFAULT '',
+    spoiler BOOLEAN NOT NULL DEFAULT 0,
+    edited_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX review_revisions_review_idx ON review_revisions (review_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE review_revisions;
+DROP TABLE reviews;
+-- +goose StatementEnd
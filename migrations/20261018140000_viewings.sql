@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every viewing of an entry, the first one is the watch and the next ones are rewatches
+CREATE TABLE viewings (
+    viewing_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    watched_on TIMESTAMP NOT NULL,
+    rating REAL CHECK(rating IS NULL OR (rating BETWEEN 0.5 AND 5 AND rating * 2 = CAST(rating * 2 AS INTEGER))),
+    location TEXT NOT NULL DEFAULT '',
+    platform TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX viewings_watchlist_idx ON viewings (watchlist_id, watched_on);
+CREATE INDEX viewings_watched_on_idx ON viewings (watched_on);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE viewings;
+-- +goose StatementEnd
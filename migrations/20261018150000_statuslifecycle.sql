@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- status gains "on hold" and "dropped" and the lifecycle timeline is recorded
+-- SQLite can not change a CHECK constraint, so the table is rebuilt
+CREATE TABLE Watchlist_new (
+    watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    release_year INTEGER,
+    genre TEXT,
+    director TEXT,
+    status TEXT CHECK(status IN ('not watched', 'watching', 'watched', 'on hold', 'dropped')) DEFAULT 'not watched',
+    added_date DATE DEFAULT (date('now')),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    status_changed_at TIMESTAMP,
+    UNIQUE(title, release_year)
+);
+
+-- backfill, the added date is the best guess for when the current status was set
+-- watched entries are finished on their last viewing when there is one
+INSERT INTO Watchlist_new (watchlist_id, title, release_year, genre, director, status, added_date, started_at, finished_at, status_changed_at)
+SELECT watchlist_id, title, release_year, genre, director, status, added_date,
+    CASE WHEN status IN ('watching', 'watched') THEN added_date END,
+    CASE WHEN status = 'watched' THEN COALESCE((SELECT MAX(watched_on) FROM viewings WHERE viewings.watchlist_id = Watchlist.watchlist_id), added_date) END,
+    added_date
+FROM Watchlist;
+
+DROP TABLE Watchlist;
+ALTER TABLE Watchlist_new RENAME TO Watchlist;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- on hold and dropped entries go back to watching
+CREATE TABLE Watchlist_old (
+    watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    release_year INTEGER,
+    genre TEXT,
+    director TEXT,
+    status TEXT CHECK(status IN ('watched', 'not watched', 'watching')) DEFAULT 'not watched',
+    added_date DATE DEFAULT (date('now')),
+    UNIQUE(title, release_year)
+);
+
+INSERT INTO Watchlist_old (watchlist_id, title, release_year, genre, director, status, added_date)
+SELECT watchlist_id, title, release_year, genre, director,
+    CASE WHEN status IN ('on hold', 'dropped') THEN 'watching' ELSE status END,
+    added_date
+FROM Watchlist;
+
+DROP TABLE Watchlist;
+ALTER TABLE Watchlist_old RENAME TO Watchlist;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every existing entry is a movie
+ALTER TABLE Watchlist ADD COLUMN kind TEXT NOT NULL DEFAULT 'movie' CHECK(kind IN ('movie', 'series', 'miniseries', 'documentary', 'short'));
+
+-- season 0 holds the specials
+CREATE TABLE seasons (
+    season_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    season_number INTEGER NOT NULL CHECK(season_number >= 0),
+    title TEXT NOT NULL DEFAULT '',
+    UNIQUE(watchlist_id, season_number)
+);
+
+-- an episode is watched once watched_at is set
+CREATE TABLE episodes (
+    episode_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    season_id INTEGER NOT NULL REFERENCES seasons(season_id) ON DELETE CASCADE,
+    episode_number INTEGER NOT NULL CHECK(episode_number >= 1),
+    title TEXT NOT NULL DEFAULT '',
+    air_date TIMESTAMP,
+    watched_at TIMESTAMP,
+    UNIQUE(season_id, episode_number)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE episodes;
+DROP TABLE seasons;
+ALTER TABLE Watchlist DROP COLUMN kind;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- runtime is in minutes, position_seconds is where playback stopped
+ALTER TABLE Watchlist ADD COLUMN runtime INTEGER CHECK(runtime IS NULL OR runtime > 0);
+ALTER TABLE Watchlist ADD COLUMN position_seconds INTEGER NOT NULL DEFAULT 0 CHECK(position_seconds >= 0);
+ALTER TABLE Watchlist ADD COLUMN progress_updated_at TIMESTAMP;
+
+-- continue watching lists the watching entries by their last progress
+CREATE INDEX watchlist_progress_idx ON Watchlist (status, progress_updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX watchlist_progress_idx;
+ALTER TABLE Watchlist DROP COLUMN progress_updated_at;
+ALTER TABLE Watchlist DROP COLUMN position_seconds;
+ALTER TABLE Watchlist DROP COLUMN runtime;
+-- +goose StatementEnd
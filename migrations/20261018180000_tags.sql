@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- personal labels like "date night", names are unique case-insensitively
+CREATE TABLE tags (
+    tag_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE
+);
+
+CREATE TABLE watchlist_tags (
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(tag_id) ON DELETE CASCADE,
+    PRIMARY KEY (watchlist_id, tag_id)
+);
+
+CREATE INDEX watchlist_tags_tag_idx ON watchlist_tags (tag_id);
+
+-- markdown notes of an entry
+ALTER TABLE Watchlist ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Watchlist DROP COLUMN notes;
+DROP TABLE watchlist_tags;
+DROP TABLE tags;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- named lists like "Horror October", an entry can be in any number of lists
+-- the share token opens unlisted and public lists, public lists are also listed
+CREATE TABLE lists (
+    list_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    description TEXT NOT NULL DEFAULT '',
+    visibility TEXT CHECK(visibility IN ('private', 'unlisted', 'public')) NOT NULL DEFAULT 'private',
+    share_token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- positions run from 1 to the number of entries of the list
+CREATE TABLE list_entries (
+    list_id INTEGER NOT NULL REFERENCES lists(list_id) ON DELETE CASCADE,
+    watchlist_id INTEGER NOT NULL REFERENCES Watchlist(watchlist_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK(position >= 1),
+    added_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (list_id, watchlist_id)
+);
+
+CREATE INDEX list_entries_position_idx ON list_entries (list_id, position);
+CREATE INDEX list_entries_watchlist_idx ON list_entries (watchlist_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE list_entries;
+DROP TABLE lists;
+-- +goose StatementEnd
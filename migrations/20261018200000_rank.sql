@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- fractional rank key of the manual order, see pkg/rank
+-- existing entries are ranked in ID order the first time an entry is added or moved
+ALTER TABLE Watchlist ADD COLUMN rank_key TEXT;
+
+-- unique keys, two entries can never share a place
+CREATE UNIQUE INDEX watchlist_rank_key_idx ON Watchlist (rank_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX watchlist_rank_key_idx;
+ALTER TABLE Watchlist DROP COLUMN rank_key;
+-- +goose StatementEnd
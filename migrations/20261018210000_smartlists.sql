@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the saved query of a smart list as JSON, static lists have none
+-- it is compiled to parameterized SQL by the repository, never run as is
+ALTER TABLE lists ADD COLUMN filter TEXT;
+
+-- pinned lists come first
+ALTER TABLE lists ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE lists DROP COLUMN pinned;
+ALTER TABLE lists DROP COLUMN filter;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- version counts the writes of an entry, it is the ETag of the entry and a write with If-Match only applies to the version read
+ALTER TABLE Watchlist ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Watchlist DROP COLUMN version;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the responses of the requests sent with an Idempotency-Key, a retry with the key gets the stored response
+-- fingerprint is a hash of the method, path and body, the key can not be reused for another request
+-- scope is the method, route and user a key was sent with, the same key sent elsewhere is another key
+CREATE TABLE idempotency_keys (
+    scope TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    state TEXT CHECK(state IN ('in progress', 'completed')) NOT NULL DEFAULT 'in progress',
+    status INTEGER NOT NULL DEFAULT 0,
+    headers TEXT NOT NULL DEFAULT '{}',
+    body BLOB,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idempotency_keys_expires_at_idx;
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a deleted entry goes to the trash with deleted_at set, it is purged once the retention period is over
+-- only the entries which are not in the trash need a unique title and year, so a trashed title can be added again
+-- SQLite can not drop a UNIQUE constraint, so the table is rebuilt with a partial unique index instead
+CREATE TABLE Watchlist_new (
+    watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    release_year INTEGER,
+    genre TEXT,
+    director TEXT,
+    status TEXT CHECK(status IN ('not watched', 'watching', 'watched', 'on hold', 'dropped')) DEFAULT 'not watched',
+    added_date DATE DEFAULT (date('now')),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    status_changed_at TIMESTAMP,
+    kind TEXT NOT NULL DEFAULT 'movie' CHECK(kind IN ('movie', 'series', 'miniseries', 'documentary', 'short')),
+    runtime INTEGER CHECK(runtime IS NULL OR runtime > 0),
+    position_seconds INTEGER NOT NULL DEFAULT 0 CHECK(position_seconds >= 0),
+    progress_updated_at TIMESTAMP,
+    notes TEXT NOT NULL DEFAULT '',
+    rank_key TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+INSERT INTO Watchlist_new (watchlist_id, title, release_year, genre, director, status, added_date, started_at, finished_at, status_changed_at,
+    kind, runtime, position_seconds, progress_updated_at, notes, rank_key, version)
+SELECT watchlist_id, title, release_year, genre, director, status, added_date, started_at, finished_at, status_changed_at,
+    kind, runtime, position_seconds, progress_updated_at, notes, rank_key, version
+FROM Watchlist;
+
+DROP TABLE Watchlist;
+ALTER TABLE Watchlist_new RENAME TO Watchlist;
+
+CREATE UNIQUE INDEX watchlist_title_year_idx ON Watchlist (title, release_year) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX watchlist_rank_key_idx ON Watchlist (rank_key);
+CREATE INDEX watchlist_progress_idx ON Watchlist (status, progress_updated_at);
+CREATE INDEX watchlist_deleted_at_idx ON Watchlist (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- the trash is emptied, its titles could break the unique constraint
+DELETE FROM Watchlist WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE Watchlist_old (
+    watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    release_year INTEGER,
+    genre TEXT,
+    director TEXT,
+    status TEXT CHECK(status IN ('not watched', 'watching', 'watched', 'on hold', 'dropped')) DEFAULT 'not watched',
+    added_date DATE DEFAULT (date('now')),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    status_changed_at TIMESTAMP,
+    kind TEXT NOT NULL DEFAULT 'movie' CHECK(kind IN ('movie', 'series', 'miniseries', 'documentary', 'short')),
+    runtime INTEGER CHECK(runtime IS NULL OR runtime > 0),
+    position_seconds INTEGER NOT NULL DEFAULT 0 CHECK(position_seconds >= 0),
+    progress_updated_at TIMESTAMP,
+    notes TEXT NOT NULL DEFAULT '',
+    rank_key TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    UNIQUE(title, release_year)
+);
+
+INSERT INTO Watchlist_old (watchlist_id, title, release_year, genre, director, status, added_date, started_at, finished_at, status_changed_at,
+    kind, runtime, position_seconds, progress_updated_at, notes, rank_key, version)
+SELECT watchlist_id, title, release_year, genre, director, status, added_date, started_at, finished_at, status_changed_at,
+    kind, runtime, position_seconds, progress_updated_at, notes, rank_key, version
+FROM Watchlist;
+
+DROP TABLE Watchlist;
+ALTER TABLE Watchlist_old RENAME TO Watchlist;
+
+CREATE UNIQUE INDEX watchlist_rank_key_idx ON Watchlist (rank_key);
+CREATE INDEX watchlist_progress_idx ON Watchlist (status, progress_updated_at);
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every write of an entry, recorded in the transaction of the write
+-- before and after only hold the fields which changed, before is NULL on a create and after on a purge
+CREATE TABLE audit_events (
+    event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TIMESTAMP NOT NULL,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before TEXT,
+    after TEXT,
+    request_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_events_entity_idx ON audit_events (entity, entity_id, event_id);
+CREATE INDEX audit_events_occurred_at_idx ON audit_events (occurred_at);
+
+-- the table is append-only
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER audit_events_no_delete;
+DROP TRIGGER audit_events_no_update;
+DROP INDEX audit_events_occurred_at_idx;
+DROP INDEX audit_events_entity_idx;
+DROP TABLE audit_events;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the content of an entry at each of its versions, a revert writes an old one back as a new version
+-- undo_token groups the versions written by one request, action is empty for a state from before the versions existed
+CREATE TABLE watchlist_versions (
+    watchlist_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    snapshot TEXT NOT NULL,
+    action TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    undo_token TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (watchlist_id, version)
+);
+
+CREATE INDEX watchlist_versions_undo_token_idx ON watchlist_versions (undo_token) WHERE undo_token <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX watchlist_versions_undo_token_idx;
+DROP TABLE watchlist_versions;
+-- +goose StatementEnd
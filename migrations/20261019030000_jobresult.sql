@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- what a job reports while it runs, like the progress of an import
+ALTER TABLE jobs ADD COLUMN result TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs DROP COLUMN result;
+-- +goose StatementEnd
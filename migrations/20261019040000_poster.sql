@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- path of the poster on the image server of the metadata provider, like /gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg
+ALTER TABLE Watchlist ADD COLUMN poster_path TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Watchlist DROP COLUMN poster_path;
+-- +goose StatementEnd
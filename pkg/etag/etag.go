@@ -0,0 +1,67 @@
+// Package etag builds entity tags and evaluates the conditional headers which carry them
+// https://www.rfc-editor.org/rfc/rfc9110#section-13
+//
+// an entry has a strong tag, its version, and a list a weak one since only what it shows matters
+// If-Match compares strongly so a weak tag never matches, If-None-Match compares weakly
+package etag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Any is the value of If-Match and If-None-Match which matches any current tag
+const Any = "*"
+
+// Strong is the tag of a version, like "3"
+func Strong(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Weak is a weak tag of an opaque value, like W/"12-5f0c2a9b"
+func Weak(value string) string {
+	return `W/"` + value + `"`
+}
+
+// MatchStrong tells whether an If-Match header matches the current tag, weak tags never match
+func MatchStrong(header string, current string) bool {
+	for _, tag := range tags(header) {
+		if tag == Any {
+			return true
+		}
+		if !isWeak(tag) && !isWeak(current) && tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchWeak tells whether an If-None-Match header matches the current tag, W/ is ignored on both sides
+func MatchWeak(header string, current string) bool {
+	for _, tag := range tags(header) {
+		if tag == Any || opaque(tag) == opaque(current) {
+			return true
+		}
+	}
+	return false
+}
+
+// tags splits a header into its tags, the quoted values never hold a comma here
+func tags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func isWeak(tag string) bool {
+	return strings.HasPrefix(tag, "W/")
+}
+
+func opaque(tag string) string {
+	return strings.TrimPrefix(tag, "W/")
+}
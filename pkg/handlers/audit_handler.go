@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type AuditHandler struct {
+	AuditModel repositories.AuditModelInterface
+}
+
+// auditActor is who makes the writes of the request, the basic auth user or anonymous, with the correlation ID of the request
+func auditActor(ctx *gin.Context) models.Actor {
+	name := ctx.GetString(gin.AuthUserKey)
+	if name == "" {
+		name = "anonymous"
+	}
+	return models.Actor{Name: name, RequestID: ctx.GetString(problem.CorrelationIDKey)}
+}
+
+// writeActor is auditActor with the undo token of the request, for the writes of the entries
+// their versions are kept with the token, which a successful response sends back in X-Undo-Token
+func writeActor(ctx *gin.Context) models.Actor {
+	actor := auditActor(ctx)
+	actor.UndoToken = undoToken(ctx)
+	return actor
+}
+
+// GetAuditEventsHandler godoc
+// @Summary      Search the audit
+// @Description  Lists the writes of the entries, newest first. before and after only hold the fields which changed.
+// @Description  Page with before_id set to the event_id of the last event read
+// @Tags         audit
+// @Produce      json
+// @Security     BasicAuth
+// @Param        actor       query     string  false  "Basic auth user, anonymous or system"
+// @Param        action      query     string  false  "create, update, delete, transition, progress, move, restore, purge, rebalance, revert, undo, review, viewing, season, episodes or tag"
+// @Param        entity      query     string  false  "watchlist"
+// @Param        entity_id   query     int     false  "ID of the entity"
+// @Param        request_id  query     string  false  "Correlation ID of the request"
+// @Param        since       query     string  false  "RFC 3339 time, included"
+// @Param        until       query     string  false  "RFC 3339 time, excluded"
+// @Param        before_id   query     int     false  "Only events older than this one"
+// @Param        limit       query     int     false  "1 to 500, 100 by default"
+// @Success      200         {array}   models.AuditEvent
+// @Failure      400         {object}  problem.Problem  "Invalid Query"
+// @Failure      401         {object}  problem.Problem  "Unauthorized"
+// @Failure      500         {object}  problem.Problem  "Failed to get Audit Events"
+// @Router       /v1/admin/audit [get]
+func (auditHandler *AuditHandler) GetAuditEventsHandler(ctx *gin.Context) {
+	var query models.AuditQuery
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Query", err))
+		return
+	}
+
+	events, err := auditHandler.AuditModel.GetAuditEvents(query)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Audit Events", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, events)
+}
+
+// GetWatchListHistoryHandler godoc
+// @Summary      Retrieve the history of a watchlist entry
+// @Description  Lists the writes of the entry in the order they happened, also while it is in the trash and after it was purged
+// @Tags         audit
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {array}   models.AuditEvent
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      500           {object}  problem.Problem  "Failed to get WatchList History"
+// @Router       /v1/watchlist/{watchlist_id}/history [get]
+func (auditHandler *AuditHandler) GetWatchListHistoryHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	events, err := auditHandler.AuditModel.GetWatchListHistory(watchlist_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList History", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, events)
+}
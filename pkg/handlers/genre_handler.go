@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type GenreHandler struct {
+	GenreModel repositories.GenreModelInterface
+}
+
+// GetGenresHandler godoc
+// @Summary      Retrieve all genres
+// @Description  Lists every genre with the number of watchlist entries tagged with it
+// @Tags         genres
+// @Produce      json
+// @Success      200  {array}   models.Genre
+// @Failure      500  {object}  problem.Problem  "Failed to get Genres"
+// @Router       /v1/genres [get]
+func (genreHandler *GenreHandler) GetGenresHandler(ctx *gin.Context) {
+	genres, err := genreHandler.GenreModel.GetGenres()
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Genres", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, genres)
+}
+
+// GetWatchListByGenreHandler godoc
+// @Summary      Retrieve the watchlist of a genre
+// @Description  Lists the watchlist entries tagged with the genre whose ID is provided in the path
+// @Tags         genres
+// @Produce      json
+// @Param        genre_id  path      string  true  "Genre ID"
+// @Success      200       {array}   models.Watchlist
+// @Failure      404       {object}  problem.Problem  "Genre not found"
+// @Failure      500       {object}  problem.Problem  "Failed to get WatchList by genre"
+// @Router       /v1/genres/{genre_id}/watchlist [get]
+func (genreHandler *GenreHandler) GetWatchListByGenreHandler(ctx *gin.Context) {
+	genre_id_param := ctx.Param("genre_id")
+
+	watchLists, err := genreHandler.GenreModel.GetWatchListByGenre(genre_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Genre not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList by genre", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchLists)
+}
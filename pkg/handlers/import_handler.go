@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/importer"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+// ImportHandler queues the imports as "watchlist.import" jobs, the worker pool runs them
+type ImportHandler struct {
+	JobModel repositories.JobModelInterface
+}
+
+// ImportTraktHandler godoc
+// @Summary      Import a Trakt export
+// @Description  Starts a background import of a Trakt JSON export (history, watchlist, ratings).
+// @Description  Send the files either as a JSON body or as multipart form files named history, watchlist and ratings.
+// @Description  Titles already present in the watchlist are counted as duplicates and skipped.
+// @Description  The import is a watchlist.import job, it also shows up in /admin/jobs
+// @Tags         import
+// @Accept       json
+// @Accept       mpfd
+// @Produce      json
+// @Param        export  body      models.TraktImportRequest  false  "Trakt export files"
+// @Success      202     {object}  models.ImportJob
+// @Failure      400     {object}  problem.Problem  "Invalid Trakt export"
+// @Failure      500     {object}  problem.Problem  "Failed to start the import"
+// @Router       /v1/import/trakt [post]
+func (importHandler *ImportHandler) ImportTraktHandler(ctx *gin.Context) {
+	var body models.TraktImportRequest
+
+	var err error
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		body, err = readTraktMultipart(ctx)
+	} else {
+		err = ctx.ShouldBindJSON(&body)
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Trakt export", err))
+		return
+	}
+
+	items, err := importer.ParseTraktExport(body.History, body.Watchlist, body.Ratings)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Trakt export", err))
+		return
+	}
+
+	payload, err := json.Marshal(models.ImportPayload{Source: "trakt", Items: items})
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to start the import", err))
+		return
+	}
+
+	job, err := importHandler.JobModel.EnqueueJob(jobs.WatchListImportKind, payload, 0)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to start the import", err))
+		return
+	}
+
+	importJob, err := importer.ImportJob(job)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to start the import", err))
+		return
+	}
+	ctx.JSON(http.StatusAccepted, importJob)
+}
+
+// GetImportJobHandler godoc
+// @Summary      Get the status of an import job
+// @Description  Returns the progress of a background import started by /import/trakt
+// @Tags         import
+// @Produce      json
+// @Param        job_id  path      int     true  "Import Job ID"
+// @Success      200     {object}  models.ImportJob
+// @Failure      404     {object}  problem.Problem  "Import job not found"
+// @Failure      500     {object}  problem.Problem  "Failed to get Import job"
+// @Router       /v1/import/jobs/{job_id} [get]
+func (importHandler *ImportHandler) GetImportJobHandler(ctx *gin.Context) {
+	job_id_param := ctx.Param("job_id")
+
+	job, err := importHandler.JobModel.GetJobById(job_id_param)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && job.Kind != jobs.WatchListImportKind) {
+		ctx.Error(problem.New(problem.NotFound, "Import job not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Import job", err))
+		return
+	}
+
+	importJob, err := importer.ImportJob(job)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Import job", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, importJob)
+}
+
+// readTraktMultipart reads the optional history, watchlist and ratings form files
+func readTraktMultipart(ctx *gin.Context) (models.TraktImportRequest, error) {
+	body := models.TraktImportRequest{}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return body, err
+	}
+
+	files := map[string]*[]byte{
+		"history":   (*[]byte)(&body.History),
+		"watchlist": (*[]byte)(&body.Watchlist),
+		"ratings":   (*[]byte)(&body.Ratings),
+	}
+	for name, target := range files {
+		headers := form.File[name]
+		if len(headers) == 0 {
+			continue
+		}
+
+		data, err := readFormFile(headers[0])
+		if err != nil {
+			return body, err
+		}
+		*target = data
+	}
+
+	return body, nil
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type JobHandler struct {
+	JobModel       repositories.JobModelInterface
+	WatchListModel repositories.WatchListModelInterface
+	Pool           *jobs.Pool
+}
+
+// GetJobsHandler godoc
+// @Summary      List background jobs
+// @Description  Lists a page of the background jobs, newest first, optionally filtered by state
+// @Description  Succeeded and cancelled jobs are purged JOB_RETENTION after they finished
+// @Tags         jobs
+// @Produce      json
+// @Security     BasicAuth
+// @Param        state   query     string  false  "queued, running, succeeded, dead or cancelled"
+// @Param        limit   query     int     false  "1 to 500, 100 by default"
+// @Param        offset  query     int     false  "Number of jobs to skip"
+// @Success      200     {array}   models.Job
+// @Failure      400     {object}  problem.Problem  "Invalid Query"
+// @Failure      401     {object}  problem.Problem  "Unauthorized"
+// @Failure      500     {object}  problem.Problem  "Failed to get Jobs"
+// @Router       /v1/admin/jobs [get]
+func (jobHandler *JobHandler) GetJobsHandler(ctx *gin.Context) {
+	var query models.JobQuery
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Query", err))
+		return
+	}
+
+	jobList, err := jobHandler.JobModel.GetJobs(query)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Jobs", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, jobList)
+}
+
+// GetJobByIdHandler godoc
+// @Summary      Inspect a background job
+// @Description  Returns a job with its attempts and last error
+// @Tags         jobs
+// @Produce      json
+// @Security     BasicAuth
+// @Param        job_id  path      string  true  "Job ID"
+// @Success      200     {object}  models.Job
+// @Failure      401     {object}  problem.Problem  "Unauthorized"
+// @Failure      404     {object}  problem.Problem  "Job not found"
+// @Failure      500     {object}  problem.Problem  "Failed to get Job by ID"
+// @Router       /v1/admin/jobs/{job_id} [get]
+func (jobHandler *JobHandler) GetJobByIdHandler(ctx *gin.Context) {
+	job_id_param := ctx.Param("job_id")
+	job, err := jobHandler.JobModel.GetJobById(job_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Job not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Job by ID", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// EnqueueJobHandler godoc
+// @Summary      Enqueue a background job
+// @Description  Adds a job to the queue, the kind must have a registered handler
+// @Tags         jobs
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request  body      models.JobEnqueueRequest  true  "Job"
+// @Success      202      {object}  models.Job
+// @Failure      401      {object}  problem.Problem  "Unauthorized"
+// @Failure      400      {object}  problem.Problem  "Invalid Job"
+// @Failure      500      {object}  problem.Problem  "Failed to enqueue Job"
+// @Router       /v1/admin/jobs [post]
+func (jobHandler *JobHandler) EnqueueJobHandler(ctx *gin.Context) {
+	var body models.JobEnqueueRequest
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Job", err))
+		return
+	}
+
+	if !jobHandler.Pool.HasHandler(body.Kind) {
+		ctx.Error(problem.New(problem.Validation, "Invalid Job: no handler registered for job kind "+body.Kind))
+		return
+	}
+
+	job, err := jobHandler.JobModel.EnqueueJob(body.Kind, body.Payload, body.MaxAttempts)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to enqueue Job", err))
+		return
+	}
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// CancelJobHandler godoc
+// @Summary      Cancel a background job
+// @Description  Cancels a queued or running job
+// @Tags         jobs
+// @Produce      json
+// @Security     BasicAuth
+// @Param        job_id  path      string  true  "Job ID"
+// @Success      200     {object}  models.Job
+// @Failure      401     {object}  problem.Problem  "Unauthorized"
+// @Failure      404     {object}  problem.Problem  "Job not found"
+// @Failure      409     {object}  problem.Problem  "Job already finished"
+// @Failure      500     {object}  problem.Problem  "Failed to cancel Job"
+// @Router       /v1/admin/jobs/{job_id}/cancel [post]
+func (jobHandler *JobHandler) CancelJobHandler(ctx *gin.Context) {
+	job_id_param := ctx.Param("job_id")
+
+	job, err := jobHandler.JobModel.GetJobById(job_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Job not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to cancel Job", err))
+		return
+	}
+
+	rowAffected, err := jobHandler.JobModel.CancelJob(job_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to cancel Job", err))
+		return
+	}
+	if rowAffected == 0 {
+		ctx.Error(problem.New(problem.Conflict, "Job already finished as "+job.State))
+		return
+	}
+
+	// interrupt the handler if a worker is running it
+	jobHandler.Pool.Cancel(job.JobID)
+
+	job, err = jobHandler.JobModel.GetJobById(job_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to cancel Job", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// EnqueueWatchListRefreshHandler godoc
+// @Summary      Refresh watchlist entries in the background
+// @Description  Enqueues one "watchlist.refresh" job per entry, the job re-fetches the entry metadata from the provider
+// @Description  An empty watchlist_ids list refreshes every entry
+// @Tags         jobs
+// @Accept       json
+// @Produce      json
+// @Security     BasicAuth
+// @Param        request  body      models.WatchListRefreshRequest  true  "Entries to refresh"
+// @Success      202      {array}   models.Job
+// @Failure      401      {object}  problem.Problem  "Unauthorized"
+// @Failure      400      {object}  problem.Problem  "Invalid refresh request"
+// @Failure      500      {object}  problem.Problem  "Failed to enqueue refresh"
+// @Router       /v1/admin/watchlist/refresh [post]
+func (jobHandler *JobHandler) EnqueueWatchListRefreshHandler(ctx *gin.Context) {
+	var body models.WatchListRefreshRequest
+
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid refresh request", err))
+		return
+	}
+
+	if !jobHandler.Pool.HasHandler(jobs.WatchListRefreshKind) {
+		ctx.Error(problem.New(problem.BadRequest, "Invalid refresh request: metadata enrichment is not configured, set TMDB_API_KEY"))
+		return
+	}
+
+	watchlistIDs := body.WatchlistIDs
+	if len(watchlistIDs) == 0 {
+		watchLists, err := jobHandler.WatchListModel.GetAllWatchList(models.WatchListQuery{})
+		if err != nil {
+			ctx.Error(problem.Wrap(problem.Internal, "Failed to enqueue refresh", err))
+			return
+		}
+		for _, watchList := range watchLists {
+			watchlistIDs = append(watchlistIDs, watchList.WatchlistID)
+		}
+	}
+
+	enqueued := []models.Job{}
+	for _, watchlistID := range watchlistIDs {
+		payload, _ := json.Marshal(models.WatchListRefreshPayload{WatchlistID: watchlistID})
+
+		job, err := jobHandler.JobModel.EnqueueJob(jobs.WatchListRefreshKind, payload, 0)
+		if err != nil {
+			ctx.Error(problem.Wrap(problem.Internal, "Failed to enqueue refresh", err))
+			return
+		}
+		enqueued = append(enqueued, job)
+	}
+
+	ctx.JSON(http.StatusAccepted, enqueued)
+}
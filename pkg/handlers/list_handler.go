@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type ListHandler struct {
+	ListModel repositories.ListModelInterface
+}
+
+// GetListsHandler godoc
+// @Summary      Retrieve all lists
+// @Description  Lists every list with its number of entries, whatever its visibility
+// @Tags         lists
+// @Produce      json
+// @Success      200  {array}   models.List
+// @Failure      500  {object}  problem.Problem  "Failed to get Lists"
+// @Router       /v1/lists [get]
+func (listHandler *ListHandler) GetListsHandler(ctx *gin.Context) {
+	lists, err := listHandler.ListModel.GetLists()
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Lists", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, lists)
+}
+
+// GetListByIdHandler godoc
+// @Summary      Retrieve a list
+// @Description  Returns the list with its entries in order
+// @Tags         lists
+// @Produce      json
+// @Param        list_id  path      string  true  "List ID"
+// @Success      200      {object}  models.List
+// @Failure      404      {object}  problem.Problem  "List not found"
+// @Failure      500      {object}  problem.Problem  "Failed to get List"
+// @Router       /v1/lists/{list_id} [get]
+func (listHandler *ListHandler) GetListByIdHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+
+	list, err := listHandler.ListModel.GetListById(list_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get List", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// AddListHandler godoc
+// @Summary      Create a list
+// @Description  Creates a list, names are unique case-insensitively and the visibility defaults to private
+// @Description  a list created with a filter is a smart list, its entries are the watchlist entries matching the filter
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ListRequest  true  "List"
+// @Success      201      {object}  models.List
+// @Failure      400      {object}  problem.Problem  "Invalid List Data"
+// @Failure      409      {object}  problem.Problem  "List already exists"
+// @Failure      500      {object}  problem.Problem  "Failed to add List"
+// @Router       /v1/lists [post]
+func (listHandler *ListHandler) AddListHandler(ctx *gin.Context) {
+	var body models.ListRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err == nil && strings.TrimSpace(body.Name) == "" {
+		err = errors.New("name must not be blank")
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid List Data", err))
+		return
+	}
+
+	list, err := listHandler.ListModel.AddList(body)
+	if errors.Is(err, repositories.ErrInvalidFilter) {
+		ctx.Error(problem.Invalid("Invalid List Data", err))
+		return
+	}
+	if errors.Is(err, repositories.ErrListExists) {
+		ctx.Error(problem.New(problem.Conflict, "List already exists"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add List", err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, list)
+}
+
+// UpdateListHandler godoc
+// @Summary      Update a list
+// @Description  Changes the name, description, visibility, filter or pin of a list, fields which are not sent are kept
+// @Description  only smart lists take a filter
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        list_id  path      string                    true  "List ID"
+// @Param        request  body      models.ListUpdateRequest  true  "Fields to change"
+// @Success      200      {object}  models.List
+// @Failure      400      {object}  problem.Problem  "Invalid List Data"
+// @Failure      404      {object}  problem.Problem  "List not found"
+// @Failure      409      {object}  problem.Problem  "List already exists or is not a smart list"
+// @Failure      500      {object}  problem.Problem  "Failed to update List"
+// @Router       /v1/lists/{list_id} [patch]
+func (listHandler *ListHandler) UpdateListHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+
+	var body models.ListUpdateRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err == nil && body.Name != nil && strings.TrimSpace(*body.Name) == "" {
+		err = errors.New("name must not be blank")
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid List Data", err))
+		return
+	}
+
+	list, err := listHandler.ListModel.UpdateList(list_id_param, body)
+	if errors.Is(err, repositories.ErrInvalidFilter) {
+		ctx.Error(problem.Invalid("Invalid List Data", err))
+		return
+	}
+	if errors.Is(err, repositories.ErrListKind) {
+		ctx.Error(problem.New(problem.Conflict, "Only smart lists have a filter"))
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrListExists) {
+		ctx.Error(problem.New(problem.Conflict, "List already exists"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to update List", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// DeleteListHandler godoc
+// @Summary      Delete a list
+// @Description  Deletes the list, its entries stay in the watchlist
+// @Tags         lists
+// @Produce      json
+// @Param        list_id  path      string  true  "List ID"
+// @Success      200      {object}  gin.H  "List deleted successfully"
+// @Failure      500      {object}  problem.Problem  "Failed to delete List"
+// @Router       /v1/lists/{list_id} [delete]
+func (listHandler *ListHandler) DeleteListHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+
+	rowAffected, err := listHandler.ListModel.DeleteList(list_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to delete List", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "List deleted successfully",
+		"row-affected": rowAffected,
+	})
+}
+
+// AddListEntryHandler godoc
+// @Summary      Add an entry to a list
+// @Description  Adds a watchlist entry at the position, or at the end, the entries from the position on move down by one
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        list_id  path      string                   true  "List ID"
+// @Param        request  body      models.ListEntryRequest  true  "Entry"
+// @Success      201      {object}  models.List
+// @Failure      400      {object}  problem.Problem  "Invalid List Entry Data"
+// @Failure      404      {object}  problem.Problem  "List or WatchList not found"
+// @Failure      409      {object}  problem.Problem  "Entry is already in the List or the List is a smart list"
+// @Failure      500      {object}  problem.Problem  "Failed to add List Entry"
+// @Router       /v1/lists/{list_id}/entries [post]
+func (listHandler *ListHandler) AddListEntryHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+
+	var body models.ListEntryRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid List Entry Data", err))
+		return
+	}
+
+	list, err := listHandler.ListModel.AddListEntry(list_id_param, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List or WatchList not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrListKind) {
+		ctx.Error(problem.New(problem.Conflict, "Entries of a smart list come from its filter"))
+		return
+	}
+	if errors.Is(err, repositories.ErrListEntryExists) {
+		ctx.Error(problem.New(problem.Conflict, "Entry is already in the List"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add List Entry", err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, list)
+}
+
+// RemoveListEntryHandler godoc
+// @Summary      Remove an entry from a list
+// @Description  Removes the entry from the list only, the entries after it move up by one
+// @Tags         lists
+// @Produce      json
+// @Param        list_id       path      string  true  "List ID"
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {object}  gin.H  "List Entry removed successfully"
+// @Failure      500           {object}  problem.Problem  "Failed to remove List Entry"
+// @Router       /v1/lists/{list_id}/entries/{watchlist_id} [delete]
+func (listHandler *ListHandler) RemoveListEntryHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	rowAffected, err := listHandler.ListModel.RemoveListEntry(list_id_param, watchlist_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to remove List Entry", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "List Entry removed successfully",
+		"row-affected": rowAffected,
+	})
+}
+
+// ReorderListHandler godoc
+// @Summary      Reorder a list
+// @Description  Gives the entries of the list the order sent, every entry of the list must be sent once
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Param        list_id  path      string                   true  "List ID"
+// @Param        request  body      models.ListOrderRequest  true  "Entries in their new order"
+// @Success      200      {object}  models.List
+// @Failure      400      {object}  problem.Problem  "Invalid List Order"
+// @Failure      404      {object}  problem.Problem  "List not found"
+// @Failure      409      {object}  problem.Problem  "List is a smart list"
+// @Failure      500      {object}  problem.Problem  "Failed to reorder List"
+// @Router       /v1/lists/{list_id}/order [put]
+func (listHandler *ListHandler) ReorderListHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+
+	var body models.ListOrderRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid List Order", err))
+		return
+	}
+
+	list, err := listHandler.ListModel.ReorderList(list_id_param, body.WatchlistIDs)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrListKind) {
+		ctx.Error(problem.New(problem.Conflict, "Entries of a smart list come from its filter"))
+		return
+	}
+	if errors.Is(err, repositories.ErrListOrder) {
+		ctx.Error(problem.Invalid("Invalid List Order", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to reorder List", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// GetPublicListsHandler godoc
+// @Summary      Retrieve the public lists
+// @Description  Lists the public lists, unlisted lists are only opened with their share token
+// @Tags         lists
+// @Produce      json
+// @Success      200  {array}   models.List
+// @Failure      500  {object}  problem.Problem  "Failed to get Lists"
+// @Router       /v1/shared/lists [get]
+func (listHandler *ListHandler) GetPublicListsHandler(ctx *gin.Context) {
+	lists, err := listHandler.ListModel.GetPublicLists()
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Lists", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, lists)
+}
+
+// GetSharedListHandler godoc
+// @Summary      Retrieve a shared list
+// @Description  Returns an unlisted or public list by its share token, private lists are not found
+// @Tags         lists
+// @Produce      json
+// @Param        share_token  path      string  true  "Share token of the list"
+// @Success      200          {object}  models.List
+// @Failure      404          {object}  problem.Problem  "List not found"
+// @Failure      500          {object}  problem.Problem  "Failed to get List"
+// @Router       /v1/shared/lists/{share_token} [get]
+func (listHandler *ListHandler) GetSharedListHandler(ctx *gin.Context) {
+	share_token_param := ctx.Param("share_token")
+
+	list, err := listHandler.ListModel.GetSharedList(share_token_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get List", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// GetListEntriesHandler godoc
+// @Summary      Retrieve the entries of a list
+// @Description  Returns a page of the entries of a list, a smart list is evaluated against the watchlist on every call
+// @Tags         lists
+// @Produce      json
+// @Param        list_id    path      string  true   "List ID"
+// @Param        page       query     int     false  "Page, starting at 1"
+// @Param        page_size  query     int     false  "Entries per page, 20 by default and at most 100"
+// @Success      200        {object}  models.ListEntriesPage
+// @Failure      400        {object}  problem.Problem  "Invalid Query"
+// @Failure      404        {object}  problem.Problem  "List not found"
+// @Failure      500        {object}  problem.Problem  "Failed to get List Entries"
+// @Router       /v1/lists/{list_id}/entries [get]
+func (listHandler *ListHandler) GetListEntriesHandler(ctx *gin.Context) {
+	list_id_param := ctx.Param("list_id")
+
+	var query models.PageQuery
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Query", err))
+		return
+	}
+
+	page, err := listHandler.ListModel.GetListEntries(list_id_param, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get List Entries", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetSharedListEntriesHandler godoc
+// @Summary      Retrieve the entries of a shared list
+// @Description  Returns a page of the entries of an unlisted or public list by its share token, private lists are not found
+// @Tags         lists
+// @Produce      json
+// @Param        share_token  path      string  true   "Share token of the list"
+// @Param        page         query     int     false  "Page, starting at 1"
+// @Param        page_size    query     int     false  "Entries per page, 20 by default and at most 100"
+// @Success      200          {object}  models.ListEntriesPage
+// @Failure      400          {object}  problem.Problem  "Invalid Query"
+// @Failure      404          {object}  problem.Problem  "List not found"
+// @Failure      500          {object}  problem.Problem  "Failed to get List Entries"
+// @Router       /v1/shared/lists/{share_token}/entries [get]
+func (listHandler *ListHandler) GetSharedListEntriesHandler(ctx *gin.Context) {
+	share_token_param := ctx.Param("share_token")
+
+	var query models.PageQuery
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Query", err))
+		return
+	}
+
+	page, err := listHandler.ListModel.GetSharedListEntries(share_token_param, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "List not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get List Entries", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
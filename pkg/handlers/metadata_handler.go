@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/metadata"
+	"github.com/saketV8/cine-dots/pkg/problem"
+)
+
+type MetadataHandler struct {
+	MetadataProvider metadata.MetadataProvider
+}
+
+// LookupMetadataHandler godoc
+// @Summary      Look up metadata for a title
+// @Description  Fetches canonical title, release year, genres, directors, runtime, poster path and external IDs from the metadata provider
+// @Tags         metadata
+// @Produce      json
+// @Param        title  query     string  true   "Title to look up"
+// @Param        year   query     int     false  "Release year"
+// @Success      200    {object}  models.Metadata
+// @Failure      400    {object}  problem.Problem  "Invalid lookup"
+// @Failure      404    {object}  problem.Problem  "No metadata found for title"
+// @Failure      502    {object}  problem.Problem  "Failed to fetch metadata"
+// @Router       /v1/metadata/lookup [get]
+func (metadataHandler *MetadataHandler) LookupMetadataHandler(ctx *gin.Context) {
+	if metadataHandler.MetadataProvider == nil {
+		ctx.Error(problem.New(problem.BadRequest, "Metadata enrichment is not configured: set TMDB_API_KEY to enable metadata lookups"))
+		return
+	}
+
+	title_param := ctx.Query("title")
+	if title_param == "" {
+		ctx.Error(problem.New(problem.Validation, "Invalid lookup: title is required"))
+		return
+	}
+
+	year := 0
+	if year_param := ctx.Query("year"); year_param != "" {
+		parsed, err := strconv.Atoi(year_param)
+		if err != nil {
+			ctx.Error(problem.Invalid("Invalid lookup", err))
+			return
+		}
+		year = parsed
+	}
+
+	found, err := metadataHandler.MetadataProvider.Lookup(ctx.Request.Context(), title_param, year)
+	if errors.Is(err, metadata.ErrNotFound) {
+		ctx.Error(problem.Wrap(problem.NotFound, "No metadata found for title", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Upstream, "Failed to fetch metadata", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, found)
+}
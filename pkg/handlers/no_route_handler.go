@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/problem"
+)
+
+// NoRouteHandler answers the requests no route matches with a not found problem
+func NoRouteHandler(ctx *gin.Context) {
+	ctx.Error(problem.New(problem.NotFound, "no route matches "+ctx.Request.Method+" "+ctx.Request.URL.Path))
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type PersonHandler struct {
+	PersonModel repositories.PersonModelInterface
+}
+
+// GetWatchListByPersonHandler godoc
+// @Summary      Retrieve the watchlist of a person
+// @Description  Lists the watchlist entries the person is credited on, optionally for a single role
+// @Tags         people
+// @Produce      json
+// @Param        person_id  path      string  true   "Person ID"
+// @Param        role       query     string  false  "director, writer or actor"
+// @Success      200        {array}   models.Watchlist
+// @Failure      400        {object}  problem.Problem  "Invalid role"
+// @Failure      404        {object}  problem.Problem  "Person not found"
+// @Failure      500        {object}  problem.Problem  "Failed to get WatchList by person"
+// @Router       /v1/people/{person_id}/watchlist [get]
+func (personHandler *PersonHandler) GetWatchListByPersonHandler(ctx *gin.Context) {
+	person_id_param := ctx.Param("person_id")
+	role_param := ctx.Query("role")
+
+	switch role_param {
+	case "", "director", "writer", "actor":
+	default:
+		ctx.Error(problem.New(problem.Validation, "Invalid role: role must be one of director, writer, actor"))
+		return
+	}
+
+	_, err := personHandler.PersonModel.GetPersonById(person_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Person not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList by person", err))
+		return
+	}
+
+	watchLists, err := personHandler.PersonModel.GetWatchListByPerson(person_id_param, role_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList by person", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchLists)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type ReviewHandler struct {
+	ReviewModel repositories.ReviewModelInterface
+}
+
+// bindReviewRequest reads a review body, ratings must be whole or half stars
+// it responds with 400 and returns false when the body is invalid
+func bindReviewRequest(ctx *gin.Context) (models.ReviewRequest, bool) {
+	var body models.ReviewRequest
+
+	err := ctx.ShouldBindJSON(&body)
+	if err == nil && body.Rating*2 != math.Trunc(body.Rating*2) {
+		err = errors.New("rating must be a multiple of 0.5")
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Review Data", err))
+		return body, false
+	}
+	return body, true
+}
+
+// GetReviewHandler godoc
+// @Summary      Retrieve the review of a watchlist
+// @Description  Fetches the rating and review of the watchlist with its edit history
+// @Tags         reviews
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {object}  models.Review
+// @Failure      404           {object}  problem.Problem  "Review not found"
+// @Failure      500           {object}  problem.Problem  "Failed to get Review"
+// @Router       /v1/watchlist/{watchlist_id}/review [get]
+func (reviewHandler *ReviewHandler) GetReviewHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	review, err := reviewHandler.ReviewModel.GetReview(watchlist_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Review not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Review", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+
+// AddReviewHandler godoc
+// @Summary      Review a watchlist
+// @Description  Rates a watchlist in half stars (0.5 to 5) with an optional review text
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      string                true  "Watchlist ID"
+// @Param        request       body      models.ReviewRequest  true  "Review"
+// @Success      201           {object}  models.Review
+// @Failure      400           {object}  problem.Problem  "Invalid Review Data"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      409           {object}  problem.Problem  "WatchList is already reviewed"
+// @Failure      500           {object}  problem.Problem  "Failed to add Review"
+// @Router       /v1/watchlist/{watchlist_id}/review [post]
+func (reviewHandler *ReviewHandler) AddReviewHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	body, ok := bindReviewRequest(ctx)
+	if !ok {
+		return
+	}
+
+	review, err := reviewHandler.ReviewModel.As(writeActor(ctx)).AddReview(watchlist_id_param, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrReviewExists) {
+		ctx.Error(problem.New(problem.Conflict, "WatchList is already reviewed, use PUT to edit the review"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add Review", err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, review)
+}
+
+// UpdateReviewHandler godoc
+// @Summary      Edit the review of a watchlist
+// @Description  Replaces the rating and review text, the previous version is kept in the history
+// @Tags         reviews
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      string                true  "Watchlist ID"
+// @Param        request       body      models.ReviewRequest  true  "Review"
+// @Success      200           {object}  models.Review
+// @Failure      400           {object}  problem.Problem  "Invalid Review Data"
+// @Failure      404           {object}  problem.Problem  "Review not found"
+// @Failure      500           {object}  problem.Problem  "Failed to update Review"
+// @Router       /v1/watchlist/{watchlist_id}/review [put]
+func (reviewHandler *ReviewHandler) UpdateReviewHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	body, ok := bindReviewRequest(ctx)
+	if !ok {
+		return
+	}
+
+	review, err := reviewHandler.ReviewModel.As(writeActor(ctx)).UpdateReview(watchlist_id_param, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Review not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to update Review", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+
+// DeleteReviewHandler godoc
+// @Summary      Delete the review of a watchlist
+// @Description  Removes the rating and review of the watchlist with its history
+// @Tags         reviews
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {object}  gin.H  "Review deleted successfully"
+// @Failure      404           {object}  problem.Problem  "Review not found"
+// @Failure      500           {object}  problem.Problem  "Failed to delete Review"
+// @Router       /v1/watchlist/{watchlist_id}/review [delete]
+func (reviewHandler *ReviewHandler) DeleteReviewHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	rowAffected, err := reviewHandler.ReviewModel.As(writeActor(ctx)).DeleteReview(watchlist_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to delete Review", err))
+		return
+	}
+	if rowAffected == 0 {
+		ctx.Error(problem.New(problem.NotFound, "Review not found"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Review deleted successfully",
+		"row-affected": rowAffected,
+	})
+}
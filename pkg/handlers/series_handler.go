@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type SeriesHandler struct {
+	SeriesModel repositories.SeriesModelInterface
+}
+
+// GetSeasonsHandler godoc
+// @Summary      Retrieve the seasons of a series
+// @Description  Lists the seasons of the watchlist with their episodes and when they were watched
+// @Tags         series
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {array}   models.Season
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      500           {object}  problem.Problem  "Failed to get Seasons"
+// @Router       /v1/watchlist/{watchlist_id}/seasons [get]
+func (seriesHandler *SeriesHandler) GetSeasonsHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	seasons, err := seriesHandler.SeriesModel.GetSeasons(watchlist_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Seasons", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, seasons)
+}
+
+// SaveSeasonHandler godoc
+// @Summary      Create or extend a season
+// @Description  Creates the season with its episodes, or adds the missing episodes when it exists. Watched episodes stay watched
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id   path      string                true  "Watchlist ID"
+// @Param        season_number  path      int                   true  "Season number, 0 for specials"
+// @Param        request        body      models.SeasonRequest  true  "Season"
+// @Success      200            {object}  models.Season
+// @Failure      400            {object}  problem.Problem  "Invalid Season Data"
+// @Failure      404            {object}  problem.Problem  "WatchList not found"
+// @Failure      409            {object}  problem.Problem  "WatchList is not a series"
+// @Failure      500            {object}  problem.Problem  "Failed to save Season"
+// @Router       /v1/watchlist/{watchlist_id}/seasons/{season_number} [put]
+func (seriesHandler *SeriesHandler) SaveSeasonHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	season_number, ok := bindSeasonNumber(ctx)
+	if !ok {
+		return
+	}
+
+	var body models.SeasonRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Season Data", err))
+		return
+	}
+
+	season, err := seriesHandler.SeriesModel.As(writeActor(ctx)).SaveSeason(watchlist_id_param, season_number, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrNotEpisodic) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList is not a series", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to save Season", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, season)
+}
+
+// DeleteSeasonHandler godoc
+// @Summary      Delete a season
+// @Description  Removes the season and its episodes from the watchlist
+// @Tags         series
+// @Produce      json
+// @Param        watchlist_id   path      string  true  "Watchlist ID"
+// @Param        season_number  path      int     true  "Season number"
+// @Success      200            {object}  gin.H  "Season deleted successfully"
+// @Failure      400            {object}  problem.Problem  "Invalid season number"
+// @Failure      500            {object}  problem.Problem  "Failed to delete Season"
+// @Router       /v1/watchlist/{watchlist_id}/seasons/{season_number} [delete]
+func (seriesHandler *SeriesHandler) DeleteSeasonHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	season_number, ok := bindSeasonNumber(ctx)
+	if !ok {
+		return
+	}
+
+	rowAffected, err := seriesHandler.SeriesModel.As(writeActor(ctx)).DeleteSeason(watchlist_id_param, season_number)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to delete Season", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Season deleted successfully",
+		"row-affected": rowAffected,
+	})
+}
+
+// MarkEpisodesWatchedHandler godoc
+// @Summary      Mark episodes as watched
+// @Description  Marks the whole season, or the episodes from and to (inclusive), as watched in one call.
+// @Description  The series becomes watching, and watched once every episode is watched
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id   path      string                      true   "Watchlist ID"
+// @Param        season_number  path      int                         true   "Season number"
+// @Param        request        body      models.EpisodeRangeRequest  false  "Episodes, the whole season when empty"
+// @Success      200            {object}  models.Watchlist
+// @Failure      400            {object}  problem.Problem  "Invalid episode range"
+// @Failure      404            {object}  problem.Problem  "Season not found"
+// @Failure      500            {object}  problem.Problem  "Failed to mark Episodes"
+// @Router       /v1/watchlist/{watchlist_id}/seasons/{season_number}/watched [post]
+func (seriesHandler *SeriesHandler) MarkEpisodesWatchedHandler(ctx *gin.Context) {
+	seriesHandler.markEpisodes(ctx, true)
+}
+
+// UnmarkEpisodesWatchedHandler godoc
+// @Summary      Mark episodes as not watched
+// @Description  Clears the watched date of the whole season, or of the episodes from and to (inclusive)
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id   path      string                      true   "Watchlist ID"
+// @Param        season_number  path      int                         true   "Season number"
+// @Param        request        body      models.EpisodeRangeRequest  false  "Episodes, the whole season when empty"
+// @Success      200            {object}  models.Watchlist
+// @Failure      400            {object}  problem.Problem  "Invalid episode range"
+// @Failure      404            {object}  problem.Problem  "Season not found"
+// @Failure      500            {object}  problem.Problem  "Failed to mark Episodes"
+// @Router       /v1/watchlist/{watchlist_id}/seasons/{season_number}/watched [delete]
+func (seriesHandler *SeriesHandler) UnmarkEpisodesWatchedHandler(ctx *gin.Context) {
+	seriesHandler.markEpisodes(ctx, false)
+}
+
+func (seriesHandler *SeriesHandler) markEpisodes(ctx *gin.Context, watched bool) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	season_number, ok := bindSeasonNumber(ctx)
+	if !ok {
+		return
+	}
+
+	// the body is optional, no body is the whole season
+	var body models.EpisodeRangeRequest
+	if ctx.Request.ContentLength != 0 {
+		err := ctx.ShouldBindJSON(&body)
+		if err == nil && body.To != 0 && body.To < body.From {
+			err = errors.New("to must not be before from")
+		}
+		if err != nil {
+			ctx.Error(problem.Invalid("Invalid episode range", err))
+			return
+		}
+	}
+
+	watchList, err := seriesHandler.SeriesModel.As(writeActor(ctx)).MarkEpisodes(watchlist_id_param, season_number, body, watched)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Season not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to mark Episodes", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// bindSeasonNumber reads the season number of the path
+// it responds with 400 and returns false when it is not a number
+func bindSeasonNumber(ctx *gin.Context) (int, bool) {
+	season_number, err := strconv.Atoi(ctx.Param("season_number"))
+	if err != nil || season_number < 0 {
+		ctx.Error(problem.New(problem.Validation, "Invalid season number: season_number must be 0 or more"))
+		return 0, false
+	}
+	return season_number, true
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type TagHandler struct {
+	TagModel repositories.TagModelInterface
+}
+
+// GetTagsHandler godoc
+// @Summary      Retrieve all tags
+// @Description  Lists every tag with the number of watchlist entries tagged with it
+// @Tags         tags
+// @Produce      json
+// @Success      200  {array}   models.Tag
+// @Failure      500  {object}  problem.Problem  "Failed to get Tags"
+// @Router       /v1/tags [get]
+func (tagHandler *TagHandler) GetTagsHandler(ctx *gin.Context) {
+	tags, err := tagHandler.TagModel.GetTags()
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Tags", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, tags)
+}
+
+// AddTagHandler godoc
+// @Summary      Create a tag
+// @Description  Creates a tag, names are unique case-insensitively
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.TagRequest  true  "Tag"
+// @Success      201      {object}  models.Tag
+// @Failure      400      {object}  problem.Problem  "Invalid Tag Data"
+// @Failure      409      {object}  problem.Problem  "Tag already exists"
+// @Failure      500      {object}  problem.Problem  "Failed to add Tag"
+// @Router       /v1/tags [post]
+func (tagHandler *TagHandler) AddTagHandler(ctx *gin.Context) {
+	body, ok := bindTagRequest(ctx)
+	if !ok {
+		return
+	}
+
+	tag, err := tagHandler.TagModel.AddTag(body.Name)
+	if errors.Is(err, repositories.ErrTagExists) {
+		ctx.Error(problem.New(problem.Conflict, "Tag already exists"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add Tag", err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, tag)
+}
+
+// RenameTagHandler godoc
+// @Summary      Rename a tag
+// @Description  Renames the tag on every entry, merge the tags instead when the name is taken
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        tag_id   path      string             true  "Tag ID"
+// @Param        request  body      models.TagRequest  true  "New name"
+// @Success      200      {object}  models.Tag
+// @Failure      400      {object}  problem.Problem  "Invalid Tag Data"
+// @Failure      404      {object}  problem.Problem  "Tag not found"
+// @Failure      409      {object}  problem.Problem  "Tag already exists"
+// @Failure      500      {object}  problem.Problem  "Failed to rename Tag"
+// @Router       /v1/tags/{tag_id} [put]
+func (tagHandler *TagHandler) RenameTagHandler(ctx *gin.Context) {
+	tag_id_param := ctx.Param("tag_id")
+
+	body, ok := bindTagRequest(ctx)
+	if !ok {
+		return
+	}
+
+	tag, err := tagHandler.TagModel.As(writeActor(ctx)).RenameTag(tag_id_param, body.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Tag not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrTagExists) {
+		ctx.Error(problem.New(problem.Conflict, "Tag already exists, merge the tags with POST /tags/{tag_id}/merge"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to rename Tag", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, tag)
+}
+
+// DeleteTagHandler godoc
+// @Summary      Delete a tag
+// @Description  Removes the tag from every entry and deletes it
+// @Tags         tags
+// @Produce      json
+// @Param        tag_id  path      string  true  "Tag ID"
+// @Success      200     {object}  gin.H  "Tag deleted successfully"
+// @Failure      500     {object}  problem.Problem  "Failed to delete Tag"
+// @Router       /v1/tags/{tag_id} [delete]
+func (tagHandler *TagHandler) DeleteTagHandler(ctx *gin.Context) {
+	tag_id_param := ctx.Param("tag_id")
+
+	rowAffected, err := tagHandler.TagModel.As(writeActor(ctx)).DeleteTag(tag_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to delete Tag", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Tag deleted successfully",
+		"row-affected": rowAffected,
+	})
+}
+
+// MergeTagsHandler godoc
+// @Summary      Merge tags
+// @Description  Moves the entries of the listed tags to the tag of the path and deletes the listed tags
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        tag_id   path      string                  true  "Tag ID to keep"
+// @Param        request  body      models.TagMergeRequest  true  "Tags to merge"
+// @Success      200      {object}  models.Tag
+// @Failure      400      {object}  problem.Problem  "Invalid Tag Data"
+// @Failure      404      {object}  problem.Problem  "Tag not found"
+// @Failure      500      {object}  problem.Problem  "Failed to merge Tags"
+// @Router       /v1/tags/{tag_id}/merge [post]
+func (tagHandler *TagHandler) MergeTagsHandler(ctx *gin.Context) {
+	tag_id_param := ctx.Param("tag_id")
+
+	var body models.TagMergeRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Tag Data", err))
+		return
+	}
+
+	tag, err := tagHandler.TagModel.As(writeActor(ctx)).MergeTags(tag_id_param, body.TagIDs)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "Tag not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to merge Tags", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, tag)
+}
+
+// TagWatchListsHandler godoc
+// @Summary      Tag many watchlist entries
+// @Description  Adds the tags to every listed entry, missing tags are created and unknown entries are skipped
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BulkTagRequest  true  "Entries and tags"
+// @Success      200      {object}  gin.H  "Tags added successfully"
+// @Failure      400      {object}  problem.Problem  "Invalid Tag Data"
+// @Failure      500      {object}  problem.Problem  "Failed to tag WatchList"
+// @Router       /v1/watchlist/tags [post]
+func (tagHandler *TagHandler) TagWatchListsHandler(ctx *gin.Context) {
+	var body models.BulkTagRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Tag Data", err))
+		return
+	}
+
+	rowAffected, err := tagHandler.TagModel.As(writeActor(ctx)).TagWatchLists(body.WatchlistIDs, body.Tags)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to tag WatchList", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Tags added successfully",
+		"row-affected": rowAffected,
+		"body":         body,
+	})
+}
+
+// UntagWatchListsHandler godoc
+// @Summary      Untag many watchlist entries
+// @Description  Removes the tags from every listed entry, the tags themselves are kept
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BulkTagRequest  true  "Entries and tags"
+// @Success      200      {object}  gin.H  "Tags removed successfully"
+// @Failure      400      {object}  problem.Problem  "Invalid Tag Data"
+// @Failure      500      {object}  problem.Problem  "Failed to untag WatchList"
+// @Router       /v1/watchlist/tags [delete]
+func (tagHandler *TagHandler) UntagWatchListsHandler(ctx *gin.Context) {
+	var body models.BulkTagRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Tag Data", err))
+		return
+	}
+
+	rowAffected, err := tagHandler.TagModel.As(writeActor(ctx)).UntagWatchLists(body.WatchlistIDs, body.Tags)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to untag WatchList", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Tags removed successfully",
+		"row-affected": rowAffected,
+		"body":         body,
+	})
+}
+
+// bindTagRequest reads the body of a tag create or rename
+// it responds with 400 and returns false when the name is missing or blank
+func bindTagRequest(ctx *gin.Context) (models.TagRequest, bool) {
+	var body models.TagRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err == nil && strings.TrimSpace(body.Name) == "" {
+		err = errors.New("name must not be blank")
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Tag Data", err))
+		return body, false
+	}
+	return body, true
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+type ViewingHandler struct {
+	ViewingModel repositories.ViewingModelInterface
+}
+
+// GetViewingsHandler godoc
+// @Summary      Retrieve the viewings of a watchlist
+// @Description  Lists every logged viewing of the watchlist, oldest first
+// @Tags         diary
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {array}   models.Viewing
+// @Failure      500           {object}  problem.Problem  "Failed to get Viewings"
+// @Router       /v1/watchlist/{watchlist_id}/viewings [get]
+func (viewingHandler *ViewingHandler) GetViewingsHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	viewings, err := viewingHandler.ViewingModel.GetViewings(watchlist_id_param)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Viewings", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, viewings)
+}
+
+// AddViewingHandler godoc
+// @Summary      Log a viewing
+// @Description  Records a dated viewing of the watchlist and marks it as watched, later viewings count as rewatches
+// @Tags         diary
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      string                 true  "Watchlist ID"
+// @Param        request       body      models.ViewingRequest  true  "Viewing"
+// @Success      201           {object}  models.Viewing
+// @Failure      400           {object}  problem.Problem  "Invalid Viewing Data"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      500           {object}  problem.Problem  "Failed to add Viewing"
+// @Router       /v1/watchlist/{watchlist_id}/viewings [post]
+func (viewingHandler *ViewingHandler) AddViewingHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	var body models.ViewingRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err == nil && body.Rating != nil && *body.Rating*2 != math.Trunc(*body.Rating*2) {
+		err = errors.New("rating must be a multiple of 0.5")
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Viewing Data", err))
+		return
+	}
+
+	viewing, err := viewingHandler.ViewingModel.As(writeActor(ctx)).AddViewing(watchlist_id_param, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add Viewing", err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, viewing)
+}
+
+// GetDiaryHandler godoc
+// @Summary      Retrieve the watch diary
+// @Description  Lists the viewings of every watchlist between two dates, newest first
+// @Tags         diary
+// @Produce      json
+// @Param        from  query     string  false  "First day, YYYY-MM-DD"
+// @Param        to    query     string  false  "Last day (inclusive), YYYY-MM-DD"
+// @Success      200   {array}   models.Viewing
+// @Failure      400   {object}  problem.Problem  "Invalid date"
+// @Failure      500   {object}  problem.Problem  "Failed to get Diary"
+// @Router       /v1/diary [get]
+func (viewingHandler *ViewingHandler) GetDiaryHandler(ctx *gin.Context) {
+	var from, to time.Time
+
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := ctx.Query(param.name)
+		if raw == "" {
+			continue
+		}
+
+		day, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			ctx.Error(problem.New(problem.Validation, "Invalid date: "+param.name+" must be formatted as YYYY-MM-DD"))
+			return
+		}
+		*param.value = day
+	}
+
+	// the last day is inclusive
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	viewings, err := viewingHandler.ViewingModel.GetDiary(from, to)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Diary", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, viewings)
+}
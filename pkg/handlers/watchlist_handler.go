@@ -1,16 +1,35 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/etag"
+	"github.com/saketV8/cine-dots/pkg/metadata"
 	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	querylang "github.com/saketV8/cine-dots/pkg/query"
 	"github.com/saketV8/cine-dots/pkg/repositories"
 )
 
 type WatchListHandler struct {
 	// WatchListModel *repositories.WatchListModel
 	WatchListModel repositories.WatchListModelInterface // Interface type
+
+	// MetadataProvider is optional, it is used by AddWatchListHandler when enrich=true
+	MetadataProvider metadata.MetadataProvider
+}
+
+// writer is the model for the writes of the request, see writeActor
+func (watchListHandler *WatchListHandler) writer(ctx *gin.Context) repositories.WatchListModelInterface {
+	return watchListHandler.WatchListModel.As(writeActor(ctx))
 }
 
 // GET Methods
@@ -21,19 +40,31 @@ type WatchListHandler struct {
 // @Description  Retrieves all watchlists from the database.
 // @Tags         watchlists
 // @Produce      json
+// @Param        sort        query     string  false  "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending"
+// @Param        min_rating  query     number  false  "Minimum average rating (0.5 to 5)"
+// @Param        max_rating  query     number  false  "Maximum average rating (0.5 to 5)"
+// @Param        tags        query     string  false  "Comma-separated tags"
+// @Param        tag_match   query     string  false  "any (default) or all of the tags"
+// @Param        q           query     string  false  "Query like genre:action year:>2000 status:watched"
 // @Success      200  {array}  models.Watchlist
-// @Failure      500  {object} gin.H  "Failed to get All WatchList"
-// @Router       /watchlist/all [get]
+// @Header       200  {string}  ETag  "W/\"7-5f0c2a9b\""
+// @Success      304  "Not modified"
+// @Failure      400  {object}  problem.Problem  "Invalid list query"
+// @Failure      500  {object} problem.Problem  "Failed to get All WatchList"
+// @Router       /v1/watchlist [get]
+// @Router       /v1/watchlist/all [get]
 func (watchListHandler *WatchListHandler) GetAllWatchListHandler(ctx *gin.Context) {
-	watchLists, err := watchListHandler.WatchListModel.GetAllWatchList()
+	query, ok := bindWatchListQuery(ctx)
+	if !ok {
+		return
+	}
+
+	watchLists, err := watchListHandler.WatchListModel.GetAllWatchList(query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get All WatchList",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get All WatchList", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, watchLists)
+	writeWatchLists(ctx, watchLists)
 }
 
 // GetWatchedListHandler godoc
@@ -41,19 +72,30 @@ func (watchListHandler *WatchListHandler) GetAllWatchListHandler(ctx *gin.Contex
 // @Description  Fetches all watchlists with a "watched" status from the database
 // @Tags         watchlists
 // @Produce      json
+// @Param        sort        query     string  false  "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending"
+// @Param        min_rating  query     number  false  "Minimum average rating (0.5 to 5)"
+// @Param        max_rating  query     number  false  "Maximum average rating (0.5 to 5)"
+// @Param        tags        query     string  false  "Comma-separated tags"
+// @Param        tag_match   query     string  false  "any (default) or all of the tags"
+// @Param        q           query     string  false  "Query like genre:action year:>2000 status:watched"
 // @Success      200  {array}  models.Watchlist
-// @Failure      500  {object} gin.H  "Failed to get Watched List"
-// @Router       /watchlist/watched [get]
+// @Header       200  {string}  ETag  "W/\"7-5f0c2a9b\""
+// @Success      304  "Not modified"
+// @Failure      400  {object}  problem.Problem  "Invalid list query"
+// @Failure      500  {object} problem.Problem  "Failed to get Watched List"
+// @Router       /v1/watchlist/watched [get]
 func (watchListHandler *WatchListHandler) GetWatchedListHandler(ctx *gin.Context) {
-	watchLists, err := watchListHandler.WatchListModel.GetWatchedList()
+	query, ok := bindWatchListQuery(ctx)
+	if !ok {
+		return
+	}
+
+	watchLists, err := watchListHandler.WatchListModel.GetWatchedList(query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get Watched List",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Watched List", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, watchLists)
+	writeWatchLists(ctx, watchLists)
 }
 
 // GetWatchingListHandler godoc
@@ -61,19 +103,30 @@ func (watchListHandler *WatchListHandler) GetWatchedListHandler(ctx *gin.Context
 // @Description  Returns all watchlists with a "watching" status from the database
 // @Tags         watchlists
 // @Produce      json
+// @Param        sort        query     string  false  "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending"
+// @Param        min_rating  query     number  false  "Minimum average rating (0.5 to 5)"
+// @Param        max_rating  query     number  false  "Maximum average rating (0.5 to 5)"
+// @Param        tags        query     string  false  "Comma-separated tags"
+// @Param        tag_match   query     string  false  "any (default) or all of the tags"
+// @Param        q           query     string  false  "Query like genre:action year:>2000 status:watched"
 // @Success      200  {array}   models.Watchlist
-// @Failure      500  {object}  gin.H  "Failed to get Watching List"
-// @Router       /watchlist/watching [get]
+// @Header       200  {string}  ETag  "W/\"7-5f0c2a9b\""
+// @Success      304  "Not modified"
+// @Failure      400  {object}  problem.Problem  "Invalid list query"
+// @Failure      500  {object}  problem.Problem  "Failed to get Watching List"
+// @Router       /v1/watchlist/watching [get]
 func (watchListHandler *WatchListHandler) GetWatchingListHandler(ctx *gin.Context) {
-	watchLists, err := watchListHandler.WatchListModel.GetWatchingList()
+	query, ok := bindWatchListQuery(ctx)
+	if !ok {
+		return
+	}
+
+	watchLists, err := watchListHandler.WatchListModel.GetWatchingList(query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get Watching List",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Watching List", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, watchLists)
+	writeWatchLists(ctx, watchLists)
 }
 
 // GetNotWatchedListHandler godoc
@@ -81,19 +134,61 @@ func (watchListHandler *WatchListHandler) GetWatchingListHandler(ctx *gin.Contex
 // @Description  Returns all watchlists with a "not watched" status from the database
 // @Tags         watchlists
 // @Produce      json
+// @Param        sort        query     string  false  "rating, title, release_year, added_date, progress_updated_at or rank, prefix with - for descending"
+// @Param        min_rating  query     number  false  "Minimum average rating (0.5 to 5)"
+// @Param        max_rating  query     number  false  "Maximum average rating (0.5 to 5)"
+// @Param        tags        query     string  false  "Comma-separated tags"
+// @Param        tag_match   query     string  false  "any (default) or all of the tags"
+// @Param        q           query     string  false  "Query like genre:action year:>2000 status:watched"
 // @Success      200  {array}   models.Watchlist
-// @Failure      500  {object}  gin.H  "Failed to get Watching List"
-// @Router       /watchlist/notwatched [get]
+// @Header       200  {string}  ETag  "W/\"7-5f0c2a9b\""
+// @Success      304  "Not modified"
+// @Failure      400  {object}  problem.Problem  "Invalid list query"
+// @Failure      500  {object}  problem.Problem  "Failed to get Watching List"
+// @Router       /v1/watchlist/notwatched [get]
 func (watchListHandler *WatchListHandler) GetNotWatchedListHandler(ctx *gin.Context) {
-	watchLists, err := watchListHandler.WatchListModel.GetNotWatchedList()
+	query, ok := bindWatchListQuery(ctx)
+	if !ok {
+		return
+	}
+
+	watchLists, err := watchListHandler.WatchListModel.GetNotWatchedList(query)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get Watching List",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Watching List", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, watchLists)
+	writeWatchLists(ctx, watchLists)
+}
+
+// GetContinueWatchingHandler godoc
+// @Summary      Continue watching
+// @Description  Lists the entries being watched, the most recently updated progress first
+// @Tags         watchlists
+// @Produce      json
+// @Param        min_rating  query     number  false  "Minimum average rating (0.5 to 5)"
+// @Param        max_rating  query     number  false  "Maximum average rating (0.5 to 5)"
+// @Param        tags        query     string  false  "Comma-separated tags"
+// @Param        tag_match   query     string  false  "any (default) or all of the tags"
+// @Param        q           query     string  false  "Query like genre:action year:>2000 status:watched"
+// @Success      200  {array}  models.Watchlist
+// @Header       200  {string}  ETag  "W/\"7-5f0c2a9b\""
+// @Success      304  "Not modified"
+// @Failure      400  {object}  problem.Problem  "Invalid list query"
+// @Failure      500  {object} problem.Problem  "Failed to get Continue Watching"
+// @Router       /v1/watchlist/continue [get]
+func (watchListHandler *WatchListHandler) GetContinueWatchingHandler(ctx *gin.Context) {
+	query, ok := bindWatchListQuery(ctx)
+	if !ok {
+		return
+	}
+	query.Sort = "-progress_updated_at"
+
+	watchLists, err := watchListHandler.WatchListModel.GetWatchingList(query)
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Continue Watching", err))
+		return
+	}
+	writeWatchLists(ctx, watchLists)
 }
 
 // GetWatchListByIdHandler godoc
@@ -101,21 +196,121 @@ func (watchListHandler *WatchListHandler) GetNotWatchedListHandler(ctx *gin.Cont
 // @Description  Fetches the watchlist whose ID is provided in the path
 // @Tags         watchlists
 // @Produce      json
-// @Param        watchlist_id  path      string  true  "Watchlist ID"
-// @Success      200           {object}  models.Watchlist
-// @Failure      500           {object}  gin.H  "Failed to get WatchList by ID"
-// @Router       /watchlist/{watchlist_id} [get]
+// @Param        watchlist_id   path      string  true   "Watchlist ID"
+// @Param        If-None-Match  header    string  false  "ETag of the cached entry"
+// @Success      200            {object}  models.Watchlist
+// @Header       200            {string}  ETag  "\"3\""
+// @Success      304            "Not modified"
+// @Failure      400            {object}  problem.Problem  "Invalid WatchList ID"
+// @Failure      404            {object}  problem.Problem  "WatchList not found"
+// @Failure      500            {object}  problem.Problem  "Failed to get WatchList by ID"
+// @Router       /v1/watchlist/{watchlist_id} [get]
 func (watchListHandler *WatchListHandler) GetWatchListByIdHandler(ctx *gin.Context) {
 	watchlist_id_param := ctx.Param("watchlist_id")
+	_, err := strconv.Atoi(watchlist_id_param)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid WatchList ID", err))
+		return
+	}
+
 	watchLists, err := watchListHandler.WatchListModel.GetWatchListById(watchlist_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get WatchList by ID",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList by ID", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, watchLists)
+	writeWatchList(ctx, watchLists)
+}
+
+// GetWatchListByExternalIdHandler godoc
+// @Summary      Retrieve a watchlist by external ID
+// @Description  Fetches the watchlist linked to an IMDb, TMDb or Wikidata ID
+// @Tags         watchlists
+// @Produce      json
+// @Param        provider     path      string  true  "imdb, tmdb or wikidata"
+// @Param        external_id  path      string  true  "ID on the provider (tt2380307, 354912, Q27188178)"
+// @Success      200          {object}  models.Watchlist
+// @Failure      400          {object}  problem.Problem  "Invalid provider"
+// @Failure      404          {object}  problem.Problem  "WatchList not found"
+// @Failure      500          {object}  problem.Problem  "Failed to get WatchList by external ID"
+// @Router       /v1/watchlist/by-external/{provider}/{external_id} [get]
+func (watchListHandler *WatchListHandler) GetWatchListByExternalIdHandler(ctx *gin.Context) {
+	provider_param := ctx.Param("provider")
+	external_id_param := ctx.Param("external_id")
+
+	switch provider_param {
+	case "imdb", "tmdb", "wikidata":
+	default:
+		ctx.Error(problem.New(problem.Validation, "Invalid provider: provider must be one of imdb, tmdb, wikidata"))
+		return
+	}
+
+	watchList, err := watchListHandler.WatchListModel.GetWatchListByExternalId(provider_param, external_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList by external ID", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// writeWatchList responds with an entry and its ETag, or with 304 when If-None-Match holds the ETag
+func writeWatchList(ctx *gin.Context, watchList models.Watchlist) {
+	writeWithETag(ctx, etag.Strong(watchList.Version), watchList)
+}
+
+// writeWatchLists responds with a list and its weak ETag, or with 304 when If-None-Match holds the ETag
+// the ETag comes from the highest version and the ID and version of every entry in order,
+// so any write, a new entry, a deleted one or another order changes it
+func writeWatchLists(ctx *gin.Context, watchLists []models.Watchlist) {
+	maxVersion := 0
+	hash := fnv.New64a()
+	for _, watchList := range watchLists {
+		maxVersion = max(maxVersion, watchList.Version)
+		fmt.Fprintf(hash, "%d:%d,", watchList.WatchlistID, watchList.Version)
+	}
+	writeWithETag(ctx, etag.Weak(fmt.Sprintf("%d-%x", maxVersion, hash.Sum64())), watchLists)
+}
+
+func writeWithETag(ctx *gin.Context, tag string, body any) {
+	ctx.Header("ETag", tag)
+	ifNoneMatch := ctx.GetHeader("If-None-Match")
+	if ifNoneMatch != "" && etag.MatchWeak(ifNoneMatch, tag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.JSON(http.StatusOK, body)
+}
+
+// bindWatchListQuery reads the sorting and filtering of the list endpoints
+// it responds with 400 and returns false when the query is invalid, with the column at fault for q
+func bindWatchListQuery(ctx *gin.Context) (models.WatchListQuery, bool) {
+	var query models.WatchListQuery
+
+	err := ctx.ShouldBindQuery(&query)
+	if err == nil && query.MinRating > 0 && query.MaxRating > 0 && query.MinRating > query.MaxRating {
+		err = errors.New("min_rating is greater than max_rating")
+	}
+	if err == nil {
+		_, _, err = repositories.CompileWatchListQuery(query.Q)
+	}
+
+	var queryErr *querylang.Error
+	if errors.As(err, &queryErr) {
+		ctx.Error(problem.Invalid("Invalid list query", err).With("column", queryErr.Column))
+		return query, false
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid list query", err))
+		return query, false
+	}
+	return query, true
 }
 
 // =====================================================================================
@@ -127,35 +322,48 @@ func (watchListHandler *WatchListHandler) GetWatchListByIdHandler(ctx *gin.Conte
 // AddWatchListHandler godoc
 // @Summary      Create a new watchlist item
 // @Description  Adds a new watchlist entry to the database
+// @Description  With enrich=true only the title is required, the other fields are fetched from the metadata provider (models.WatchListEnrichRequest)
 // @Tags         watchlists
 // @Accept       json
 // @Produce      json
-// @Param        watchlist  body      models.WatchListAddRequestExample  true  "Watchlist Data"
-// @Success      200        {object}  models.Watchlist
-// @Failure      400        {object}  gin.H  "Invalid WatchList Data"
-// @Failure      500        {object}  gin.H  "Failed to add WatchList data"
-// @Router       /watchlist/add [post]
+// @Param        watchlist        body      models.WatchListAddRequestExample  true   "Watchlist Data"
+// @Param        enrich           query     bool                               false  "Fill missing fields from the metadata provider"
+// @Param        Idempotency-Key  header    string                             false  "Key to retry the request safely, a retry gets the first response"
+// @Success      200              {object}  models.Watchlist
+// @Failure      400              {object}  problem.Problem  "Invalid WatchList Data"
+// @Failure      404              {object}  problem.Problem  "No metadata found for title"
+// @Failure      409              {object}  problem.Problem  "WatchList already exists"
+// @Failure      422              {object}  problem.Problem  "Idempotency-Key sent with another request"
+// @Failure      500              {object}  problem.Problem  "Failed to add WatchList data"
+// @Failure      502              {object}  problem.Problem  "Failed to fetch metadata"
+// @Router       /v1/watchlist/add [post]
 func (watchListHandler *WatchListHandler) AddWatchListHandler(ctx *gin.Context) {
+	if ctx.Query("enrich") == "true" {
+		watchListHandler.addEnrichedWatchList(ctx)
+		return
+	}
+
 	//getting param from POST request body
 	var body models.Watchlist
 
 	// this will bind data coming from POST request
-	err := ctx.BindJSON(&body)
+	err := ctx.ShouldBindJSON(&body)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid WatchList Data",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err))
 		return
 	}
 
-	watchListAdded, err := watchListHandler.WatchListModel.AddWatchList(body)
+	watchListAdded, err := watchListHandler.writer(ctx).AddWatchList(body)
+	if errors.Is(err, repositories.ErrInvalidStatus) || errors.Is(err, repositories.ErrInvalidWatchList) {
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err))
+		return
+	}
+	if errors.Is(err, repositories.ErrWatchListExists) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList already exists", err))
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to add WatchList data",
-			"details": err.Error(),
-			"body":    body,
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add WatchList data", err))
 		return
 	}
 
@@ -164,41 +372,38 @@ func (watchListHandler *WatchListHandler) AddWatchListHandler(ctx *gin.Context)
 
 // DeleteWatchListHandler godoc
 // @Summary      Delete a watchlist entry
-// @Description  Removes a watchlist from the database based on the provided watchlist ID
+// @Description  Moves the watchlist with the provided ID to the trash, it can be restored until it is purged TRASH_RETENTION later
 // @Tags         watchlists
 // @Accept       json
 // @Produce      json
 // @Param        request  body      models.WatchListDeleteRequest  true  "Delete Request (watchlist_id)"
 // @Success      200      {object}  gin.H  "WatchList deleted successfully"
-// @Failure      400      {object}  gin.H  "Invalid WatchList ID"
-// @Failure      500      {object}  gin.H  "Failed to delete WatchList"
-// @Router       /watchlist/delete [delete]
+// @Failure      400      {object}  problem.Problem  "Invalid WatchList ID"
+// @Failure      404      {object}  problem.Problem  "WatchList not found"
+// @Failure      500      {object}  problem.Problem  "Failed to delete WatchList"
+// @Router       /v1/watchlist/delete [delete]
 func (watchListHandler *WatchListHandler) DeleteWatchListHandler(ctx *gin.Context) {
 	//getting param from POST request body
 	var body models.WatchListDeleteRequest
 
 	// this will bind data coming from POST request
-	err := ctx.BindJSON(&body)
+	err := ctx.ShouldBindJSON(&body)
 	if err != nil {
 		// If binding fails, return a 400 error with the error message
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid WatchList ID",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Invalid("Invalid WatchList ID", err))
 		return
 	}
 
-	rowAffected, err := watchListHandler.WatchListModel.DeleteWatchList(body)
+	rowAffected, err := watchListHandler.writer(ctx).DeleteWatchList(body)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete WatchList",
-			"details": err.Error(),
-			"body":    body,
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to delete WatchList", err))
+		return
+	}
+	if rowAffected == 0 {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
 		return
 	}
 
-	// Add option to check if rowAffected == 0 then return Already Deleted or DATA DNE
 	ctx.JSON(http.StatusOK, gin.H{
 		"message":      "WatchList deleted successfully",
 		"row-affected": rowAffected,
@@ -214,30 +419,42 @@ func (watchListHandler *WatchListHandler) DeleteWatchListHandler(ctx *gin.Contex
 // @Produce      json
 // @Param        request  body      models.WatchListUpdateRequestExample  true  "Updated WatchList Data"
 // @Success      200      {object}  gin.H  "WatchList updated successfully"
-// @Failure      400      {object}  gin.H  "Invalid WatchList Data"
-// @Failure      500      {object}  gin.H  "Failed to update WatchList"
-// @Router       /watchlist/update [patch]
+// @Failure      400      {object}  problem.Problem  "Invalid WatchList Data"
+// @Failure      404      {object}  problem.Problem  "WatchList not found"
+// @Failure      409      {object}  problem.Problem  "Status transition not allowed or WatchList already exists"
+// @Failure      500      {object}  problem.Problem  "Failed to update WatchList"
+// @Router       /v1/watchlist/update [patch]
 func (watchListHandler *WatchListHandler) UpdateWatchListHandler(ctx *gin.Context) {
 	//getting param from POST request body
 	var body models.WatchListUpdateRequest
 
 	// this will bind data coming from POST request
-	err := ctx.BindJSON(&body)
+	err := ctx.ShouldBindJSON(&body)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid WatchList Data",
-			"details": err.Error(),
-		})
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err))
 		return
 	}
 
-	rowAffected, err := watchListHandler.WatchListModel.UpdateWatchList(body)
+	rowAffected, err := watchListHandler.writer(ctx).UpdateWatchList(body)
+	if errors.Is(err, repositories.ErrInvalidStatus) || errors.Is(err, repositories.ErrInvalidWatchList) {
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err))
+		return
+	}
+	var transitionError *repositories.StatusTransitionError
+	if errors.As(err, &transitionError) {
+		ctx.Error(problem.New(problem.Conflict, transitionError.Error()))
+		return
+	}
+	if errors.Is(err, repositories.ErrWatchListExists) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList already exists", err))
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update WatchList",
-			"details": err.Error(),
-			"body":    body,
-		})
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to update WatchList", err))
+		return
+	}
+	if rowAffected == 0 {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
 		return
 	}
 
@@ -247,3 +464,259 @@ func (watchListHandler *WatchListHandler) UpdateWatchListHandler(ctx *gin.Contex
 		"body":         body,
 	})
 }
+
+// TransitionWatchListHandler godoc
+// @Summary      Move a watchlist entry to another status
+// @Description  Statuses follow the lifecycle not watched -> watching -> watched, with on hold and dropped on the side.
+// @Description  started_at, finished_at and status_changed_at are set on the way, at defaults to now
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      string                             true  "Watchlist ID"
+// @Param        request       body      models.WatchListTransitionRequest  true  "New status"
+// @Success      200           {object}  models.Watchlist
+// @Failure      400           {object}  problem.Problem  "Invalid status"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      409           {object}  problem.Problem  "Status transition not allowed"
+// @Failure      500           {object}  problem.Problem  "Failed to change WatchList status"
+// @Router       /v1/watchlist/{watchlist_id}/transition [post]
+func (watchListHandler *WatchListHandler) TransitionWatchListHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	var body models.WatchListTransitionRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid status", err))
+		return
+	}
+
+	at := time.Now()
+	if body.At != nil {
+		at = *body.At
+	}
+
+	watchList, err := watchListHandler.writer(ctx).TransitionWatchList(watchlist_id_param, body.Status, at)
+	if errors.Is(err, repositories.ErrInvalidStatus) {
+		ctx.Error(problem.Invalid("Invalid status", err))
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	var transitionError *repositories.StatusTransitionError
+	if errors.As(err, &transitionError) {
+		ctx.Error(problem.New(problem.Conflict, transitionError.Error()).With("allowed", models.StatusTransitions[transitionError.From]))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to change WatchList status", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// UpdateProgressHandler godoc
+// @Summary      Save the playback position of a watchlist entry
+// @Description  Stores where playback stopped. Positions before the stored one are ignored unless reset is true, so updates can be replayed.
+// @Description  The entry becomes watching, and watched once the position passes the completion threshold of the runtime
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      string                  true  "Watchlist ID"
+// @Param        request       body      models.ProgressRequest  true  "Playback position"
+// @Success      200           {object}  models.Watchlist
+// @Failure      400           {object}  problem.Problem  "Invalid progress"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      500           {object}  problem.Problem  "Failed to update progress"
+// @Router       /v1/watchlist/{watchlist_id}/progress [put]
+func (watchListHandler *WatchListHandler) UpdateProgressHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	var body models.ProgressRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid progress", err))
+		return
+	}
+
+	watchList, err := watchListHandler.writer(ctx).UpdateProgress(watchlist_id_param, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to update progress", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// MoveWatchListHandler godoc
+// @Summary      Move a watchlist entry in the manual order
+// @Description  Places the entry right before the entry before, right after the entry after, or between both when both are sent.
+// @Description  Only the moved entry is updated. The order is read with sort=rank, a conflict means it changed and must be reloaded
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      string                       true  "Watchlist ID"
+// @Param        request       body      models.WatchListMoveRequest  true  "Anchors"
+// @Success      200           {object}  models.Watchlist
+// @Failure      400           {object}  problem.Problem  "Invalid move"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      409           {object}  problem.Problem  "Order changed"
+// @Failure      500           {object}  problem.Problem  "Failed to move WatchList"
+// @Router       /v1/watchlist/{watchlist_id}/move [post]
+func (watchListHandler *WatchListHandler) MoveWatchListHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	var body models.WatchListMoveRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err == nil && body.Before == nil && body.After == nil {
+		err = errors.New("before or after is required")
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid move", err))
+		return
+	}
+
+	watchList, err := watchListHandler.writer(ctx).MoveWatchList(watchlist_id_param, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrInvalidMove) {
+		ctx.Error(problem.Invalid("Invalid move", err))
+		return
+	}
+	if errors.Is(err, repositories.ErrRankConflict) {
+		ctx.Error(problem.Wrap(problem.Conflict, "Order changed", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to move WatchList", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// GetTrashHandler godoc
+// @Summary      Retrieve the trash
+// @Description  Fetches the deleted entries which are not purged yet, the last deleted first.
+// @Description  An entry is purged TRASH_RETENTION after it was deleted
+// @Tags         watchlists
+// @Produce      json
+// @Success      200  {array}   models.Watchlist
+// @Failure      500  {object}  problem.Problem  "Failed to get Trash"
+// @Router       /v1/trash [get]
+func (watchListHandler *WatchListHandler) GetTrashHandler(ctx *gin.Context) {
+	watchLists, err := watchListHandler.WatchListModel.GetTrash()
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get Trash", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchLists)
+}
+
+// RestoreWatchListHandler godoc
+// @Summary      Restore a watchlist entry from the trash
+// @Description  Takes a deleted entry out of the trash with its tags, credits, review and viewings, it goes to the end of the manual order.
+// @Description  Lists it was in are not restored
+// @Tags         watchlists
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {object}  models.Watchlist
+// @Failure      404           {object}  problem.Problem  "WatchList not in the trash"
+// @Failure      409           {object}  problem.Problem  "WatchList already exists"
+// @Failure      500           {object}  problem.Problem  "Failed to restore WatchList"
+// @Router       /v1/watchlist/{watchlist_id}/restore [post]
+func (watchListHandler *WatchListHandler) RestoreWatchListHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	watchList, err := watchListHandler.writer(ctx).RestoreWatchList(watchlist_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not in the trash"))
+		return
+	}
+	if errors.Is(err, repositories.ErrWatchListExists) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList already exists", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to restore WatchList", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// addEnrichedWatchList handles POST /watchlist/add?enrich=true
+func (watchListHandler *WatchListHandler) addEnrichedWatchList(ctx *gin.Context) {
+	if watchListHandler.MetadataProvider == nil {
+		ctx.Error(problem.New(problem.BadRequest, "Metadata enrichment is not configured: set TMDB_API_KEY to enable enrich=true"))
+		return
+	}
+
+	var body models.WatchListEnrichRequest
+	err := ctx.ShouldBindJSON(&body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err))
+		return
+	}
+
+	found, err := watchListHandler.MetadataProvider.Lookup(ctx.Request.Context(), body.Title, body.ReleaseYear)
+	if errors.Is(err, metadata.ErrNotFound) {
+		ctx.Error(problem.Wrap(problem.NotFound, "No metadata found for title", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Upstream, "Failed to fetch metadata", err))
+		return
+	}
+
+	// fields sent by the client win over the provider values
+	watchList := models.Watchlist{
+		Title:       found.Title,
+		ReleaseYear: found.ReleaseYear,
+		Genre:       strings.Join(found.Genres, ", "),
+		Director:    strings.Join(found.Directors, ", "),
+		Status:      "not watched",
+		Runtime:     found.RuntimeMinutes,
+		PosterPath:  found.PosterPath,
+		ExternalIDs: found.ExternalIDs,
+		Genres:      found.Genres,
+	}
+	if body.ReleaseYear != 0 {
+		watchList.ReleaseYear = body.ReleaseYear
+	}
+	if body.Genre != "" {
+		// the genres win over the genre string
+		watchList.Genre = body.Genre
+		watchList.Genres = nil
+	}
+	if body.Director != "" {
+		watchList.Director = body.Director
+	}
+	if body.Status != "" {
+		watchList.Status = body.Status
+	}
+	if watchList.Title == "" {
+		watchList.Title = body.Title
+	}
+
+	watchListAdded, err := watchListHandler.writer(ctx).AddWatchList(watchList)
+	if errors.Is(err, repositories.ErrInvalidStatus) || errors.Is(err, repositories.ErrInvalidWatchList) {
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err))
+		return
+	}
+	if errors.Is(err, repositories.ErrWatchListExists) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList already exists", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to add WatchList data", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, watchListAdded)
+}
@@ -0,0 +1,545 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/saketV8/cine-dots/pkg/etag"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/patch"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/saketV8/cine-dots/pkg/utils"
+)
+
+// v2 routes
+// the watchlist is a resource at /api/v2/watchlist/{id}, the status codes follow the HTTP semantics:
+// 201 with a Location on create, 204 on delete, 404 for a missing entry, 409 for a conflict
+// and 422 for a body which is valid JSON but fails the validation
+// the ETag of an entry is its version, writes must send it in If-Match: 428 without it and 412 when the entry changed
+// =====================================================================================
+
+// AddWatchListV2Handler godoc
+// @Summary      Create a watchlist entry
+// @Description  Creates an entry, the Location header is the URL of the new entry
+// @Tags         watchlists v2
+// @Accept       json
+// @Produce      json
+// @Param        watchlist        body      models.WatchListAddRequestExample  true   "Watchlist Data"
+// @Param        Idempotency-Key  header    string                             false  "Key to retry the request safely, a retry gets the first response"
+// @Success      201              {object}  models.Watchlist
+// @Header       201              {string}  Location  "/api/v2/watchlist/{watchlist_id}"
+// @Header       201              {string}  ETag      "\"1\""
+// @Failure      400              {object}  problem.Problem  "Malformed JSON"
+// @Failure      409              {object}  problem.Problem  "WatchList already exists"
+// @Failure      422              {object}  problem.Problem  "Invalid WatchList Data"
+// @Failure      500              {object}  problem.Problem  "Failed to add WatchList"
+// @Router       /v2/watchlist [post]
+func (watchListHandler *WatchListHandler) AddWatchListV2Handler(ctx *gin.Context) {
+	var body models.Watchlist
+	if !bindV2Body(ctx, &body, nil) {
+		return
+	}
+
+	watchList, err := watchListHandler.writer(ctx).AddWatchList(body)
+	if watchListV2Error(ctx, err, "Failed to add WatchList") {
+		return
+	}
+
+	ctx.Header("Location", watchListV2Location(watchList.WatchlistID))
+	ctx.Header("ETag", etag.Strong(watchList.Version))
+	ctx.JSON(http.StatusCreated, watchList)
+}
+
+// GetWatchListV2Handler godoc
+// @Summary      Retrieve a watchlist entry
+// @Description  The ETag is the version of the entry, with If-None-Match the entry is only sent when it changed
+// @Tags         watchlists v2
+// @Produce      json
+// @Param        watchlist_id   path      int     true   "Watchlist ID"
+// @Param        If-None-Match  header    string  false  "ETag of the cached entry"
+// @Success      200            {object}  models.Watchlist
+// @Header       200            {string}  ETag  "\"3\""
+// @Success      304            "Not modified"
+// @Failure      404            {object}  problem.Problem  "WatchList not found"
+// @Failure      500           {object}  problem.Problem  "Failed to get WatchList"
+// @Router       /v2/watchlist/{watchlist_id} [get]
+func (watchListHandler *WatchListHandler) GetWatchListV2Handler(ctx *gin.Context) {
+	watchlist_id, ok := watchListV2Id(ctx)
+	if !ok {
+		return
+	}
+
+	watchList, err := watchListHandler.WatchListModel.GetWatchListById(strconv.Itoa(watchlist_id))
+	if watchListV2Error(ctx, err, "Failed to get WatchList") {
+		return
+	}
+	writeWatchList(ctx, watchList)
+}
+
+// ReplaceWatchListV2Handler godoc
+// @Summary      Replace a watchlist entry
+// @Description  Replaces the fields of an entry like PATCH /api/v1/watchlist/update, the ID comes from the path
+// @Tags         watchlists v2
+// @Accept       json
+// @Produce      json
+// @Param        watchlist_id  path      int                                   true  "Watchlist ID"
+// @Param        If-Match      header    string                                true  "ETag of the entry"
+// @Param        request       body      models.WatchListUpdateRequestExample  true  "WatchList Data"
+// @Success      200           {object}  models.Watchlist
+// @Header       200           {string}  ETag  "\"4\""
+// @Failure      400           {object}  problem.Problem  "Malformed JSON"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      409           {object}  problem.Problem  "WatchList already exists or status transition not allowed"
+// @Failure      412           {object}  problem.Problem  "WatchList changed"
+// @Failure      422           {object}  problem.Problem  "Invalid WatchList Data"
+// @Failure      428           {object}  problem.Problem  "If-Match is required"
+// @Failure      500           {object}  problem.Problem  "Failed to update WatchList"
+// @Router       /v2/watchlist/{watchlist_id} [put]
+func (watchListHandler *WatchListHandler) ReplaceWatchListV2Handler(ctx *gin.Context) {
+	watchlist_id, ok := watchListV2Id(ctx)
+	if !ok {
+		return
+	}
+
+	current, ok := watchListHandler.watchListV2Precondition(ctx, watchlist_id, "Failed to update WatchList")
+	if !ok {
+		return
+	}
+
+	body := models.WatchListUpdateRequest{}
+	if !bindV2Body(ctx, &body, func() { body.WatchlistID = watchlist_id; body.Version = current.Version }) {
+		return
+	}
+
+	watchListHandler.updateWatchListV2(ctx, body)
+}
+
+// PatchWatchListV2Handler godoc
+// @Summary      Update some fields of a watchlist entry
+// @Description  Applies a merge patch (application/merge-patch+json, RFC 7396) or a JSON patch (application/json-patch+json, RFC 6902)
+// @Description  to the entry and validates the result, application/json is read as a merge patch.
+// @Description  The patch applies to the fields of models.WatchListDocument, any other field is rejected.
+// @Description  Changing genre without genres or director without credits replaces them
+// @Tags         watchlists v2
+// @Accept       application/merge-patch+json,application/json-patch+json,json
+// @Produce      json
+// @Param        watchlist_id  path      int                       true  "Watchlist ID"
+// @Param        If-Match      header    string                    true  "ETag of the entry"
+// @Param        request       body      models.WatchListDocument  true  "Merge patch, or an array of JSON patch operations"
+// @Success      200           {object}  models.Watchlist
+// @Header       200           {string}  ETag  "\"4\""
+// @Failure      400           {object}  problem.Problem  "Malformed JSON or invalid patch"
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      409           {object}  problem.Problem  "Patch test failed, WatchList already exists or status transition not allowed"
+// @Failure      412           {object}  problem.Problem  "WatchList changed"
+// @Failure      415           {object}  problem.Problem  "Unsupported patch format"
+// @Failure      422           {object}  problem.Problem  "Invalid WatchList Data or patch path not found"
+// @Failure      428           {object}  problem.Problem  "If-Match is required"
+// @Failure      500           {object}  problem.Problem  "Failed to update WatchList"
+// @Router       /v2/watchlist/{watchlist_id} [patch]
+func (watchListHandler *WatchListHandler) PatchWatchListV2Handler(ctx *gin.Context) {
+	watchlist_id, ok := watchListV2Id(ctx)
+	if !ok {
+		return
+	}
+
+	var apply func(document []byte, patch []byte) ([]byte, error)
+	switch ctx.ContentType() {
+	case patch.JSONPatchType:
+		apply = patch.Apply
+	case patch.MergePatchType, binding.MIMEJSON:
+		apply = patch.Merge
+	default:
+		ctx.Error(problem.New(problem.UnsupportedMediaType, "the Content-Type must be "+patch.MergePatchType+" or "+patch.JSONPatchType))
+		return
+	}
+
+	current, ok := watchListHandler.watchListV2Precondition(ctx, watchlist_id, "Failed to update WatchList")
+	if !ok {
+		return
+	}
+	original := watchListDocument(current)
+
+	data, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Malformed JSON", err))
+		return
+	}
+	if !json.Valid(data) {
+		ctx.Error(problem.New(problem.BadRequest, "Malformed JSON: the body is not valid JSON"))
+		return
+	}
+
+	// a merge patch which is not an object would replace the whole entry
+	document, err := json.Marshal(original)
+	if err == nil && ctx.ContentType() != patch.JSONPatchType && !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = fmt.Errorf("%w: a merge patch of an entry is an object", patch.ErrInvalidPatch)
+	}
+	if err == nil {
+		document, err = apply(document, data)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, patch.ErrTestFailed):
+		ctx.Error(problem.New(problem.Conflict, "Patch test failed: "+err.Error()))
+		return
+	case errors.Is(err, patch.ErrPathNotFound):
+		ctx.Error(problem.Invalid("Patch path not found", err).WithStatus(http.StatusUnprocessableEntity))
+		return
+	case errors.Is(err, patch.ErrInvalidPatch):
+		ctx.Error(problem.Invalid("Invalid patch", err))
+		return
+	default:
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to update WatchList", err))
+		return
+	}
+
+	// the patched document must still be a watchlist entry, unknown fields are rejected
+	var patched models.WatchListDocument
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&patched)
+	if err == nil {
+		err = binding.Validator.ValidateStruct(patched)
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid WatchList Data", err).WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+
+	update := patchedUpdateRequest(watchlist_id, original, patched)
+	update.Version = current.Version
+	watchListHandler.updateWatchListV2(ctx, update)
+}
+
+// DeleteWatchListV2Handler godoc
+// @Summary      Delete a watchlist entry
+// @Description  Moves the entry to the trash, see POST /v1/watchlist/{watchlist_id}/restore
+// @Tags         watchlists v2
+// @Param        watchlist_id  path    int     true  "Watchlist ID"
+// @Param        If-Match      header  string  true  "ETag of the entry"
+// @Success      204
+// @Failure      404  {object}  problem.Problem  "WatchList not found"
+// @Failure      412  {object}  problem.Problem  "WatchList changed"
+// @Failure      428  {object}  problem.Problem  "If-Match is required"
+// @Failure      500  {object}  problem.Problem  "Failed to delete WatchList"
+// @Router       /v2/watchlist/{watchlist_id} [delete]
+func (watchListHandler *WatchListHandler) DeleteWatchListV2Handler(ctx *gin.Context) {
+	watchlist_id, ok := watchListV2Id(ctx)
+	if !ok {
+		return
+	}
+
+	current, ok := watchListHandler.watchListV2Precondition(ctx, watchlist_id, "Failed to delete WatchList")
+	if !ok {
+		return
+	}
+
+	rowAffected, err := watchListHandler.writer(ctx).DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: watchlist_id, Version: current.Version})
+	if err == nil && rowAffected == 0 {
+		err = sql.ErrNoRows
+	}
+	if watchListV2Error(ctx, err, "Failed to delete WatchList") {
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// BatchWatchListV2Handler godoc
+// @Summary      Create, update and delete many watchlist entries
+// @Description  Runs the operations in order in one transaction. With atomic=true (the default) they are all written or none:
+// @Description  the first failing operation is answered with its problem and its index. With atomic=false each operation
+// @Description  is written or fails on its own and the results hold the status and the problem of each one.
+// @Description  An update is a full replace like PUT, version is like If-Match. A batch has at most BATCH_MAX_SIZE operations, 100 by default
+// @Tags         watchlists v2
+// @Accept       json
+// @Produce      json
+// @Param        operations  body      []models.WatchListBatchOperation  true   "Operations"
+// @Param        atomic      query     bool                              false  "All or nothing, true by default"
+// @Success      200         {array}   models.WatchListBatchResult
+// @Failure      400         {object}  problem.Problem  "Malformed JSON"
+// @Failure      404         {object}  problem.Problem  "WatchList not found"
+// @Failure      409         {object}  problem.Problem  "WatchList already exists or status transition not allowed"
+// @Failure      412         {object}  problem.Problem  "WatchList changed"
+// @Failure      422         {object}  problem.Problem  "Invalid operation or too many operations"
+// @Failure      500         {object}  problem.Problem  "Failed to run the batch"
+// @Router       /v2/watchlist/batch [post]
+func (watchListHandler *WatchListHandler) BatchWatchListV2Handler(ctx *gin.Context) {
+	atomic, err := strconv.ParseBool(ctx.DefaultQuery("atomic", "true"))
+	if err != nil {
+		ctx.Error(problem.New(problem.BadRequest, "atomic must be true or false"))
+		return
+	}
+
+	operations := []models.WatchListBatchOperation{}
+	data, err := io.ReadAll(ctx.Request.Body)
+	if err == nil {
+		err = json.Unmarshal(data, &operations)
+	}
+	if err != nil {
+		ctx.Error(problem.Invalid("Malformed JSON", err))
+		return
+	}
+
+	// an operation which can not be read fails on its own, the repository does not run it
+	writes := make([]models.WatchListBatchWrite, len(operations))
+	for i, operation := range operations {
+		writes[i] = batchWrite(operation)
+	}
+
+	watchLists, errs, err := watchListHandler.writer(ctx).BatchWatchList(writes, atomic)
+	var batchError *repositories.BatchError
+	switch {
+	case errors.Is(err, repositories.ErrInvalidBatch):
+		ctx.Error(problem.New(problem.Validation, err.Error()).WithStatus(http.StatusUnprocessableEntity))
+		return
+	case errors.As(err, &batchError):
+		ctx.Error(watchListV2Problem(batchError.Err, "Failed to run the batch").With("index", batchError.Index))
+		return
+	case err != nil:
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to run the batch", err))
+		return
+	}
+
+	results := make([]models.WatchListBatchResult, len(operations))
+	for i, operation := range operations {
+		results[i] = models.WatchListBatchResult{Index: i, Op: operation.Op}
+
+		if errs[i] != nil {
+			details := problem.Details(ctx, watchListV2Problem(errs[i], "Failed to run the operation"))
+			results[i].Status = details.Status
+			results[i].Error = details
+			continue
+		}
+
+		switch operation.Op {
+		case "create":
+			results[i].Status = http.StatusCreated
+			results[i].Item = &watchLists[i]
+		case "update":
+			results[i].Status = http.StatusOK
+			results[i].Item = &watchLists[i]
+		default:
+			results[i].Status = http.StatusNoContent
+		}
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
+
+// batchWrite reads an operation of a batch like the body of the route it stands for
+func batchWrite(operation models.WatchListBatchOperation) models.WatchListBatchWrite {
+	write := models.WatchListBatchWrite{Op: operation.Op}
+
+	err := binding.Validator.ValidateStruct(operation)
+	if err != nil {
+		write.Err = problem.Invalid("Invalid operation", err).WithStatus(http.StatusUnprocessableEntity)
+		return write
+	}
+
+	switch operation.Op {
+	case "create":
+		invalid := decodeV2Body(operation.Item, &write.Create, nil)
+		if invalid != nil {
+			write.Err = invalid
+		}
+	case "update":
+		invalid := decodeV2Body(operation.Item, &write.Update, func() { write.Update.WatchlistID = operation.WatchlistID; write.Update.Version = operation.Version })
+		if invalid != nil {
+			write.Err = invalid
+		}
+	case "delete":
+		write.Delete = models.WatchListDeleteRequest{WatchlistID: operation.WatchlistID, Version: operation.Version}
+	}
+	return write
+}
+
+func (watchListHandler *WatchListHandler) updateWatchListV2(ctx *gin.Context, body models.WatchListUpdateRequest) {
+	rowAffected, err := watchListHandler.writer(ctx).UpdateWatchList(body)
+	if err == nil && rowAffected == 0 {
+		err = sql.ErrNoRows
+	}
+	if watchListV2Error(ctx, err, "Failed to update WatchList") {
+		return
+	}
+
+	watchList, err := watchListHandler.WatchListModel.GetWatchListById(strconv.Itoa(body.WatchlistID))
+	if watchListV2Error(ctx, err, "Failed to update WatchList") {
+		return
+	}
+	ctx.Header("ETag", etag.Strong(watchList.Version))
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// watchListV2Precondition loads the entry a write applies to and checks it against If-Match
+// the write then only applies to the version loaded, so a change in between is a 412 too
+func (watchListHandler *WatchListHandler) watchListV2Precondition(ctx *gin.Context, watchlistID int, message string) (models.Watchlist, bool) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		ctx.Error(problem.New(problem.PreconditionRequired, "If-Match is required, send the ETag of the entry"))
+		return models.Watchlist{}, false
+	}
+
+	current, err := watchListHandler.WatchListModel.GetWatchListById(strconv.Itoa(watchlistID))
+	if watchListV2Error(ctx, err, message) {
+		return models.Watchlist{}, false
+	}
+
+	if !etag.MatchStrong(ifMatch, etag.Strong(current.Version)) {
+		ctx.Header("ETag", etag.Strong(current.Version))
+		ctx.Error(problem.New(problem.PreconditionFailed, "WatchList changed: If-Match does not match the ETag of the entry, read it again"))
+		return models.Watchlist{}, false
+	}
+	return current, true
+}
+
+// watchListV2Location is the URL of an entry on the v2 routes
+func watchListV2Location(watchlistID int) string {
+	return utils.ROUTER_PREFIX + utils.ROUTER_PREFIX_VERSION_2 + "/watchlist/" + strconv.Itoa(watchlistID)
+}
+
+// watchListV2Id reads the ID of the path, an ID which is not a number can not exist so it is a 404
+func watchListV2Id(ctx *gin.Context) (int, bool) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	watchlistID, err := strconv.Atoi(watchlist_id_param)
+	if err != nil || watchlistID < 1 {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return 0, false
+	}
+	return watchlistID, true
+}
+
+// bindV2Body decodes the JSON body into body, runs prepare and validates the result
+// malformed JSON is a 400 and a body failing the validation a 422
+func bindV2Body(ctx *gin.Context, body any, prepare func()) bool {
+	data, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.Error(problem.Invalid("Malformed JSON", err))
+		return false
+	}
+
+	invalid := decodeV2Body(data, body, prepare)
+	if invalid != nil {
+		ctx.Error(invalid)
+		return false
+	}
+	return true
+}
+
+// decodeV2Body is bindV2Body for a body which is already read, like the item of a batch operation
+func decodeV2Body(data []byte, body any, prepare func()) *problem.Error {
+	err := json.Unmarshal(data, body)
+	if err != nil {
+		return problem.Invalid("Malformed JSON", err)
+	}
+
+	if prepare != nil {
+		prepare()
+	}
+
+	err = binding.Validator.ValidateStruct(body)
+	if err != nil {
+		return problem.Invalid("Invalid WatchList Data", err).WithStatus(http.StatusUnprocessableEntity)
+	}
+	return nil
+}
+
+// watchListV2Error responds with the status of a repository error, it returns false when there is no error
+func watchListV2Error(ctx *gin.Context, err error, message string) bool {
+	if err == nil {
+		return false
+	}
+	ctx.Error(watchListV2Problem(err, message))
+	return true
+}
+
+// watchListV2Problem is the problem of a repository error, message is the detail of an unexpected one
+func watchListV2Problem(err error, message string) *problem.Error {
+	var transitionError *repositories.StatusTransitionError
+	var invalid *problem.Error
+
+	switch {
+	case errors.As(err, &invalid):
+		return invalid
+	case errors.Is(err, sql.ErrNoRows):
+		return problem.New(problem.NotFound, "WatchList not found")
+	case errors.Is(err, repositories.ErrWatchListExists):
+		return problem.Wrap(problem.Conflict, "WatchList already exists", err)
+	case errors.Is(err, repositories.ErrVersionMismatch):
+		return problem.New(problem.PreconditionFailed, "WatchList changed: "+err.Error())
+	case errors.As(err, &transitionError):
+		return problem.New(problem.Conflict, transitionError.Error()).With("allowed", models.StatusTransitions[transitionError.From])
+	case errors.Is(err, repositories.ErrInvalidStatus), errors.Is(err, repositories.ErrInvalidWatchList):
+		return problem.Invalid("Invalid WatchList Data", err).WithStatus(http.StatusUnprocessableEntity)
+	default:
+		return problem.Wrap(problem.Internal, message, err)
+	}
+}
+
+// watchListDocument is the document a PATCH applies to, it holds the fields of the entry which can change
+func watchListDocument(watchList models.Watchlist) models.WatchListDocument {
+	return models.WatchListDocument{
+		Title:       watchList.Title,
+		ReleaseYear: watchList.ReleaseYear,
+		Genre:       watchList.Genre,
+		Director:    watchList.Director,
+		Status:      watchList.Status,
+		AddedDate:   watchList.AddedDate,
+		Kind:        watchList.Kind,
+		Runtime:     watchList.Runtime,
+		PosterPath:  watchList.PosterPath,
+		ExternalIDs: watchList.ExternalIDs,
+		Genres:      watchList.Genres,
+		Credits:     watchList.Credits,
+		Tags:        watchList.Tags,
+		Notes:       watchList.Notes,
+	}
+}
+
+// patchedUpdateRequest is the update request of a patched document
+// genres win over genre and credits over director, so changing the legacy field alone replaces them
+func patchedUpdateRequest(watchlistID int, original models.WatchListDocument, patched models.WatchListDocument) models.WatchListUpdateRequest {
+	genres := patched.Genres
+	if patched.Genre != original.Genre && reflect.DeepEqual(patched.Genres, original.Genres) {
+		genres = nil
+	}
+	credits := patched.Credits
+	if patched.Director != original.Director && reflect.DeepEqual(patched.Credits, original.Credits) {
+		credits = nil
+	}
+
+	// removed tags are an empty list, nil would keep the stored tags
+	tags := patched.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return models.WatchListUpdateRequest{
+		WatchlistID: watchlistID,
+		Title:       patched.Title,
+		ReleaseYear: patched.ReleaseYear,
+		Genre:       patched.Genre,
+		Director:    patched.Director,
+		Status:      patched.Status,
+		AddedDate:   &patched.AddedDate,
+		Kind:        patched.Kind,
+		Runtime:     patched.Runtime,
+		PosterPath:  patched.PosterPath,
+		ExternalIDs: &patched.ExternalIDs,
+		Genres:      genres,
+		Credits:     credits,
+		Tags:        tags,
+		Notes:       &patched.Notes,
+	}
+}
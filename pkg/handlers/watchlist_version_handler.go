@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+// UndoTokenHeader carries the token which undoes the writes of a request, see UndoWatchListHandler
+const UndoTokenHeader = "X-Undo-Token"
+
+// the context key of the undo token of the request
+const undoTokenKey = "undo_token"
+
+// undoTokenWriter adds the undo token to the successful responses only, a failed write has nothing to undo
+type undoTokenWriter struct {
+	gin.ResponseWriter
+	token string
+}
+
+func (writer *undoTokenWriter) WriteHeader(code int) {
+	if code < http.StatusMultipleChoices {
+		writer.Header().Set(UndoTokenHeader, writer.token)
+	}
+	writer.ResponseWriter.WriteHeader(code)
+}
+
+// undoToken is the undo token of the request, it is made on the first write
+func undoToken(ctx *gin.Context) string {
+	token := ctx.GetString(undoTokenKey)
+	if token != "" {
+		return token
+	}
+
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	token = hex.EncodeToString(buf)
+
+	ctx.Set(undoTokenKey, token)
+	ctx.Writer = &undoTokenWriter{ResponseWriter: ctx.Writer, token: token}
+	return token
+}
+
+// GetWatchListVersionsHandler godoc
+// @Summary      Retrieve the versions of a watchlist entry
+// @Description  Lists the content the entry had after each of its writes, oldest first.
+// @Description  Entries from before the versions start with the one they had before their first write
+// @Tags         versions
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Success      200           {array}   models.WatchListVersion
+// @Failure      404           {object}  problem.Problem  "WatchList not found"
+// @Failure      500           {object}  problem.Problem  "Failed to get WatchList Versions"
+// @Router       /v1/watchlist/{watchlist_id}/versions [get]
+func (watchListHandler *WatchListHandler) GetWatchListVersionsHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	versions, err := watchListHandler.WatchListModel.GetWatchListVersions(watchlist_id_param)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to get WatchList Versions", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, versions)
+}
+
+// DiffWatchListVersionsHandler godoc
+// @Summary      Compare two versions of a watchlist entry
+// @Description  Returns the fields which differ between the versions, before holds them as in from and after as in to
+// @Tags         versions
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Param        from          query     int     true  "Version to compare from"
+// @Param        to            query     int     true  "Version to compare to"
+// @Success      200           {object}  models.WatchListVersionDiff
+// @Failure      400           {object}  problem.Problem  "Invalid Query"
+// @Failure      404           {object}  problem.Problem  "WatchList Version not found"
+// @Failure      500           {object}  problem.Problem  "Failed to compare WatchList Versions"
+// @Router       /v1/watchlist/{watchlist_id}/versions/diff [get]
+func (watchListHandler *WatchListHandler) DiffWatchListVersionsHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	var query models.WatchListDiffQuery
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Query", err))
+		return
+	}
+
+	diff, err := watchListHandler.WatchListModel.DiffWatchListVersions(watchlist_id_param, query.From, query.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList Version not found"))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to compare WatchList Versions", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// RevertWatchListHandler godoc
+// @Summary      Revert a watchlist entry to an old version
+// @Description  Writes the content of the version back as a new version, the place in the manual order is kept.
+// @Description  The status and its timeline are written back as they were, without the lifecycle
+// @Tags         versions
+// @Produce      json
+// @Param        watchlist_id  path      string  true  "Watchlist ID"
+// @Param        version       query     int     true  "Version to revert to"
+// @Success      200           {object}  models.Watchlist
+// @Header       200           {string}  X-Undo-Token  "Token which undoes the revert"
+// @Failure      400           {object}  problem.Problem  "Invalid Query"
+// @Failure      404           {object}  problem.Problem  "WatchList Version not found"
+// @Failure      409           {object}  problem.Problem  "WatchList already exists"
+// @Failure      500           {object}  problem.Problem  "Failed to revert WatchList"
+// @Router       /v1/watchlist/{watchlist_id}/revert [post]
+func (watchListHandler *WatchListHandler) RevertWatchListHandler(ctx *gin.Context) {
+	watchlist_id_param := ctx.Param("watchlist_id")
+
+	var query models.WatchListRevertQuery
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		ctx.Error(problem.Invalid("Invalid Query", err))
+		return
+	}
+
+	watchList, err := watchListHandler.writer(ctx).RevertWatchList(watchlist_id_param, query.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.Error(problem.New(problem.NotFound, "WatchList Version not found"))
+		return
+	}
+	if errors.Is(err, repositories.ErrWatchListExists) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList already exists", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to revert WatchList", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchList)
+}
+
+// UndoWatchListHandler godoc
+// @Summary      Undo the writes of a request
+// @Description  Puts the entries a request wrote back as they were before it, with the X-Undo-Token of its response.
+// @Description  A created entry goes to the trash and a deleted one comes back. The token can be used for UNDO_TTL,
+// @Description  the undo has its own token so it can be undone too
+// @Tags         versions
+// @Produce      json
+// @Param        undo_token  path      string  true  "X-Undo-Token of the response"
+// @Success      200         {array}   models.Watchlist
+// @Header       200         {string}  X-Undo-Token  "Token which undoes the undo"
+// @Failure      404         {object}  problem.Problem  "Undo token not found or expired"
+// @Failure      409         {object}  problem.Problem  "WatchList changed since"
+// @Failure      500         {object}  problem.Problem  "Failed to undo"
+// @Router       /v1/undo/{undo_token} [post]
+func (watchListHandler *WatchListHandler) UndoWatchListHandler(ctx *gin.Context) {
+	undo_token_param := ctx.Param("undo_token")
+
+	watchLists, err := watchListHandler.writer(ctx).UndoWatchList(undo_token_param)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, repositories.ErrUndoExpired) {
+		ctx.Error(problem.New(problem.NotFound, "Undo token not found or expired"))
+		return
+	}
+	if errors.Is(err, repositories.ErrUndoConflict) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList changed since", err))
+		return
+	}
+	if errors.Is(err, repositories.ErrWatchListExists) {
+		ctx.Error(problem.Wrap(problem.Conflict, "WatchList already exists", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(problem.Wrap(problem.Internal, "Failed to undo", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, watchLists)
+}
@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// WatchListStore is the part of the watchlist repository used by the importer
+type WatchListStore interface {
+	FindDuplicateWatchList(title string, releaseYear int, externalIDs models.ExternalIDs) (models.Watchlist, bool, error)
+	AddWatchList(watchList models.Watchlist) (models.Watchlist, error)
+}
+
+// ReviewStore is the part of the review repository used by the importer
+type ReviewStore interface {
+	AddReview(watchlist_id string, review models.ReviewRequest) (models.Review, error)
+}
+
+// ViewingStore is the part of the viewing repository used by the importer
+type ViewingStore interface {
+	AddViewing(watchlist_id string, viewing models.ViewingRequest) (models.Viewing, error)
+}
+
+// Stores are the repositories an import writes to
+type Stores struct {
+	WatchLists WatchListStore
+	Reviews    ReviewStore
+	Viewings   ViewingStore
+}
+
+// states of the "watchlist.import" jobs as the import status reports them
+var importStates = map[string]string{
+	"queued":    "queued",
+	"running":   "running",
+	"succeeded": "completed",
+	"dead":      "failed",
+	"cancelled": "cancelled",
+}
+
+// Import adds the item unless the same title (by IMDb/TMDb ID or title + year) already exists
+// each watch date is logged as a viewing and the rating is the review
+// it returns whether the item was imported and whether it was a duplicate
+func Import(stores Stores, item models.ImportItem) (bool, bool, error) {
+	externalIDs := models.ExternalIDs{
+		IMDbID: item.IMDbID,
+		TMDbID: item.TMDbID,
+	}
+
+	_, found, err := stores.WatchLists.FindDuplicateWatchList(item.Title, item.ReleaseYear, externalIDs)
+	if err != nil {
+		return false, false, err
+	}
+	if found {
+		return false, true, nil
+	}
+
+	watchList := models.Watchlist{
+		Title:       item.Title,
+		ReleaseYear: item.ReleaseYear,
+		Status:      item.Status,
+		ExternalIDs: externalIDs,
+	}
+
+	added, err := stores.WatchLists.AddWatchList(watchList)
+	if err != nil {
+		return false, false, err
+	}
+	watchlistID := strconv.Itoa(added.WatchlistID)
+
+	for _, watchedAt := range item.WatchDates {
+		_, err = stores.Viewings.AddViewing(watchlistID, models.ViewingRequest{WatchedOn: &watchedAt})
+		if err != nil {
+			return false, false, err
+		}
+	}
+
+	// Trakt rates from 1 to 10, reviews from 0.5 to 5 stars
+	if item.Rating >= 1 && item.Rating <= 10 {
+		_, err = stores.Reviews.AddReview(watchlistID, models.ReviewRequest{Rating: float64(item.Rating) / 2})
+		if err != nil {
+			return false, false, err
+		}
+	}
+	return true, false, nil
+}
+
+// ImportJob reads the progress of an import from its job
+func ImportJob(job models.Job) (models.ImportJob, error) {
+	var payload models.ImportPayload
+	err := json.Unmarshal(job.Payload, &payload)
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+
+	progress := models.ImportProgress{}
+	if len(job.Result) > 0 {
+		err = json.Unmarshal(job.Result, &progress)
+		if err != nil {
+			return models.ImportJob{}, err
+		}
+	}
+	if progress.Errors == nil {
+		progress.Errors = []models.ImportItemError{}
+	}
+
+	return models.ImportJob{
+		JobID:      job.JobID,
+		Source:     payload.Source,
+		State:      importStates[job.State],
+		Total:      len(payload.Items),
+		Processed:  progress.Processed,
+		Imported:   progress.Imported,
+		Duplicates: progress.Duplicates,
+		Failed:     progress.Failed,
+		Errors:     progress.Errors,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}, nil
+}
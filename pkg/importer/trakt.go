@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// Trakt export format
+// https://trakt.docs.apiary.io/#reference/sync
+// =====================================================================================
+
+type traktIDs struct {
+	Trakt int    `json:"trakt"`
+	Slug  string `json:"slug"`
+	IMDb  string `json:"imdb"`
+	TMDb  int    `json:"tmdb"`
+}
+
+type traktMedia struct {
+	Title string   `json:"title"`
+	Year  int      `json:"year"`
+	IDs   traktIDs `json:"ids"`
+}
+
+// traktEntry covers the rows of history, watchlist and ratings files
+// history rows have watched_at, watchlist rows have listed_at and ratings rows have rating
+type traktEntry struct {
+	Type      string      `json:"type"`
+	WatchedAt *time.Time  `json:"watched_at"`
+	Rating    int         `json:"rating"`
+	Movie     *traktMedia `json:"movie"`
+	Show      *traktMedia `json:"show"`
+}
+
+// media returns the movie or the show the entry belongs to
+// episode and season rows are mapped to their show
+func (entry traktEntry) media() *traktMedia {
+	if entry.Movie != nil {
+		return entry.Movie
+	}
+	return entry.Show
+}
+
+// ParseTraktExport maps the history, watchlist and ratings files of a Trakt export
+// to import items, a nil or empty file is skipped
+//
+// history and ratings mark a title as "watched", watchlist marks it as "not watched"
+// and a title present in several files is returned once
+func ParseTraktExport(history, watchlist, ratings []byte) ([]models.ImportItem, error) {
+	if isEmptyJSON(history) && isEmptyJSON(watchlist) && isEmptyJSON(ratings) {
+		return nil, errors.New("trakt export is empty, provide at least one of history, watchlist or ratings")
+	}
+
+	merger := newItemMerger()
+
+	files := []struct {
+		name   string
+		data   []byte
+		status string
+	}{
+		{"history", history, "watched"},
+		{"watchlist", watchlist, "not watched"},
+		{"ratings", ratings, "watched"},
+	}
+
+	for _, file := range files {
+		if isEmptyJSON(file.data) {
+			continue
+		}
+
+		var entries []traktEntry
+		err := json.Unmarshal(file.data, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trakt %s file: %w", file.name, err)
+		}
+
+		for _, entry := range entries {
+			media := entry.media()
+			if media == nil || strings.TrimSpace(media.Title) == "" {
+				continue
+			}
+
+			item := models.ImportItem{
+				Title:       strings.TrimSpace(media.Title),
+				ReleaseYear: media.Year,
+				Status:      file.status,
+				IMDbID:      media.IDs.IMDb,
+				TMDbID:      media.IDs.TMDb,
+				Rating:      entry.Rating,
+			}
+			if entry.WatchedAt != nil {
+				item.WatchDates = []time.Time{entry.WatchedAt.UTC()}
+			}
+			merger.add(item)
+		}
+	}
+
+	return merger.items(), nil
+}
+
+func isEmptyJSON(data []byte) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return trimmed == "" || trimmed == "null"
+}
+
+// itemMerger keeps the first seen order of titles while merging repeated entries
+// (a movie watched twice shows up twice in history)
+type itemMerger struct {
+	order []string
+	byKey map[string]*models.ImportItem
+}
+
+func newItemMerger() *itemMerger {
+	return &itemMerger{byKey: map[string]*models.ImportItem{}}
+}
+
+func (merger *itemMerger) add(item models.ImportItem) {
+	key := itemKey(item)
+
+	existing, ok := merger.byKey[key]
+	if !ok {
+		merger.order = append(merger.order, key)
+		merger.byKey[key] = &item
+		return
+	}
+
+	// "watched" wins over "not watched"
+	if item.Status == "watched" {
+		existing.Status = "watched"
+	}
+	if item.Rating > existing.Rating {
+		existing.Rating = item.Rating
+	}
+	// every watch date is kept, a watch repeated in several files counts once
+	for _, watchedAt := range item.WatchDates {
+		if !slices.ContainsFunc(existing.WatchDates, watchedAt.Equal) {
+			existing.WatchDates = append(existing.WatchDates, watchedAt)
+		}
+	}
+	slices.SortFunc(existing.WatchDates, time.Time.Compare)
+	if existing.IMDbID == "" {
+		existing.IMDbID = item.IMDbID
+	}
+	if existing.TMDbID == 0 {
+		existing.TMDbID = item.TMDbID
+	}
+}
+
+func (merger *itemMerger) items() []models.ImportItem {
+	items := make([]models.ImportItem, 0, len(merger.order))
+	for _, key := range merger.order {
+		items = append(items, *merger.byKey[key])
+	}
+	return items
+}
+
+// itemKey prefers the IMDb ID and falls back to title + year
+func itemKey(item models.ImportItem) string {
+	if item.IMDbID != "" {
+		return "imdb:" + item.IMDbID
+	}
+	if item.TMDbID != 0 {
+		return fmt.Sprintf("tmdb:%d", item.TMDbID)
+	}
+	return fmt.Sprintf("title:%s:%d", strings.ToLower(item.Title), item.ReleaseYear)
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+const IdempotencyPurgeKind = "idempotency.purge"
+
+// IdempotencyKeyStore is the part of the idempotency repository used by the purge job
+type IdempotencyKeyStore interface {
+	PurgeIdempotencyKeys() (int, error)
+}
+
+// NewIdempotencyPurgeHandler deletes the expired idempotency keys and their responses, the payload is ignored
+// an expired key is also claimed again by the next request with it, the job keeps the table small
+func NewIdempotencyPurgeHandler(store IdempotencyKeyStore) HandlerFunc {
+	return func(ctx context.Context, job models.Job) error {
+		purged, err := store.PurgeIdempotencyKeys()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("JOBS: purged %d expired idempotency keys", purged)
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+const JobPurgeKind = "jobs.purge"
+
+// JobPurgeStore is the part of the job repository used by the purge job
+type JobPurgeStore interface {
+	PurgeJobs(retention time.Duration) (int, error)
+}
+
+// NewJobPurgeHandler deletes the succeeded and cancelled jobs which finished longer than retention ago, the payload is ignored
+func NewJobPurgeHandler(store JobPurgeStore, retention time.Duration) HandlerFunc {
+	return func(ctx context.Context, job models.Job) error {
+		purged, err := store.PurgeJobs(retention)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("JOBS: purged %d finished jobs", purged)
+		return nil
+	}
+}
@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// HandlerFunc runs a single job, returning an error schedules a retry
+// ctx is cancelled when the job is cancelled or the pool stops
+type HandlerFunc func(ctx context.Context, job models.Job) error
+
+// Store is the part of the job repository used by the workers
+type Store interface {
+	EnqueueJob(kind string, payload json.RawMessage, maxAttempts int) (models.Job, error)
+	ClaimNextJob(now time.Time) (models.Job, bool, error)
+	CompleteJob(jobID int) error
+	RetryJob(jobID int, lastError string, runAt time.Time) error
+	FailJob(jobID int, lastError string) error
+	RequeueRunningJobs() (int, error)
+}
+
+// Pool runs queued jobs with a fixed number of workers
+type Pool struct {
+	Store        Store
+	Concurrency  int
+	PollInterval time.Duration
+
+	// retries wait BaseBackoff * 2^(attempts-1), capped at MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	mu        sync.Mutex
+	handlers  map[string]HandlerFunc
+	schedules map[string]time.Duration
+	running   map[int]context.CancelFunc
+	stop      context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func NewPool(store Store, concurrency int) *Pool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return &Pool{
+		Store:        store,
+		Concurrency:  concurrency,
+		PollInterval: time.Second,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   10 * time.Minute,
+		handlers:     map[string]HandlerFunc{},
+		schedules:    map[string]time.Duration{},
+		running:      map[int]context.CancelFunc{},
+	}
+}
+
+// Register sets the handler for a job kind, it must be called before Start
+func (pool *Pool) Register(kind string, handler HandlerFunc) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.handlers[kind] = handler
+}
+
+// Every enqueues a job of the kind each interval while the pool runs, it must be called before Start
+// the jobs go through the queue like any other, so they show up in /admin/jobs
+func (pool *Pool) Every(kind string, interval time.Duration) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.schedules[kind] = interval
+}
+
+func (pool *Pool) HasHandler(kind string) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	_, ok := pool.handlers[kind]
+	return ok
+}
+
+// Start requeues jobs interrupted by a previous shutdown and starts the workers
+func (pool *Pool) Start(ctx context.Context) error {
+	requeued, err := pool.Store.RequeueRunningJobs()
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		log.Printf("JOBS: requeued %d interrupted job(s)", requeued)
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	pool.mu.Lock()
+	pool.stop = stop
+	pool.mu.Unlock()
+
+	for i := 0; i < pool.Concurrency; i++ {
+		pool.wg.Add(1)
+		go pool.worker(ctx)
+	}
+
+	pool.mu.Lock()
+	for kind, interval := range pool.schedules {
+		pool.wg.Add(1)
+		go pool.schedule(ctx, kind, interval)
+	}
+	pool.mu.Unlock()
+
+	return nil
+}
+
+// Stop cancels the running jobs and waits for the workers to return
+// interrupted jobs stay "running" and are requeued by the next Start
+func (pool *Pool) Stop() {
+	pool.mu.Lock()
+	stop := pool.stop
+	pool.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+	pool.wg.Wait()
+}
+
+// Cancel interrupts a job if it is running on this pool
+func (pool *Pool) Cancel(jobID int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if cancel, ok := pool.running[jobID]; ok {
+		cancel()
+	}
+}
+
+// Backoff returns how long to wait before the next attempt
+func (pool *Pool) Backoff(attempts int) time.Duration {
+	delay := pool.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= pool.MaxBackoff {
+			return pool.MaxBackoff
+		}
+	}
+	return delay
+}
+
+func (pool *Pool) schedule(ctx context.Context, kind string, interval time.Duration) {
+	defer pool.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := pool.Store.EnqueueJob(kind, nil, 1)
+			if err != nil {
+				log.Println("JOBS ERROR: ", err)
+			}
+		}
+	}
+}
+
+func (pool *Pool) worker(ctx context.Context) {
+	defer pool.wg.Done()
+
+	for {
+		ran, err := pool.RunNext(ctx)
+		if err != nil {
+			log.Println("JOBS ERROR: ", err)
+		}
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pool.PollInterval):
+		}
+	}
+}
+
+// RunNext claims and runs one due job, it returns false when the queue is empty
+func (pool *Pool) RunNext(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	job, found, err := pool.Store.ClaimNextJob(time.Now())
+	if err != nil || !found {
+		return false, err
+	}
+
+	pool.mu.Lock()
+	handler, ok := pool.handlers[job.Kind]
+	jobCtx, cancel := context.WithCancel(ctx)
+	pool.running[job.JobID] = cancel
+	pool.mu.Unlock()
+
+	defer func() {
+		pool.mu.Lock()
+		delete(pool.running, job.JobID)
+		pool.mu.Unlock()
+		cancel()
+	}()
+
+	if !ok {
+		return true, pool.Store.FailJob(job.JobID, fmt.Sprintf("no handler registered for job kind %q", job.Kind))
+	}
+
+	err = runHandler(jobCtx, handler, job)
+	if err == nil {
+		return true, pool.Store.CompleteJob(job.JobID)
+	}
+
+	// the pool is stopping, leave the job "running" so the next Start requeues it
+	if ctx.Err() != nil {
+		return true, nil
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		return true, pool.Store.FailJob(job.JobID, err.Error())
+	}
+	return true, pool.Store.RetryJob(job.JobID, err.Error(), time.Now().Add(pool.Backoff(job.Attempts)))
+}
+
+// runHandler turns a panic in a handler into a job error
+func runHandler(ctx context.Context, handler HandlerFunc, job models.Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	return handler(ctx, job)
+}
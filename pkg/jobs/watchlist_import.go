@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/saketV8/cine-dots/pkg/importer"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/saketV8/cine-dots/pkg/validation"
+)
+
+const WatchListImportKind = "watchlist.import"
+
+// JobResultStore is the part of the job repository used by the jobs which report their progress
+type JobResultStore interface {
+	SaveJobResult(jobID int, result json.RawMessage) error
+}
+
+// NewWatchListImportHandler imports the items of the payload, a models.ImportPayload
+// the progress is saved after each item, a retried or requeued job goes on after the last processed item
+func NewWatchListImportHandler(stores importer.Stores, results JobResultStore) HandlerFunc {
+	return func(ctx context.Context, job models.Job) error {
+		var payload models.ImportPayload
+		err := json.Unmarshal(job.Payload, &payload)
+		if err != nil {
+			return err
+		}
+
+		progress := models.ImportProgress{Errors: []models.ImportItemError{}}
+		if len(job.Result) > 0 {
+			err = json.Unmarshal(job.Result, &progress)
+			if err != nil {
+				return err
+			}
+		}
+
+		for progress.Processed < len(payload.Items) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			item := payload.Items[progress.Processed]
+			imported, duplicate, err := importer.Import(stores, item)
+
+			progress.Processed++
+			switch {
+			case err != nil:
+				progress.Failed++
+				log.Printf("JOBS: failed to import %q of job %d: %v", item.Title, job.JobID, err)
+				progress.Errors = append(progress.Errors, models.ImportItemError{Title: item.Title, Details: importItemError(err)})
+			case duplicate:
+				progress.Duplicates++
+			case imported:
+				progress.Imported++
+			}
+
+			result, err := json.Marshal(progress)
+			if err != nil {
+				return err
+			}
+			err = results.SaveJobResult(job.JobID, result)
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("JOBS: imported %d of %d %s items, %d duplicates", progress.Imported, len(payload.Items), payload.Source, progress.Duplicates)
+		return nil
+	}
+}
+
+// importItemError is the detail of a failed item with the messages the handlers send for the same error,
+// the job status is served to clients so any other error is only logged
+func importItemError(err error) string {
+	var invalid *validation.Error
+	switch {
+	case errors.Is(err, repositories.ErrWatchListExists):
+		return "WatchList already exists"
+	case errors.As(err, &invalid):
+		return "Invalid WatchList Data: " + invalid.Error()
+	case errors.Is(err, repositories.ErrInvalidStatus):
+		return "Invalid WatchList Data: " + repositories.ErrInvalidStatus.Error()
+	case errors.Is(err, repositories.ErrInvalidWatchList):
+		return "Invalid WatchList Data"
+	default:
+		return "Failed to import WatchList"
+	}
+}
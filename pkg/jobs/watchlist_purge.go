@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+const WatchListPurgeKind = "watchlist.purge"
+
+// TrashStore is the part of the watchlist repository used by the purge job
+type TrashStore interface {
+	PurgeTrash(retention time.Duration) (int, error)
+}
+
+// NewWatchListPurgeHandler deletes for good the entries which are in the trash for longer than retention, the payload is ignored
+func NewWatchListPurgeHandler(store TrashStore, retention time.Duration) HandlerFunc {
+	return func(ctx context.Context, job models.Job) error {
+		purged, err := store.PurgeTrash(retention)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("JOBS: purged %d entries from the trash", purged)
+		return nil
+	}
+}
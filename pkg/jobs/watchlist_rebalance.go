@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+const WatchListRebalanceKind = "watchlist.rebalance"
+
+// RankStore is the part of the watchlist repository used by the rebalance job
+type RankStore interface {
+	RebalanceRanks() (int, error)
+}
+
+// NewWatchListRebalanceHandler gives every entry a short rank key again, the payload is ignored
+// moves rebalance on their own once a key gets too long, the job also runs every RANK_REBALANCE_INTERVAL
+func NewWatchListRebalanceHandler(store RankStore) HandlerFunc {
+	return func(ctx context.Context, job models.Job) error {
+		rebalanced, err := store.RebalanceRanks()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("JOBS: rebalanced the rank of %d entries", rebalanced)
+		return nil
+	}
+}
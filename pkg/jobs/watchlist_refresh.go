@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/saketV8/cine-dots/pkg/metadata"
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+const WatchListRefreshKind = "watchlist.refresh"
+
+// WatchListStore is the part of the watchlist repository used by the refresh job
+type WatchListStore interface {
+	GetWatchListById(watchlist_id string) (models.Watchlist, error)
+	UpdateWatchList(watchList models.WatchListUpdateRequest) (int, error)
+}
+
+// NewWatchListRefreshHandler re-fetches the metadata of one entry
+// and overwrites its title, release year, genre, director, runtime, poster and external IDs
+func NewWatchListRefreshHandler(store WatchListStore, provider metadata.MetadataProvider) HandlerFunc {
+	return func(ctx context.Context, job models.Job) error {
+		var payload models.WatchListRefreshPayload
+		err := json.Unmarshal(job.Payload, &payload)
+		if err != nil {
+			return err
+		}
+
+		watchList, err := store.GetWatchListById(strconv.Itoa(payload.WatchlistID))
+		if err != nil {
+			return err
+		}
+
+		found, err := provider.Lookup(ctx, watchList.Title, watchList.ReleaseYear)
+		if errors.Is(err, metadata.ErrNotFound) {
+			// nothing to refresh, retrying will not change that
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		update := models.WatchListUpdateRequest{
+			WatchlistID: watchList.WatchlistID,
+			Title:       watchList.Title,
+			ReleaseYear: watchList.ReleaseYear,
+			Genre:       watchList.Genre,
+			Director:    watchList.Director,
+			Status:      watchList.Status,
+			ExternalIDs: &found.ExternalIDs,
+		}
+		// keep the current value when the provider has none
+		if found.Title != "" {
+			update.Title = found.Title
+		}
+		if found.ReleaseYear != 0 {
+			update.ReleaseYear = found.ReleaseYear
+		}
+		if len(found.Genres) > 0 {
+			update.Genre = strings.Join(found.Genres, ", ")
+		}
+		if len(found.Directors) > 0 {
+			update.Director = strings.Join(found.Directors, ", ")
+		}
+		// 0 and empty keep the stored runtime and poster
+		update.Runtime = found.RuntimeMinutes
+		update.PosterPath = found.PosterPath
+
+		_, err = store.UpdateWatchList(update)
+		return err
+	}
+}
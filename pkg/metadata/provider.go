@@ -0,0 +1,24 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// ErrNotFound is returned when the provider has no title matching the lookup
+var ErrNotFound = errors.New("no metadata found for title")
+
+// MetadataProvider fetches canonical metadata for a title
+// year is optional, pass 0 when it is not known
+type MetadataProvider interface {
+	Lookup(ctx context.Context, title string, year int) (models.Metadata, error)
+}
+
+// Cache stores provider responses so the same title is not fetched twice
+type Cache interface {
+	GetMetadata(cacheKey string, maxAge time.Duration) (models.Metadata, bool, error)
+	PutMetadata(cacheKey string, metadata models.Metadata) error
+}
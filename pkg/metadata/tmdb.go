@@ -0,0 +1,182 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// TMDb API v3
+// https://developer.themoviedb.org/reference/search-movie
+// https://developer.themoviedb.org/reference/movie-details
+// =====================================================================================
+
+const TMDbBaseURL = "https://api.themoviedb.org/3"
+
+// TMDbProvider looks titles up on TMDb
+// HTTPClient and BaseURL can be swapped so tests run against an httptest server
+type TMDbProvider struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+
+	// Cache is optional, responses are cached for CacheTTL (0 keeps them forever)
+	Cache    Cache
+	CacheTTL time.Duration
+}
+
+func NewTMDbProvider(apiKey string, cache Cache) *TMDbProvider {
+	return &TMDbProvider{
+		BaseURL:    TMDbBaseURL,
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Cache:      cache,
+		CacheTTL:   7 * 24 * time.Hour,
+	}
+}
+
+type tmdbSearchResponse struct {
+	Results []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+}
+
+type tmdbMovieResponse struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	Runtime     int    `json:"runtime"`
+	PosterPath  string `json:"poster_path"`
+	Genres      []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Credits struct {
+		Crew []struct {
+			Name string `json:"name"`
+			Job  string `json:"job"`
+		} `json:"crew"`
+	} `json:"credits"`
+	ExternalIDs struct {
+		IMDbID     string `json:"imdb_id"`
+		WikidataID string `json:"wikidata_id"`
+	} `json:"external_ids"`
+}
+
+func (provider *TMDbProvider) Lookup(ctx context.Context, title string, year int) (models.Metadata, error) {
+	cacheKey := fmt.Sprintf("tmdb:%s:%d", strings.ToLower(strings.TrimSpace(title)), year)
+
+	if provider.Cache != nil {
+		cached, found, err := provider.Cache.GetMetadata(cacheKey, provider.CacheTTL)
+		if err != nil {
+			// a broken cache should not stop the lookup
+			log.Println("METADATA CACHE ERROR: ", err)
+		}
+		if found {
+			return cached, nil
+		}
+	}
+
+	metadata, err := provider.fetch(ctx, title, year)
+	if err != nil {
+		return models.Metadata{}, err
+	}
+
+	if provider.Cache != nil {
+		err = provider.Cache.PutMetadata(cacheKey, metadata)
+		if err != nil {
+			log.Println("METADATA CACHE ERROR: ", err)
+		}
+	}
+
+	return metadata, nil
+}
+
+func (provider *TMDbProvider) fetch(ctx context.Context, title string, year int) (models.Metadata, error) {
+	query := url.Values{}
+	query.Set("query", title)
+	if year > 0 {
+		query.Set("year", strconv.Itoa(year))
+	}
+
+	var search tmdbSearchResponse
+	err := provider.get(ctx, "/search/movie", query, &search)
+	if err != nil {
+		return models.Metadata{}, err
+	}
+	if len(search.Results) == 0 {
+		return models.Metadata{}, ErrNotFound
+	}
+
+	query = url.Values{}
+	query.Set("append_to_response", "credits,external_ids")
+
+	var movie tmdbMovieResponse
+	err = provider.get(ctx, "/movie/"+strconv.Itoa(search.Results[0].ID), query, &movie)
+	if err != nil {
+		return models.Metadata{}, err
+	}
+
+	metadata := models.Metadata{
+		Provider:       "tmdb",
+		Title:          movie.Title,
+		RuntimeMinutes: movie.Runtime,
+		PosterPath:     movie.PosterPath,
+		Genres:         []string{},
+		Directors:      []string{},
+		ExternalIDs: models.ExternalIDs{
+			TMDbID:     movie.ID,
+			IMDbID:     movie.ExternalIDs.IMDbID,
+			WikidataID: movie.ExternalIDs.WikidataID,
+		},
+	}
+
+	// release_date is "YYYY-MM-DD" and may be empty for unreleased titles
+	if len(movie.ReleaseDate) >= 4 {
+		metadata.ReleaseYear, _ = strconv.Atoi(movie.ReleaseDate[:4])
+	}
+	for _, genre := range movie.Genres {
+		metadata.Genres = append(metadata.Genres, genre.Name)
+	}
+	for _, crew := range movie.Credits.Crew {
+		if crew.Job == "Director" {
+			metadata.Directors = append(metadata.Directors, crew.Name)
+		}
+	}
+
+	return metadata, nil
+}
+
+func (provider *TMDbProvider) get(ctx context.Context, path string, query url.Values, target any) error {
+	if provider.APIKey != "" {
+		query.Set("api_key", provider.APIKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.BaseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := provider.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tmdb %s returned status %d", path, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
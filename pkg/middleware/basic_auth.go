@@ -1,10 +1,31 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"crypto/subtle"
 
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/problem"
+)
+
+var accounts = gin.Accounts{
+	"saket": "1234",
+}
+
+// BasicAuthMiddleware checks the credentials like gin.BasicAuth, a missing or wrong one is an unauthorized problem
 func BasicAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, password, ok := ctx.Request.BasicAuth()
+		if ok {
+			expected, found := accounts[user]
+			if found && subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1 {
+				ctx.Set(gin.AuthUserKey, user)
+				ctx.Next()
+				return
+			}
+		}
 
-	return gin.BasicAuth(gin.Accounts{
-		"saket": "1234",
-	})
+		ctx.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+		ctx.Error(problem.New(problem.Unauthorized, "valid credentials are required"))
+		ctx.Abort()
+	}
 }
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+)
+
+// IdempotencyKeyHeader carries the key a client sends to retry a request safely
+// and IdempotentReplayedHeader marks a response which was stored for the key
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// an idempotency key is up to 255 printable characters, a UUID is enough
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7E]{1,255}$`)
+
+// how often a duplicate of a request in progress checks if it completed
+const idempotencyPollInterval = 50 * time.Millisecond
+
+// IdempotencyStore keeps the responses of the requests sent with an Idempotency-Key
+type IdempotencyStore interface {
+	BeginIdempotentRequest(scope string, key string, fingerprint string, ttl time.Duration) (models.IdempotentResponse, bool, error)
+	CompleteIdempotentRequest(scope string, key string, response models.IdempotentResponse) error
+	ReleaseIdempotencyKey(scope string, key string) error
+}
+
+// IdempotencyMiddleware runs a POST or PATCH sent with an Idempotency-Key once, for ttl
+// the status, headers and body of the first response are stored and a retry with the key gets them back with Idempotent-Replayed: true
+// a retry while the first request runs waits up to wait for its response, then it is a conflict
+// a key is stored for the method, route and authenticated user it is sent with, the same key sent elsewhere is another key
+// within that scope the key belongs to the path and body it was first sent with, another request with it is an idempotency-key-reused problem
+// server errors are not stored, the request runs again on retry
+// requests without the header are not changed
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration, wait time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" || (ctx.Request.Method != http.MethodPost && ctx.Request.Method != http.MethodPatch) {
+			ctx.Next()
+			return
+		}
+
+		if !idempotencyKeyPattern.MatchString(key) {
+			ctx.Error(problem.New(problem.BadRequest, "Idempotency-Key must be 1 to 255 printable characters"))
+			ctx.Abort()
+			return
+		}
+
+		// the body is read for the fingerprint and given back to the handler
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.Error(problem.Wrap(problem.BadRequest, "the body can not be read", err))
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(ctx.Request, body)
+		scope := idempotencyScope(ctx)
+
+		// a duplicate of a request in progress waits for its response
+		deadline := time.Now().Add(wait)
+		stored, found, err := store.BeginIdempotentRequest(scope, key, fingerprint, ttl)
+		for errors.Is(err, repositories.ErrIdempotencyKeyInProgress) && time.Now().Before(deadline) {
+			time.Sleep(idempotencyPollInterval)
+			stored, found, err = store.BeginIdempotentRequest(scope, key, fingerprint, ttl)
+		}
+
+		switch {
+		case errors.Is(err, repositories.ErrIdempotencyKeyInProgress):
+			ctx.Header("Retry-After", "1")
+			ctx.Error(problem.New(problem.Conflict, "a request with this Idempotency-Key is in progress, retry later"))
+			ctx.Abort()
+			return
+		case errors.Is(err, repositories.ErrIdempotencyKeyReused):
+			ctx.Error(problem.New(problem.IdempotencyKeyReused, "this Idempotency-Key was sent with another request"))
+			ctx.Abort()
+			return
+		case err != nil:
+			ctx.Error(problem.Wrap(problem.Internal, "Failed to check the Idempotency-Key", err))
+			ctx.Abort()
+			return
+		case found:
+			replay(ctx, stored)
+			return
+		}
+
+		// the key is released when the handler panics or fails, a retry runs the request again
+		completed := false
+		defer func() {
+			if !completed {
+				err := store.ReleaseIdempotencyKey(scope, key)
+				if err != nil {
+					log.Printf("ERROR: releasing the idempotency key correlation_id=%s: %v", ctx.GetString(problem.CorrelationIDKey), err)
+				}
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// the problem is written here and not by the problem middleware so it is stored too
+		if err := ctx.Errors.Last(); err != nil && !ctx.Writer.Written() {
+			problem.Write(ctx, err.Err)
+		}
+
+		status := ctx.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		header := ctx.Writer.Header().Clone()
+		header.Del(problem.CorrelationIDHeader)
+
+		err = store.CompleteIdempotentRequest(scope, key, models.IdempotentResponse{
+			Status: status,
+			Header: header,
+			Body:   recorder.body.Bytes(),
+		})
+		if err != nil {
+			log.Printf("ERROR: storing the idempotent response correlation_id=%s: %v", ctx.GetString(problem.CorrelationIDKey), err)
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyScope is where a key is sent, the method, the route and the authenticated user, if any
+// two clients or two routes sending the same key do not share it
+func idempotencyScope(ctx *gin.Context) string {
+	return ctx.Request.Method + " " + ctx.FullPath() + " " + ctx.GetString(gin.AuthUserKey)
+}
+
+// requestFingerprint tells the requests apart, a key can only be retried with the same one
+func requestFingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay answers with a stored response, the correlation ID stays the one of this request
+func replay(ctx *gin.Context, stored models.IdempotentResponse) {
+	for name, values := range stored.Header {
+		ctx.Writer.Header()[name] = values
+	}
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Writer.WriteHeader(stored.Status)
+	_, _ = ctx.Writer.Write(stored.Body)
+	ctx.Abort()
+}
+
+// responseRecorder keeps a copy of the body written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
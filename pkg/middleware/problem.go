@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/problem"
+)
+
+// a correlation ID sent by the client is kept when it is short and printable
+var correlationIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// CorrelationIDMiddleware gives each request a correlation ID, the X-Request-ID sent or a new one
+// it is returned in X-Request-ID and logged with the errors of the request
+func CorrelationIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		correlationID := ctx.GetHeader(problem.CorrelationIDHeader)
+		if !correlationIDPattern.MatchString(correlationID) {
+			correlationID = newCorrelationID()
+		}
+
+		ctx.Set(problem.CorrelationIDKey, correlationID)
+		ctx.Header(problem.CorrelationIDHeader, correlationID)
+		ctx.Next()
+	}
+}
+
+func newCorrelationID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// ProblemMiddleware writes the last error a handler added with ctx.Error as a problem+json response
+// nothing is written when the handler already responded
+func ProblemMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		err := ctx.Errors.Last()
+		if err == nil || ctx.Writer.Written() {
+			return
+		}
+		problem.Write(ctx, err.Err)
+	}
+}
+
+// RecoveryMiddleware answers a panic with an internal problem, the panic is only logged
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecovery(func(ctx *gin.Context, recovered any) {
+		problem.Write(ctx, problem.Wrap(problem.Internal, "an unexpected error occurred", fmt.Errorf("panic: %v", recovered)))
+		ctx.Abort()
+	})
+}
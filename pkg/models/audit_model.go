@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actor is who makes the writes of a request, it is recorded with each of them in the audit
+// the name is the basic auth user, "anonymous" on the public routes and "system" for background work
+type Actor struct {
+	Name      string
+	RequestID string
+
+	// UndoToken groups the versions written by the request, they are undone together with it
+	UndoToken string
+}
+
+// SystemActor makes the writes which do not come from a request, like the jobs and the imports
+var SystemActor = Actor{Name: "system"}
+
+// AuditEvent is a write of an entity, before and after only hold the fields which changed
+// before is null when the entity was created and after when it was purged
+type AuditEvent struct {
+	EventID    int             `json:"event_id" example:"42"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor" example:"saket"`
+	Action     string          `json:"action" example:"update"`
+	Entity     string          `json:"entity" example:"watchlist"`
+	EntityID   int             `json:"entity_id" example:"7"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID  string          `json:"request_id" example:"5f0c2a9b6d1e4c3a8b7f6e5d4c3b2a19"`
+}
+
+// AuditQuery filters the audit events, newest first
+// since and until are RFC 3339 times, before_id pages through the events with the event_id of the last one read
+type AuditQuery struct {
+	Actor     string    `form:"actor"`
+	Action    string    `form:"action"`
+	Entity    string    `form:"entity"`
+	EntityID  int       `form:"entity_id" binding:"omitempty,min=1"`
+	RequestID string    `form:"request_id"`
+	Since     time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until     time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	BeforeID  int       `form:"before_id" binding:"omitempty,min=1"`
+	Limit     int       `form:"limit" binding:"omitempty,min=1,max=500"`
+}
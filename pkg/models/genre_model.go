@@ -0,0 +1,22 @@
+package models
+
+// Genre is a normalized genre name shared by every entry tagged with it
+type Genre struct {
+	GenreID        int    `json:"genre_id" example:"3"`
+	Name           string `json:"name" example:"Animation"`
+	WatchListCount int    `json:"watchlist_count" example:"2"`
+}
+
+// Person is someone credited on one or more entries
+type Person struct {
+	PersonID int    `json:"person_id" example:"12"`
+	Name     string `json:"name" example:"Lee Unkrich"`
+}
+
+// Credit links a person to an entry with a role
+// role is one of director, writer, actor
+type Credit struct {
+	PersonID int    `json:"person_id,omitempty" example:"12"`
+	Name     string `json:"name" example:"Lee Unkrich" binding:"required,notblank,max=200"`
+	Role     string `json:"role" example:"director" binding:"required,oneof=director writer actor"`
+}
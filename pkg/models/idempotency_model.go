@@ -0,0 +1,10 @@
+package models
+
+import "net/http"
+
+// IdempotentResponse is the response stored for an Idempotency-Key, a retry with the key gets it again
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
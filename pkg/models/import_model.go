@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ImportItem is a single title read from an external tracker export (Trakt, Simkl)
+// before it is turned into a Watchlist row
+type ImportItem struct {
+	Title       string      `json:"title"`
+	ReleaseYear int         `json:"release_year"`
+	Status      string      `json:"status"`
+	IMDbID      string      `json:"imdb_id,omitempty"`
+	TMDbID      int         `json:"tmdb_id,omitempty"`
+	Rating      int         `json:"rating,omitempty"`
+	WatchDates  []time.Time `json:"watch_dates,omitempty"` // oldest first
+}
+
+// ImportItemError records why a single item of an import was not added
+type ImportItemError struct {
+	Title   string `json:"title"`
+	Details string `json:"details"`
+}
+
+// ImportJob reports the progress of a background import, it is read from its "watchlist.import" job
+// state is one of "queued", "running", "completed", "failed" or "cancelled"
+type ImportJob struct {
+	JobID      int               `json:"job_id"`
+	Source     string            `json:"source"`
+	State      string            `json:"state"`
+	Total      int               `json:"total"`
+	Processed  int               `json:"processed"`
+	Imported   int               `json:"imported"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Errors     []ImportItemError `json:"errors"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// ImportPayload is the payload of a "watchlist.import" job
+type ImportPayload struct {
+	Source string       `json:"source"`
+	Items  []ImportItem `json:"items"`
+}
+
+// ImportProgress is the result of a "watchlist.import" job, it is saved after each item
+// so a job which is retried or requeued goes on after the last processed item
+type ImportProgress struct {
+	Processed  int               `json:"processed"`
+	Imported   int               `json:"imported"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Errors     []ImportItemError `json:"errors"`
+}
+
+// TraktImportRequest holds the JSON files of a Trakt export
+// every file is optional but at least one must be present
+type TraktImportRequest struct {
+	History   json.RawMessage `json:"history" swaggertype:"array,object"`
+	Watchlist json.RawMessage `json:"watchlist" swaggertype:"array,object"`
+	Ratings   json.RawMessage `json:"ratings" swaggertype:"array,object"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work stored in the jobs table
+// state is one of "queued", "running", "succeeded", "dead" or "cancelled"
+type Job struct {
+	JobID       int             `json:"job_id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	State       string          `json:"state"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+
+	// Result is what the job reported, like the progress of an import
+	Result json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+}
+
+// JobQuery selects the jobs of GET /admin/jobs, newest first
+type JobQuery struct {
+	State  string `form:"state" binding:"omitempty,oneof=queued running succeeded dead cancelled"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type JobEnqueueRequest struct {
+	Kind        string          `json:"kind" example:"watchlist.refresh" binding:"required"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	MaxAttempts int             `json:"max_attempts" example:"5"`
+}
+
+// WatchListRefreshRequest lists the entries to refresh, an empty list refreshes every entry
+type WatchListRefreshRequest struct {
+	WatchlistIDs []int `json:"watchlist_ids" example:"1,2,3"`
+}
+
+// WatchListRefreshPayload is the payload of a "watchlist.refresh" job
+type WatchListRefreshPayload struct {
+	WatchlistID int `json:"watchlist_id"`
+}
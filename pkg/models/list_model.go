@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Visibilities is every visibility of a list
+// private lists are only seen by the owner, unlisted lists are opened with their share token
+// and public lists are also listed on /shared/lists
+var Visibilities = []string{"private", "unlisted", "public"}
+
+// List is a named list of watchlist entries like "Horror October"
+// the entries of a smart list are the entries matching its filter, they are read page by page
+// entries are only returned when a single static list is read
+type List struct {
+	ListID      int          `json:"list_id" example:"1"`
+	Name        string       `json:"name" example:"Horror October"`
+	Description string       `json:"description" example:"One scary movie a night"`
+	Visibility  string       `json:"visibility" example:"unlisted"`
+	ShareToken  string       `json:"share_token" example:"9f86d081884c7d65"`
+	Smart       bool         `json:"smart" example:"false"`
+	Filter      *SmartFilter `json:"filter,omitempty"`
+	Pinned      bool         `json:"pinned" example:"true"`
+	EntryCount  int          `json:"entry_count" example:"31"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Entries     []ListEntry  `json:"entries,omitempty"`
+}
+
+// ListEntry is a watchlist entry at its position in a list, the first position is 1
+// added_at is when it was added to a static list, smart lists have none
+type ListEntry struct {
+	Position  int        `json:"position" example:"1"`
+	AddedAt   *time.Time `json:"added_at,omitempty"`
+	Watchlist Watchlist  `json:"watchlist"`
+}
+
+// SmartFilter is the saved query of a smart list, every field which is set must match
+// statuses, kinds and genres match any of their values, tags any or all of them depending on tag_match
+// title matches titles containing it and sort takes the values of the sort of the list endpoints
+type SmartFilter struct {
+	Statuses  []string `json:"statuses,omitempty" example:"not watched"`
+	Kinds     []string `json:"kinds,omitempty" example:"movie" binding:"omitempty,dive,oneof=movie series miniseries documentary short"`
+	Genres    []string `json:"genres,omitempty" example:"Science Fiction" binding:"omitempty,max=50,dive,required,max=100"`
+	Tags      []string `json:"tags,omitempty" example:"date night" binding:"omitempty,max=50,dive,required,max=100"`
+	TagMatch  string   `json:"tag_match,omitempty" example:"all" binding:"omitempty,oneof=any all"`
+	MinYear   int      `json:"min_year,omitempty" example:"1990" binding:"omitempty,min=1800,max=3000"`
+	MaxYear   int      `json:"max_year,omitempty" example:"1999" binding:"omitempty,min=1800,max=3000"`
+	MinRating float64  `json:"min_rating,omitempty" example:"4" binding:"omitempty,min=0.5,max=5"`
+	MaxRating float64  `json:"max_rating,omitempty" example:"5" binding:"omitempty,min=0.5,max=5"`
+	Title     string   `json:"title,omitempty" example:"star" binding:"max=200"`
+	Sort      string   `json:"sort,omitempty" example:"-rating" binding:"omitempty,oneof=rating -rating title -title release_year -release_year added_date -added_date progress_updated_at -progress_updated_at rank -rank"`
+}
+
+// Validate checks what the binding tags can not, the statuses and the ranges
+func (filter SmartFilter) Validate() error {
+	for _, status := range filter.Statuses {
+		if !IsValidStatus(status) {
+			return fmt.Errorf("status %q must be one of not watched, watching, watched, on hold, dropped", status)
+		}
+	}
+	if filter.MinYear > 0 && filter.MaxYear > 0 && filter.MinYear > filter.MaxYear {
+		return errors.New("min_year must not be after max_year")
+	}
+	if filter.MinRating > 0 && filter.MaxRating > 0 && filter.MinRating > filter.MaxRating {
+		return errors.New("min_rating must not be above max_rating")
+	}
+	return nil
+}
+
+// PageQuery selects a page of a list, page starts at 1 and page_size defaults to 20
+type PageQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// ListEntriesPage is a page of the entries of a list, total counts every entry
+type ListEntriesPage struct {
+	Entries  []ListEntry `json:"entries"`
+	Page     int         `json:"page" example:"1"`
+	PageSize int         `json:"page_size" example:"20"`
+	Total    int         `json:"total" example:"42"`
+}
+
+// ListRequest is the body used to create a list, the visibility defaults to private
+// a list created with a filter is a smart list
+type ListRequest struct {
+	Name        string       `json:"name" example:"Horror October" binding:"required,max=200"`
+	Description string       `json:"description" example:"One scary movie a night" binding:"max=2000"`
+	Visibility  string       `json:"visibility" example:"private" binding:"omitempty,oneof=private unlisted public"`
+	Filter      *SmartFilter `json:"filter"`
+	Pinned      bool         `json:"pinned" example:"false"`
+}
+
+// ListUpdateRequest changes the fields of a list which are sent, only smart lists take a filter
+type ListUpdateRequest struct {
+	Name        *string      `json:"name" example:"Horror October" binding:"omitempty,max=200"`
+	Description *string      `json:"description" example:"One scary movie a night" binding:"omitempty,max=2000"`
+	Visibility  *string      `json:"visibility" example:"public" binding:"omitempty,oneof=private unlisted public"`
+	Filter      *SmartFilter `json:"filter"`
+	Pinned      *bool        `json:"pinned" example:"true"`
+}
+
+// ListEntryRequest adds an entry to a list, at the end when no position is sent
+type ListEntryRequest struct {
+	WatchlistID int  `json:"watchlist_id" example:"7" binding:"required,min=1"`
+	Position    *int `json:"position" example:"1" binding:"omitempty,min=1"`
+}
+
+// ListOrderRequest lists every entry of a list in its new order
+type ListOrderRequest struct {
+	WatchlistIDs []int `json:"watchlist_ids" example:"7,2,5" binding:"required,min=1"`
+}
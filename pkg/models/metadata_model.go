@@ -0,0 +1,31 @@
+package models
+
+// Metadata is the canonical information a MetadataProvider returns for a title
+type Metadata struct {
+	Provider       string      `json:"provider" example:"tmdb"`
+	Title          string      `json:"title" example:"Coco"`
+	ReleaseYear    int         `json:"release_year" example:"2017"`
+	Genres         []string    `json:"genres" example:"Animation,Family"`
+	Directors      []string    `json:"directors" example:"Lee Unkrich"`
+	RuntimeMinutes int         `json:"runtime_minutes" example:"105"`
+	PosterPath     string      `json:"poster_path" example:"/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg"`
+	ExternalIDs    ExternalIDs `json:"external_ids"`
+}
+
+// ExternalIDs are the identifiers of a title on other services
+type ExternalIDs struct {
+	TMDbID     int    `json:"tmdb_id,omitempty" example:"354912" binding:"omitempty,min=1"`
+	IMDbID     string `json:"imdb_id,omitempty" example:"tt2380307" binding:"max=20"`
+	WikidataID string `json:"wikidata_id,omitempty" example:"Q27188178" binding:"max=20"`
+}
+
+// WatchListEnrichRequest is the body of POST /watchlist/add?enrich=true
+// only the title is required, the rest is filled from the metadata provider
+// and any field sent by the client wins over the provider value
+type WatchListEnrichRequest struct {
+	Title       string `json:"title" example:"Coco" binding:"required,notblank,max=300"`
+	ReleaseYear int    `json:"release_year" example:"2017" binding:"omitempty,releaseyear"`
+	Genre       string `json:"genre" binding:"max=500"`
+	Director    string `json:"director" binding:"max=500"`
+	Status      string `json:"status" example:"not watched" binding:"omitempty,watchstatus"`
+}
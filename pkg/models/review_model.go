@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Review is the personal rating and optional review text of a watchlist entry
+// an entry has at most one review, previous versions are kept in History
+type Review struct {
+	ReviewID    int              `json:"review_id" example:"1"`
+	WatchlistID int              `json:"watchlist_id" example:"7"`
+	Rating      float64          `json:"rating" example:"4.5"`
+	Text        string           `json:"text" example:"The final act is a masterpiece"`
+	Spoiler     bool             `json:"spoiler" example:"false"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	History     []ReviewRevision `json:"history"`
+}
+
+// ReviewRevision is a review as it was before an edit
+type ReviewRevision struct {
+	RevisionID int       `json:"revision_id" example:"1"`
+	Rating     float64   `json:"rating" example:"4"`
+	Text       string    `json:"text" example:"Great ending"`
+	Spoiler    bool      `json:"spoiler" example:"false"`
+	EditedAt   time.Time `json:"edited_at"`
+}
+
+// ReviewRequest is the body used to create or update a review
+// ratings are half stars between 0.5 and 5
+type ReviewRequest struct {
+	Rating  float64 `json:"rating" example:"4.5" binding:"required,min=0.5,max=5"`
+	Text    string  `json:"text" example:"The final act is a masterpiece"`
+	Spoiler bool    `json:"spoiler" example:"false"`
+}
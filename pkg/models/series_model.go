@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Kinds is every kind of watchlist entry, movies and shorts are single titles
+var Kinds = []string{"movie", "series", "miniseries", "documentary", "short"}
+
+// IsEpisodic reports whether entries of a kind are split into seasons and episodes
+func IsEpisodic(kind string) bool {
+	return kind == "series" || kind == "miniseries" || kind == "documentary"
+}
+
+// Season is a season of a series entry, season 0 holds the specials
+type Season struct {
+	SeasonID     int       `json:"season_id" example:"1"`
+	WatchlistID  int       `json:"watchlist_id" example:"7"`
+	SeasonNumber int       `json:"season_number" example:"1"`
+	Title        string    `json:"title" example:"Season 1"`
+	Episodes     []Episode `json:"episodes"`
+}
+
+// Episode is an episode of a season, watched_at is null until it is watched
+type Episode struct {
+	EpisodeID     int        `json:"episode_id" example:"1"`
+	SeasonNumber  int        `json:"season_number" example:"1"`
+	EpisodeNumber int        `json:"episode_number" example:"1"`
+	Title         string     `json:"title" example:"Pilot"`
+	AirDate       *time.Time `json:"air_date" example:"2008-01-20T00:00:00Z"`
+	WatchedAt     *time.Time `json:"watched_at" example:"2025-06-20T21:00:00Z"`
+}
+
+// SeriesProgress is derived from the watched episodes of a series entry
+// next_episode is the first episode not watched yet, null once every episode is watched
+type SeriesProgress struct {
+	EpisodeCount int      `json:"episode_count" example:"62"`
+	WatchedCount int      `json:"watched_count" example:"7"`
+	Percent      float64  `json:"percent" example:"11.3"`
+	NextEpisode  *Episode `json:"next_episode"`
+}
+
+// SeasonRequest creates a season or adds episodes to it
+// episode_count creates the episodes 1 to n, episodes sets their titles and air dates
+// episodes already stored keep their watched state
+type SeasonRequest struct {
+	Title        string           `json:"title" example:"Season 1"`
+	EpisodeCount int              `json:"episode_count" example:"7" binding:"omitempty,min=1,max=1000"`
+	Episodes     []EpisodeRequest `json:"episodes" binding:"dive"`
+}
+
+type EpisodeRequest struct {
+	EpisodeNumber int        `json:"episode_number" example:"1" binding:"required,min=1"`
+	Title         string     `json:"title" example:"Pilot"`
+	AirDate       *time.Time `json:"air_date" example:"2008-01-20T00:00:00Z"`
+}
+
+// EpisodeRangeRequest selects the episodes from and to (inclusive) of a season
+// from defaults to the first episode and to to the last one, at defaults to now
+type EpisodeRangeRequest struct {
+	From int        `json:"from" example:"1" binding:"omitempty,min=1"`
+	To   int        `json:"to" example:"4" binding:"omitempty,min=1"`
+	At   *time.Time `json:"at" example:"2025-06-20T21:00:00Z"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// StatusTransitions is the lifecycle of a watchlist entry, the key is the current status
+// and the value the statuses it can move to, "watched" only leads back to "watching" for a rewatch
+var StatusTransitions = map[string][]string{
+	"not watched": {"watching", "watched", "on hold", "dropped"},
+	"watching":    {"watched", "on hold", "dropped", "not watched"},
+	"on hold":     {"watching", "watched", "dropped"},
+	"dropped":     {"watching", "watched", "not watched"},
+	"watched":     {"watching"},
+}
+
+// IsValidStatus reports whether status is part of the lifecycle
+func IsValidStatus(status string) bool {
+	_, ok := StatusTransitions[status]
+	return ok
+}
+
+// CanTransition reports whether an entry can move from one status to another
+// staying on the same status is always allowed
+func CanTransition(from string, to string) bool {
+	if from == to {
+		return IsValidStatus(to)
+	}
+	for _, allowed := range StatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchListTransitionRequest is the body of POST /watchlist/{id}/transition
+// at defaults to now
+type WatchListTransitionRequest struct {
+	Status string     `json:"status" example:"watching" binding:"required,watchstatus"`
+	At     *time.Time `json:"at" example:"2025-06-20T21:00:00Z"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Tag is a personal label like "date night" shared by any number of entries
+type Tag struct {
+	TagID          int    `json:"tag_id" example:"1"`
+	Name           string `json:"name" example:"date night"`
+	WatchListCount int    `json:"watchlist_count" example:"4"`
+}
+
+// TagRequest is the body used to create or rename a tag
+type TagRequest struct {
+	Name string `json:"name" example:"date night" binding:"required,max=100"`
+}
+
+// TagMergeRequest lists the tags merged into the tag of the path, they are deleted afterwards
+type TagMergeRequest struct {
+	TagIDs []int `json:"tag_ids" example:"2,3" binding:"required,min=1"`
+}
+
+// BulkTagRequest adds or removes tags on many entries at once, missing tags are created when adding
+type BulkTagRequest struct {
+	WatchlistIDs []int    `json:"watchlist_ids" example:"1,2,7" binding:"required,min=1,max=1000"`
+	Tags         []string `json:"tags" example:"date night,with kids" binding:"required,min=1,dive,required,max=100"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// WatchListVersion is the content of an entry at one of its versions
+// action and actor are empty for a state from before the versions were kept
+type WatchListVersion struct {
+	WatchlistID int       `json:"watchlist_id" example:"7"`
+	Version     int       `json:"version" example:"3"`
+	Action      string    `json:"action,omitempty" example:"update"`
+	Actor       string    `json:"actor,omitempty" example:"anonymous"`
+	CreatedAt   time.Time `json:"created_at"`
+	Entry       Watchlist `json:"entry"`
+}
+
+// WatchListVersionDiff holds the fields which differ between two versions of an entry
+type WatchListVersionDiff struct {
+	WatchlistID int            `json:"watchlist_id" example:"7"`
+	From        int            `json:"from" example:"2"`
+	To          int            `json:"to" example:"5"`
+	Before      map[string]any `json:"before" swaggertype:"object"`
+	After       map[string]any `json:"after" swaggertype:"object"`
+}
+
+// WatchListDiffQuery is the query of GET /watchlist/{id}/versions/diff
+type WatchListDiffQuery struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+// WatchListRevertQuery is the query of POST /watchlist/{id}/revert
+type WatchListRevertQuery struct {
+	Version int `form:"version" binding:"required,min=1"`
+}
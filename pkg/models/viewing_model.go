@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Viewing is one dated viewing of a watchlist entry
+// every viewing after the first one of an entry is a rewatch
+type Viewing struct {
+	ViewingID   int       `json:"viewing_id" example:"1"`
+	WatchlistID int       `json:"watchlist_id" example:"7"`
+	Title       string    `json:"title" example:"Coco"`
+	WatchedOn   time.Time `json:"watched_on" example:"2025-06-20T21:00:00Z"`
+	Rating      *float64  `json:"rating" example:"4.5"`
+	Location    string    `json:"location" example:"Home"`
+	Platform    string    `json:"platform" example:"Disney+"`
+	Notes       string    `json:"notes" example:"Watched with the family"`
+	Rewatch     bool      `json:"rewatch" example:"false"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ViewingRequest is the body used to log a viewing
+// watched_on defaults to now, the rating is optional and in half stars between 0.5 and 5
+type ViewingRequest struct {
+	WatchedOn *time.Time `json:"watched_on" example:"2025-06-20T21:00:00Z"`
+	Rating    *float64   `json:"rating" example:"4.5" binding:"omitempty,min=0.5,max=5"`
+	Location  string     `json:"location" example:"Home"`
+	Platform  string     `json:"platform" example:"Disney+"`
+	Notes     string     `json:"notes" example:"Watched with the family"`
+}
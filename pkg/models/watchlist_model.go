@@ -1,20 +1,80 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Watchlist represents a single watchlist entry for a movie
+// genre and director are the legacy comma-separated forms of genres and the director credits
+// either form is accepted on write, responses carry both
 type Watchlist struct {
-	WatchlistID int       `json:"watchlist_id"`
-	Title       string    `json:"title" binding:"required"`
-	ReleaseYear int       `json:"release_year" binding:"required"`
-	Genre       string    `json:"genre" binding:"required"`
-	Director    string    `json:"director" binding:"required"`
-	Status      string    `json:"status" binding:"required"`
-	AddedDate   time.Time `json:"added_date" binding:"required"`
+	WatchlistID int         `json:"watchlist_id"`
+	Title       string      `json:"title" binding:"required,notblank,max=300"`
+	ReleaseYear int         `json:"release_year" binding:"required,releaseyear"`
+	Genre       string      `json:"genre" binding:"required_without=Genres,max=500"`
+	Director    string      `json:"director" binding:"required_without=Credits,max=500"`
+	Status      string      `json:"status" binding:"required,watchstatus"`
+	AddedDate   time.Time   `json:"added_date"`                                                               // defaults to today
+	Kind        string      `json:"kind" binding:"omitempty,oneof=movie series miniseries documentary short"` // defaults to movie
+	ExternalIDs ExternalIDs `json:"external_ids"`
+	Genres      []string    `json:"genres" binding:"max=50,dive,notblank,max=100"`
+	Credits     []Credit    `json:"credits" binding:"max=200,dive"`
+	Tags        []string    `json:"tags" binding:"max=50,dive,notblank,max=100"`
+	Notes       string      `json:"notes" binding:"max=20000"` // markdown
+
+	// timeline of the status lifecycle, they are read-only
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+
+	// runtime is in minutes, 0 when unknown
+	// poster_path is the poster on the image server of the metadata provider, empty when unknown
+	// position_seconds is where playback stopped, it is set through PUT /watchlist/{id}/progress
+	Runtime           int        `json:"runtime" binding:"omitempty,min=1"`
+	PosterPath        string     `json:"poster_path" binding:"max=500"`
+	PositionSeconds   int        `json:"position_seconds"`
+	ProgressUpdatedAt *time.Time `json:"progress_updated_at"`
+
+	// rank is the key of the manual order, it is set through POST /watchlist/{id}/move
+	Rank string `json:"rank"`
+
+	// version goes up by one on every write of the entry but a move, it is the ETag of the v2 routes and it is read-only
+	Version int `json:"version"`
+
+	// set while the entry is in the trash, it is purged TRASH_RETENTION after
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// aggregates of the reviews and viewings, they are read-only
+	AverageRating *float64 `json:"average_rating"`
+	RatingCount   int      `json:"rating_count"`
+	ViewingCount  int      `json:"viewing_count"`
+	RewatchCount  int      `json:"rewatch_count"`
+
+	// only set on series, miniseries and documentaries, it is read-only
+	Progress *SeriesProgress `json:"progress,omitempty"`
+}
+
+// WatchListQuery holds the optional sorting and filtering of the list endpoints
+// sort is a column name, prefixed with - for descending order
+type WatchListQuery struct {
+	Sort      string  `form:"sort" binding:"omitempty,oneof=rating -rating title -title release_year -release_year added_date -added_date progress_updated_at -progress_updated_at rank -rank"`
+	MinRating float64 `form:"min_rating" binding:"omitempty,min=0.5,max=5"`
+	MaxRating float64 `form:"max_rating" binding:"omitempty,min=0.5,max=5"`
+
+	// tags is comma-separated, entries with any of them match unless tag_match is all
+	Tags     string `form:"tags"`
+	TagMatch string `form:"tag_match" binding:"omitempty,oneof=any all"`
+
+	// q is a query like genre:action year:>2000 status:watched, see the query package
+	Q string `form:"q" binding:"max=1000"`
 }
 
 type WatchListDeleteRequest struct {
 	WatchlistID int `json:"watchlist_id" binding:"required"`
+
+	// when set, the entry is only deleted at this version, it comes from If-Match
+	Version int `json:"-"`
 }
 
 // type WatchListUpdateRequest struct {
@@ -27,31 +87,132 @@ type WatchListDeleteRequest struct {
 
 type WatchListUpdateRequest struct {
 	WatchlistID int        `json:"watchlist_id" binding:"required"`
-	Title       string     `json:"title" binding:"required"`
-	ReleaseYear int        `json:"release_year" binding:"required"`
-	Genre       string     `json:"genre" binding:"required"`
-	Director    string     `json:"director" binding:"required"`
-	Status      string     `json:"status" binding:"required"`
-	AddedDate   *time.Time `json:"added_date"`
+	Title       string     `json:"title" binding:"required,notblank,max=300"`
+	ReleaseYear int        `json:"release_year" binding:"required,releaseyear"`
+	Genre       string     `json:"genre" binding:"required_without=Genres,max=500"`
+	Director    string     `json:"director" binding:"required_without=Credits,max=500"`
+	Status      string     `json:"status" binding:"required,watchstatus"`
+	AddedDate   *time.Time `json:"added_date"` // nil keeps the stored added_date
+
+	// empty keeps the stored kind and poster and 0 the stored runtime
+	Kind       string `json:"kind,omitempty" binding:"omitempty,oneof=movie series miniseries documentary short"`
+	Runtime    int    `json:"runtime,omitempty" binding:"omitempty,min=1"`
+	PosterPath string `json:"poster_path,omitempty" binding:"max=500"`
+
+	// nil keeps the stored IDs, otherwise the IDs sent are replaced
+	ExternalIDs *ExternalIDs `json:"external_ids,omitempty"`
+
+	// genres replace the genre string when sent
+	// credits replace every credit, otherwise director only replaces the director credits
+	Genres  []string `json:"genres,omitempty" binding:"max=50,dive,notblank,max=100"`
+	Credits []Credit `json:"credits,omitempty" binding:"max=200,dive"`
+
+	// nil keeps the stored tags and notes, tags sent replace every tag
+	Tags  []string `json:"tags,omitempty" binding:"max=50,dive,notblank,max=100"`
+	Notes *string  `json:"notes,omitempty" binding:"omitempty,max=20000"`
+
+	// when set, the update only applies to this version of the entry, it comes from If-Match
+	Version int `json:"-"`
+}
+
+// WatchListDocument is the document a PATCH /watchlist/{id} applies its patch to, it holds every field which can change
+// a patched document with any other field is rejected
+// removing runtime or poster_path keeps the stored value and removing an external ID keeps the stored ID
+type WatchListDocument struct {
+	Title       string      `json:"title" binding:"required,notblank,max=300"`
+	ReleaseYear int         `json:"release_year" binding:"required,releaseyear"`
+	Genre       string      `json:"genre" binding:"required_without=Genres,max=500"`
+	Director    string      `json:"director" binding:"required_without=Credits,max=500"`
+	Status      string      `json:"status" binding:"required,watchstatus"`
+	AddedDate   time.Time   `json:"added_date" binding:"required"`
+	Kind        string      `json:"kind" binding:"required,oneof=movie series miniseries documentary short"`
+	Runtime     int         `json:"runtime" binding:"omitempty,min=1"`
+	PosterPath  string      `json:"poster_path" binding:"max=500"`
+	ExternalIDs ExternalIDs `json:"external_ids"`
+	Genres      []string    `json:"genres" binding:"max=50,dive,notblank,max=100"`
+	Credits     []Credit    `json:"credits" binding:"max=200,dive"`
+	Tags        []string    `json:"tags" binding:"max=50,dive,notblank,max=100"`
+	Notes       string      `json:"notes" binding:"max=20000"`
+}
+
+// ProgressRequest is the body of PUT /watchlist/{id}/progress
+// a position before the stored one is ignored unless reset is set, runtime is in minutes
+type ProgressRequest struct {
+	PositionSeconds *int `json:"position_seconds" example:"3600" binding:"required,min=0"`
+	Runtime         int  `json:"runtime" example:"105" binding:"omitempty,min=1"`
+	Reset           bool `json:"reset" example:"false"`
+}
+
+// WatchListMoveRequest places an entry right before or right after another one
+// with both, the entry goes between them and they must be next to each other in the order
+type WatchListMoveRequest struct {
+	Before *int `json:"before" example:"3"`
+	After  *int `json:"after" example:"5"`
+}
+
+// WatchListBatchOperation is an operation of POST /api/v2/watchlist/batch
+// item is the body of a create (models.Watchlist) or of an update (models.WatchListUpdateRequest), a delete has none
+// version is like If-Match, when set the update or delete only applies to this version of the entry
+type WatchListBatchOperation struct {
+	Op          string          `json:"op" example:"update" binding:"required,oneof=create update delete"`
+	WatchlistID int             `json:"watchlist_id,omitempty" example:"7" binding:"required_unless=Op create,omitempty,min=1"`
+	Version     int             `json:"version,omitempty" example:"3" binding:"omitempty,min=1"`
+	Item        json.RawMessage `json:"item,omitempty" swaggertype:"object" binding:"required_unless=Op delete"`
+}
+
+// WatchListBatchWrite is an operation of a batch read for the repository, Create, Update or Delete is set by Op
+// Err is why the operation could not be read, it fails without running
+type WatchListBatchWrite struct {
+	Op     string
+	Create Watchlist
+	Update WatchListUpdateRequest
+	Delete WatchListDeleteRequest
+	Err    error
+}
+
+// WatchListBatchResult is the outcome of an operation of a batch, in the order they were sent
+// item is the created or updated entry and error the problem of a failed operation
+type WatchListBatchResult struct {
+	Index  int        `json:"index" example:"0"`
+	Op     string     `json:"op" example:"update"`
+	Status int        `json:"status" example:"200"`
+	Item   *Watchlist `json:"item,omitempty"`
+	Error  any        `json:"error,omitempty" swaggertype:"object"`
 }
 
 // Example for swagger :)
 
 type WatchListAddRequestExample struct {
-	Title       string     `json:"title" example:"Coco" binding:"required"`
-	ReleaseYear int        `json:"release_year" example:"2017" binding:"required"`
-	Genre       string     `json:"genre" example:"Animation" binding:"required"`
-	Director    string     `json:"director" example:"Lee Unkrich" binding:"required"`
-	Status      string     `json:"status" example:"not watched" binding:"required"`
-	AddedDate   *time.Time `json:"added_date" example:"2025-06-20T00:00:00Z"`
+	Title       string      `json:"title" example:"Coco" binding:"required"`
+	ReleaseYear int         `json:"release_year" example:"2017" binding:"required"`
+	Genre       string      `json:"genre" example:"Animation, Family"`
+	Director    string      `json:"director" example:"Lee Unkrich"`
+	Status      string      `json:"status" example:"not watched" binding:"required"`
+	AddedDate   *time.Time  `json:"added_date" example:"2025-06-20T00:00:00Z"`
+	Kind        string      `json:"kind" example:"movie"`
+	Runtime     int         `json:"runtime" example:"105"`
+	PosterPath  string      `json:"poster_path" example:"/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg"`
+	ExternalIDs ExternalIDs `json:"external_ids"`
+	Genres      []string    `json:"genres" example:"Animation,Family"`
+	Credits     []Credit    `json:"credits"`
+	Tags        []string    `json:"tags" example:"date night,with kids"`
+	Notes       string      `json:"notes" example:"Recommended by **Priya**"`
 }
 
 type WatchListUpdateRequestExample struct {
-	WatchlistID int        `json:"watchlist_id" binding:"required" example:"7"`
-	Title       string     `json:"title" binding:"required" example:"Coco"`
-	ReleaseYear int        `json:"release_year" binding:"required" example:"2017"`
-	Genre       string     `json:"genre" binding:"required" example:"Animation"`
-	Director    string     `json:"director" binding:"required" example:"Lee Unkrich"`
-	Status      string     `json:"status" binding:"required" example:"watching"`
-	AddedDate   *time.Time `json:"added_date,omitempty" example:"2025-06-20T00:00:00Z"`
+	WatchlistID int          `json:"watchlist_id" binding:"required" example:"7"`
+	Title       string       `json:"title" binding:"required" example:"Coco"`
+	ReleaseYear int          `json:"release_year" binding:"required" example:"2017"`
+	Genre       string       `json:"genre" example:"Animation"`
+	Director    string       `json:"director" example:"Lee Unkrich"`
+	Status      string       `json:"status" binding:"required" example:"watching"`
+	AddedDate   *time.Time   `json:"added_date,omitempty" example:"2025-06-20T00:00:00Z"`
+	Kind        string       `json:"kind,omitempty" example:"movie"`
+	Runtime     int          `json:"runtime,omitempty" example:"105"`
+	PosterPath  string       `json:"poster_path,omitempty" example:"/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg"`
+	ExternalIDs *ExternalIDs `json:"external_ids,omitempty"`
+	Genres      []string     `json:"genres,omitempty" example:"Animation,Family"`
+	Credits     []Credit     `json:"credits,omitempty"`
+	Tags        []string     `json:"tags,omitempty" example:"date night,with kids"`
+	Notes       *string      `json:"notes,omitempty" example:"Recommended by **Priya**"`
 }
@@ -0,0 +1,348 @@
+// Package patch applies the two JSON patch formats to a JSON document
+//
+// a merge patch (RFC 7396, application/merge-patch+json) is an object holding the members to change,
+// null removes a member, https://www.rfc-editor.org/rfc/rfc7396
+//
+// a JSON patch (RFC 6902, application/json-patch+json) is an array of add, remove, replace, move, copy
+// and test operations on JSON pointers (RFC 6901), it is applied as a whole or not at all
+// https://www.rfc-editor.org/rfc/rfc6902
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	ErrInvalidPatch = errors.New("invalid patch")
+	ErrPathNotFound = errors.New("path not found")
+	ErrTestFailed   = errors.New("test failed")
+)
+
+// Merge applies the merge patch to the document
+func Merge(document []byte, patch []byte) ([]byte, error) {
+	var target, changes any
+	err := json.Unmarshal(document, &target)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(patch, &changes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+func merge(target any, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		// anything but an object replaces the target
+		return patch
+	}
+
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = merge(object[name], value)
+	}
+	return object
+}
+
+// Operation is a single operation of a JSON patch
+// from is only read by move and copy, value by add, replace and test
+type Operation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// Apply applies the JSON patch to the document
+// the error of a failed operation tells its index, an operation on a missing path is ErrPathNotFound
+// and a failed test ErrTestFailed
+func Apply(document []byte, patch []byte) ([]byte, error) {
+	var target any
+	err := json.Unmarshal(document, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []Operation
+	err = json.Unmarshal(patch, &operations)
+	if err != nil {
+		return nil, fmt.Errorf("%w: a JSON patch is an array of operations: %v", ErrInvalidPatch, err)
+	}
+
+	for i, operation := range operations {
+		target, err = apply(target, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func apply(target any, operation Operation) (any, error) {
+	if operation.Path == nil {
+		return nil, fmt.Errorf("%w: %s needs a path", ErrInvalidPatch, operation.Op)
+	}
+	path, err := parsePointer(*operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%w: %s needs a value", ErrInvalidPatch, operation.Op)
+		}
+		var value any
+		err = json.Unmarshal(*operation.Value, &value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+
+		switch operation.Op {
+		case "add":
+			return add(target, path, value)
+		case "replace":
+			target, _, err = remove(target, path)
+			if err != nil {
+				return nil, err
+			}
+			return add(target, path, value)
+		default:
+			current, err := get(target, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: %s is not %s", ErrTestFailed, *operation.Path, *operation.Value)
+			}
+			return target, nil
+		}
+	case "remove":
+		target, _, err = remove(target, path)
+		return target, err
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, fmt.Errorf("%w: %s needs a from", ErrInvalidPatch, operation.Op)
+		}
+		from, err := parsePointer(*operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any
+		if operation.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: %s can not be moved into itself", ErrInvalidPatch, *operation.From)
+			}
+			target, value, err = remove(target, from)
+		} else {
+			value, err = get(target, from)
+			value = deepCopy(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, operation.Op)
+	}
+}
+
+// parsePointer splits a JSON pointer into its unescaped tokens, "" is the whole document
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: the path %q does not start with /", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		// ~1 is unescaped first, so ~01 reads as ~1 and not as /
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// index reads an array index, "-" is the end of the array which only add accepts
+func index(token string, length int, end bool) (int, error) {
+	if token == "-" && end {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) || token[0] == '+' {
+		return 0, fmt.Errorf("%w: %q is not an array index", ErrPathNotFound, token)
+	}
+
+	limit := length - 1
+	if end {
+		limit = length
+	}
+	if i > limit {
+		return 0, fmt.Errorf("%w: index %d is out of the array", ErrPathNotFound, i)
+	}
+	return i, nil
+}
+
+func get(target any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := target.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+			}
+			target = value
+		case []any:
+			i, err := index(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			target = node[i]
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+		}
+	}
+	return target, nil
+}
+
+// add sets the value at the path, the parent must exist
+// a member is added or replaced, a value is inserted in an array
+func add(target any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(target, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+		return target, nil
+	case []any:
+		i, err := index(token, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return set(target, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+	}
+}
+
+// remove deletes the value at the path and returns it
+func remove(target any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, target, nil
+	}
+
+	parent, err := get(target, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+		}
+		delete(node, token)
+		return target, value, nil
+	case []any:
+		i, err := index(token, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		target, err = set(target, path[:len(path)-1], node)
+		return target, value, err
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrPathNotFound, token)
+	}
+}
+
+// set replaces the value at an existing path, arrays change length so their parent holds the new slice
+func set(target any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(target, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+	case []any:
+		i, err := index(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = value
+	}
+	return target, nil
+}
+
+func deepCopy(value any) any {
+	switch node := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(node))
+		for name, member := range node {
+			object[name] = deepCopy(member)
+		}
+		return object
+	case []any:
+		array := make([]any, len(node))
+		for i, item := range node {
+			array[i] = deepCopy(item)
+		}
+		return array
+	default:
+		return value
+	}
+}
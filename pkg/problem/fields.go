@@ -0,0 +1,63 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/saketV8/cine-dots/pkg/validation"
+)
+
+// FieldError is a failed rule of a field of the request
+// field is the JSON path of the field like credits[0].name
+type FieldError struct {
+	Field   string `json:"field" example:"release_year"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"release_year is required"`
+}
+
+// fieldErrors reads the fields at fault of a binding error, nil when err is not about fields
+func fieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fields = append(fields, FieldError{
+				Field:   validation.Field(fieldError),
+				Rule:    fieldError.Tag(),
+				Message: validation.Message(fieldError),
+			})
+		}
+		return fields
+	case errors.As(err, &typeError) && typeError.Field != "":
+		return []FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("%s must be a %s, not a %s", typeError.Field, jsonKind(typeError.Type), typeError.Value),
+		}}
+	default:
+		return nil
+	}
+}
+
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,236 @@
+// Package problem holds the errors of the API and writes them as RFC 7807 problem details
+// https://www.rfc-editor.org/rfc/rfc7807
+//
+// handlers add an *Error to the gin context with ctx.Error and return, the problem middleware writes it
+// the detail of an *Error is shown to the client, its cause is only logged with the correlation ID
+package problem
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of a problem response
+const ContentType = "application/problem+json"
+
+// TypeBaseURI is the prefix of the type URIs, each type is described in docs/problems.md
+const TypeBaseURI = "https://github.com/saketV8/cine-dots/blob/main/docs/problems.md#"
+
+// CorrelationIDKey is the key of the correlation ID in the gin context
+// and CorrelationIDHeader the header which carries it on the request and the response
+const (
+	CorrelationIDKey    = "correlation_id"
+	CorrelationIDHeader = "X-Request-ID"
+)
+
+// Type is a kind of problem, its URI never changes
+type Type string
+
+const (
+	BadRequest           Type = "bad-request"
+	Validation           Type = "validation"
+	Unauthorized         Type = "unauthorized"
+	NotFound             Type = "not-found"
+	Conflict             Type = "conflict"
+	PreconditionFailed   Type = "precondition-failed"
+	PreconditionRequired Type = "precondition-required"
+	IdempotencyKeyReused Type = "idempotency-key-reused"
+	UnsupportedMediaType Type = "unsupported-media-type"
+	Upstream             Type = "upstream"
+	Internal             Type = "internal"
+)
+
+var types = map[Type]struct {
+	status int
+	title  string
+}{
+	BadRequest:           {http.StatusBadRequest, "Bad request"},
+	Validation:           {http.StatusBadRequest, "Validation failed"},
+	Unauthorized:         {http.StatusUnauthorized, "Unauthorized"},
+	NotFound:             {http.StatusNotFound, "Not found"},
+	Conflict:             {http.StatusConflict, "Conflict"},
+	PreconditionFailed:   {http.StatusPreconditionFailed, "Precondition failed"},
+	PreconditionRequired: {http.StatusPreconditionRequired, "Precondition required"},
+	IdempotencyKeyReused: {http.StatusUnprocessableEntity, "Idempotency key reused"},
+	UnsupportedMediaType: {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	Upstream:             {http.StatusBadGateway, "Upstream service failed"},
+	Internal:             {http.StatusInternalServerError, "Internal server error"},
+}
+
+// URI is the type member of the problems of this type
+func (t Type) URI() string {
+	return TypeBaseURI + string(t)
+}
+
+// Status is the default status of the problems of this type
+func (t Type) Status() int {
+	return types[t].status
+}
+
+// Title is the summary of the type, it is the same for every problem of the type
+func (t Type) Title() string {
+	return types[t].title
+}
+
+// Error is an error of the API
+// Status overrides the status of the type, v2 answers validation problems with 422
+type Error struct {
+	Type       Type
+	Status     int
+	Detail     string
+	Fields     []FieldError
+	Extensions map[string]any
+
+	// Err is the cause, it is logged and never sent
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Type, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// With adds an extension member to the problem, like the allowed statuses of a refused transition
+func (e *Error) With(name string, value any) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]any{}
+	}
+	e.Extensions[name] = value
+	return e
+}
+
+// WithStatus answers the problem with another status than the one of its type
+func (e *Error) WithStatus(status int) *Error {
+	e.Status = status
+	return e
+}
+
+// New is a problem of the type, the detail is shown to the client
+func New(t Type, detail string) *Error {
+	return &Error{Type: t, Detail: detail}
+}
+
+// Wrap is a problem of the type caused by err, err is only logged
+func Wrap(t Type, detail string, err error) *Error {
+	return &Error{Type: t, Detail: detail, Err: err}
+}
+
+// Invalid is a validation problem of the request, err is about what the client sent so it is shown:
+// the failed rules of a struct become per-field errors and any other message is appended to the detail
+// a body which is not JSON is a bad request
+func Invalid(detail string, err error) *Error {
+	problem := &Error{Type: Validation, Detail: detail}
+	if err == nil {
+		return problem
+	}
+
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		problem.Type = BadRequest
+	}
+
+	problem.Fields = fieldErrors(err)
+	if problem.Fields == nil {
+		problem.Detail = detail + ": " + err.Error()
+	}
+	return problem
+}
+
+// From turns any error into a problem, a missing row is not found and anything unknown internal
+func From(err error) *Error {
+	var problem *Error
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.Is(err, sql.ErrNoRows):
+		return Wrap(NotFound, "the resource does not exist", err)
+	default:
+		return Wrap(Internal, "an unexpected error occurred", err)
+	}
+}
+
+// Problem is the body of an error response
+type Problem struct {
+	Type          string       `json:"type" example:"https://github.com/saketV8/cine-dots/blob/main/docs/problems.md#validation"`
+	Title         string       `json:"title" example:"Validation failed"`
+	Status        int          `json:"status" example:"400"`
+	Detail        string       `json:"detail,omitempty" example:"Invalid WatchList Data"`
+	Instance      string       `json:"instance" example:"/api/v1/watchlist/add"`
+	CorrelationID string       `json:"correlation_id" example:"5f0c2a9b6d1e4c3a8b7f6e5d4c3b2a19"`
+	Errors        []FieldError `json:"errors,omitempty"`
+
+	// extension members of the type, like allowed on a refused status transition
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON writes the extension members next to the standard ones
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := map[string]any{}
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	err = json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// Details is the problem of err as it is sent, the cause of the problem is logged with the correlation ID
+// a batch answers the problem of each failed operation in its results
+func Details(ctx *gin.Context, err error) Problem {
+	problem := From(err)
+
+	status := problem.Status
+	if status == 0 {
+		status = problem.Type.Status()
+	}
+	correlationID := ctx.GetString(CorrelationIDKey)
+
+	if problem.Err != nil || status >= http.StatusInternalServerError {
+		log.Printf("ERROR: %s %s %d correlation_id=%s: %v", ctx.Request.Method, ctx.Request.URL.Path, status, correlationID, problem)
+	}
+
+	return Problem{
+		Type:          problem.Type.URI(),
+		Title:         problem.Type.Title(),
+		Status:        status,
+		Detail:        problem.Detail,
+		Instance:      ctx.Request.URL.Path,
+		CorrelationID: correlationID,
+		Errors:        problem.Fields,
+		Extensions:    problem.Extensions,
+	}
+}
+
+// Write responds with the problem of err, the cause of the problem is logged with the correlation ID
+func Write(ctx *gin.Context, err error) {
+	details := Details(ctx, err)
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		log.Printf("ERROR: writing the problem correlation_id=%s: %v", details.CorrelationID, err)
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	ctx.Data(details.Status, ContentType, data)
+}
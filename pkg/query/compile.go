@@ -0,0 +1,205 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldKind tells how a field compares with the values of a query
+type FieldKind int
+
+const (
+	// TextField matches the values containing the value case-insensitively, = matches the whole value
+	TextField FieldKind = iota
+	// EnumField matches one of Values exactly
+	EnumField
+	// NumberField takes comparisons and ranges
+	NumberField
+	// MatchField matches when Condition holds, every ? of Condition is bound to the value
+	MatchField
+)
+
+// Field maps a field of the language to SQL, Column is a column or any SQL expression
+type Field struct {
+	Kind      FieldKind
+	Column    string
+	Values    []string
+	Condition string
+}
+
+// Schema is what a query can search, Text lists the columns free text is searched in
+type Schema struct {
+	Fields map[string]Field
+	Text   []string
+}
+
+// EscapeLike escapes the wildcards of a LIKE pattern, the escape character is \
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Compile turns a node into a SQL condition and its arguments
+// only the columns of the schema end up in the SQL, the values of the query are always arguments
+func Compile(node Node, schema Schema) (string, []any, error) {
+	compiler := &compiler{schema: schema}
+	condition, err := compiler.compile(node)
+	if err != nil {
+		return "", nil, err
+	}
+	return condition, compiler.args, nil
+}
+
+type compiler struct {
+	schema Schema
+	args   []any
+}
+
+func (c *compiler) compile(node Node) (string, error) {
+	switch node := node.(type) {
+	case And:
+		return c.binary(node.Left, "AND", node.Right)
+	case Or:
+		return c.binary(node.Left, "OR", node.Right)
+	case Not:
+		operand, err := c.compile(node.Operand)
+		if err != nil {
+			return "", err
+		}
+		// a NULL counts as not matching, so -rating:>4 also matches the entries without a rating
+		return "NOT IFNULL(" + operand + ", 0)", nil
+	case Term:
+		return c.term(node)
+	case Range:
+		return c.rangeTerm(node)
+	case Text:
+		return c.text(node)
+	}
+	return "", fmt.Errorf("query: unknown node %T", node)
+}
+
+func (c *compiler) binary(left Node, operator string, right Node) (string, error) {
+	leftCondition, err := c.compile(left)
+	if err != nil {
+		return "", err
+	}
+	rightCondition, err := c.compile(right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + leftCondition + " " + operator + " " + rightCondition + ")", nil
+}
+
+func (c *compiler) field(name string, column int) (Field, error) {
+	field, ok := c.schema.Fields[name]
+	if !ok {
+		names := make([]string, 0, len(c.schema.Fields))
+		for name := range c.schema.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return field, &Error{Column: column, Message: fmt.Sprintf("unknown field %q, the fields are %s", name, strings.Join(names, ", "))}
+	}
+	return field, nil
+}
+
+func (c *compiler) term(term Term) (string, error) {
+	field, err := c.field(term.Field, term.Column)
+	if err != nil {
+		return "", err
+	}
+
+	if field.Kind != NumberField && term.Op != OpMatch && term.Op != OpEqual {
+		return "", &Error{Column: term.ValueColumn - len(term.Op), Message: fmt.Sprintf("%s can not be compared with %s, only numbers can", term.Field, term.Op)}
+	}
+
+	switch field.Kind {
+	case TextField:
+		if term.Op == OpEqual {
+			c.args = append(c.args, term.Value)
+			return field.Column + " = ? COLLATE NOCASE", nil
+		}
+		c.args = append(c.args, "%"+EscapeLike(term.Value)+"%")
+		return field.Column + ` LIKE ? ESCAPE '\'`, nil
+
+	case EnumField:
+		for _, value := range field.Values {
+			if strings.EqualFold(value, term.Value) {
+				c.args = append(c.args, value)
+				return field.Column + " = ?", nil
+			}
+		}
+		return "", &Error{Column: term.ValueColumn, Message: fmt.Sprintf("%s must be one of %s, quote the values with a space", term.Field, strings.Join(field.Values, ", "))}
+
+	case NumberField:
+		number, err := c.number(term.Field, term.Value, term.ValueColumn)
+		if err != nil {
+			return "", err
+		}
+		operator := string(term.Op)
+		if term.Op == OpMatch {
+			operator = "="
+		}
+		c.args = append(c.args, number)
+		return field.Column + " " + operator + " ?", nil
+	}
+
+	for range strings.Count(field.Condition, "?") {
+		c.args = append(c.args, term.Value)
+	}
+	return field.Condition, nil
+}
+
+func (c *compiler) rangeTerm(term Range) (string, error) {
+	field, err := c.field(term.Field, term.Column)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind != NumberField {
+		return "", &Error{Column: term.ValueColumn, Message: fmt.Sprintf("%s does not take a range, only numbers do", term.Field)}
+	}
+
+	conditions := []string{}
+	if term.From != "" {
+		from, err := c.number(term.Field, term.From, term.ValueColumn)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, field.Column+" >= ?")
+		c.args = append(c.args, from)
+	}
+	if term.To != "" {
+		to, err := c.number(term.Field, term.To, term.ValueColumn+len([]rune(term.From))+2)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, field.Column+" <= ?")
+		c.args = append(c.args, to)
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", nil
+}
+
+func (c *compiler) number(field string, value string, column int) (float64, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, &Error{Column: column, Message: fmt.Sprintf("%s takes a number, %q is not one", field, value)}
+	}
+	return number, nil
+}
+
+func (c *compiler) text(text Text) (string, error) {
+	if len(c.schema.Text) == 0 {
+		return "", &Error{Column: text.Column, Message: "free text is not supported, use field:value"}
+	}
+
+	conditions := make([]string, 0, len(c.schema.Text))
+	for _, column := range c.schema.Text {
+		conditions = append(conditions, column+` LIKE ? ESCAPE '\'`)
+		c.args = append(c.args, "%"+EscapeLike(text.Value)+"%")
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", nil
+}
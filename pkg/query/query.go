@@ -0,0 +1,465 @@
+// Package query parses the search language of the list endpoints into an AST
+// a query is terms joined by AND (the default), OR and NOT, grouped with parentheses:
+//
+//	genre:action year:>2000 status:watched
+//	(director:nolan OR director:villeneuve) -status:dropped "space opera"
+//
+// a term is field:value, field:>value (also >=, <, <= and =), field:from..to or free text
+// values with spaces or special characters are quoted, a quote or backslash inside is escaped with a backslash
+// the AST is compiled to parameterized SQL by Compile, values never end up in the SQL
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxDepth is how deep parentheses and NOT can be nested
+const MaxDepth = 32
+
+// Error is a parse or compile error, Column is the 1-based column of the offending character
+type Error struct {
+	Column  int
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", err.Column, err.Message)
+}
+
+// Op is the comparison of a term, OpMatch is the plain field:value
+type Op string
+
+const (
+	OpMatch        Op = ":"
+	OpEqual        Op = "="
+	OpLess         Op = "<"
+	OpLessEqual    Op = "<="
+	OpGreater      Op = ">"
+	OpGreaterEqual Op = ">="
+)
+
+// Node is a node of the AST, String returns the query it was parsed from in a canonical form
+type Node interface {
+	String() string
+}
+
+// And matches when both sides match
+type And struct {
+	Left  Node
+	Right Node
+}
+
+// Or matches when either side matches
+type Or struct {
+	Left  Node
+	Right Node
+}
+
+// Not matches when its operand does not
+type Not struct {
+	Operand Node
+}
+
+// Term compares a field with a value, Column is where the field starts and ValueColumn where the value starts
+type Term struct {
+	Field       string
+	Op          Op
+	Value       string
+	Column      int
+	ValueColumn int
+}
+
+// Range matches a field between From and To included, one of them can be empty for an open range
+type Range struct {
+	Field       string
+	From        string
+	To          string
+	Column      int
+	ValueColumn int
+}
+
+// Text is free text, it is searched in the text fields
+type Text struct {
+	Value  string
+	Column int
+}
+
+func (node And) String() string {
+	return group(node.Left, false) + " AND " + group(node.Right, true)
+}
+
+func (node Or) String() string {
+	return node.Left.String() + " OR " + group(node.Right, false)
+}
+
+func (node Not) String() string {
+	return "NOT " + group(node.Operand, true)
+}
+
+func (node Term) String() string {
+	op := string(node.Op)
+	if node.Op != OpMatch {
+		op = ":" + op
+	}
+	return node.Field + op + quote(node.Value)
+}
+
+func (node Range) String() string {
+	return node.Field + ":" + node.From + ".." + node.To
+}
+
+func (node Text) String() string {
+	return quote(node.Value)
+}
+
+// group puts an OR, and an AND too when and is set, in parentheses so it parses back into the same tree
+// AND binds tighter than OR and both group to the left
+func group(node Node, and bool) string {
+	switch node.(type) {
+	case Or:
+		return "(" + node.String() + ")"
+	case And:
+		if and {
+			return "(" + node.String() + ")"
+		}
+	}
+	return node.String()
+}
+
+// quote returns value as it is when it reads back as the same word, otherwise quoted
+func quote(value string) string {
+	plain := value != "" && !isKeyword(value) && !strings.Contains(value, "..") && !strings.ContainsAny(value[:1], "-<>=")
+	for _, char := range value {
+		if !isWordRune(char) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, char := range value {
+		if char == '"' || char == '\\' {
+			quoted.WriteByte('\\')
+		}
+		quoted.WriteRune(char)
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+func isKeyword(word string) bool {
+	return word == "AND" || word == "OR" || word == "NOT"
+}
+
+// words stop at spaces, parentheses, quotes and colons
+func isWordRune(char rune) bool {
+	return !unicode.IsSpace(char) && char != '(' && char != ')' && char != '"' && char != ':' && char != '\\'
+}
+
+// Lexer
+// =====================================================================================
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenWord
+	tokenString
+	tokenColon
+	tokenOp
+	tokenMinus
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	column int
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenEnd:
+		return "end of query"
+	case tokenString:
+		return "quoted text"
+	case tokenWord, tokenOp:
+		return fmt.Sprintf("%q", tok.value)
+	case tokenColon:
+		return `":"`
+	case tokenMinus:
+		return `"-"`
+	case tokenOpen:
+		return `"("`
+	}
+	return `")"`
+}
+
+// lex splits the query into tokens, the columns count characters and not bytes
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := []token{}
+	// a value follows a colon right away, an operator or a minus sign there belongs to the value
+	afterColon := false
+
+	for i := 0; i < len(runes); {
+		char := runes[i]
+		column := i + 1
+
+		switch {
+		case unicode.IsSpace(char):
+			afterColon = false
+			i++
+			continue
+
+		case char == ':':
+			tokens = append(tokens, token{kind: tokenColon, value: ":", column: column})
+			i++
+			afterColon = true
+			if i < len(runes) && strings.ContainsRune("<>=", runes[i]) {
+				op := string(runes[i])
+				if runes[i] != '=' && i+1 < len(runes) && runes[i+1] == '=' {
+					op += "="
+				}
+				tokens = append(tokens, token{kind: tokenOp, value: op, column: i + 1})
+				i += len(op)
+			}
+			continue
+
+		case char == '(':
+			tokens = append(tokens, token{kind: tokenOpen, value: "(", column: column})
+			i++
+
+		case char == ')':
+			tokens = append(tokens, token{kind: tokenClose, value: ")", column: column})
+			i++
+
+		case char == '"':
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+					if i == len(runes) {
+						break
+					}
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, &Error{Column: column, Message: "unterminated quoted text, a quote is missing at the end"}
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value.String(), column: column})
+			i++
+
+		case char == '\\':
+			return nil, &Error{Column: column, Message: "a backslash can only be used inside quoted text"}
+
+		case char == '-' && !afterColon && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{kind: tokenMinus, value: "-", column: column})
+			i++
+
+		default:
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[start:i]), column: column})
+		}
+		afterColon = false
+	}
+
+	return append(tokens, token{kind: tokenEnd, column: len(runes) + 1}), nil
+}
+
+// Parser
+// =====================================================================================
+
+type parser struct {
+	tokens []token
+	next   int
+	depth  int
+}
+
+// Parse parses a query, an empty query returns a nil node
+// the error is an *Error pointing at the column where the query stops making sense
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEnd {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEnd {
+		if tok.kind == tokenClose {
+			return nil, &Error{Column: tok.column, Message: `")" has no matching "("`}
+		}
+		return nil, &Error{Column: tok.column, Message: "unexpected " + describe(tok)}
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEnd {
+		p.next++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && tok.value == keyword
+}
+
+// or := and (OR and)*
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("OR") {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// and := unary ([AND] unary)*
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind == tokenEnd || tok.kind == tokenClose || p.isKeyword("OR") {
+			return left, nil
+		}
+		if p.isKeyword("AND") {
+			p.advance()
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+// unary := (NOT | -) unary | primary
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind != tokenMinus && !p.isKeyword("NOT") {
+		return p.parsePrimary()
+	}
+
+	tok := p.advance()
+	if p.depth++; p.depth > MaxDepth {
+		return nil, &Error{Column: tok.column, Message: fmt.Sprintf("the query is nested more than %d levels deep", MaxDepth)}
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	p.depth--
+	return Not{Operand: operand}, nil
+}
+
+// primary := ( or ) | field : [op] value | text
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.advance()
+
+	switch tok.kind {
+	case tokenOpen:
+		if p.depth++; p.depth > MaxDepth {
+			return nil, &Error{Column: tok.column, Message: fmt.Sprintf("the query is nested more than %d levels deep", MaxDepth)}
+		}
+		if p.peek().kind == tokenClose {
+			return nil, &Error{Column: p.peek().column, Message: "empty parentheses, a search term is missing"}
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenClose {
+			return nil, &Error{Column: p.peek().column, Message: fmt.Sprintf(`expected ")" to close the "(" at column %d, found %s`, tok.column, describe(p.peek()))}
+		}
+		p.advance()
+		p.depth--
+		return node, nil
+
+	case tokenString:
+		return Text{Value: tok.value, Column: tok.column}, nil
+
+	case tokenWord:
+		if isKeyword(tok.value) {
+			return nil, &Error{Column: tok.column, Message: fmt.Sprintf("expected a search term before %s, quote it to search for the word", tok.value)}
+		}
+		if p.peek().kind != tokenColon {
+			return Text{Value: tok.value, Column: tok.column}, nil
+		}
+		return p.parseTerm(tok)
+
+	case tokenEnd:
+		return nil, &Error{Column: tok.column, Message: "unexpected end of query, a search term is missing"}
+
+	case tokenColon:
+		return nil, &Error{Column: tok.column, Message: `a field name is missing before ":"`}
+	}
+	return nil, &Error{Column: tok.column, Message: "unexpected " + describe(tok)}
+}
+
+func (p *parser) parseTerm(field token) (Node, error) {
+	colon := p.advance()
+	name := strings.ToLower(field.value)
+
+	op, written := OpMatch, ""
+	if p.peek().kind == tokenOp {
+		op = Op(p.advance().value)
+		written = string(op)
+	}
+
+	// the value follows the colon without a space, "status: watched" is a mistake
+	value := p.peek()
+	column := colon.column + 1 + len(written)
+	if (value.kind != tokenWord && value.kind != tokenString) || value.column != column {
+		return nil, &Error{Column: column, Message: fmt.Sprintf("a value is missing after %s:%s, it can not be separated by a space", name, written)}
+	}
+	p.advance()
+
+	if op == OpMatch && value.kind == tokenWord && strings.Contains(value.value, "..") {
+		from, to, _ := strings.Cut(value.value, "..")
+		if from == "" && to == "" {
+			return nil, &Error{Column: value.column, Message: "a range needs a start, an end or both like 1990..1999"}
+		}
+		return Range{Field: name, From: from, To: to, Column: field.column, ValueColumn: value.column}, nil
+	}
+	return Term{Field: name, Op: op, Value: value.value, Column: field.column, ValueColumn: value.column}, nil
+}
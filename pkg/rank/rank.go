@@ -0,0 +1,253 @@
+// Package rank generates fractional rank keys, strings which sort in the order of the entries
+// a key can always be generated between two others, so moving an entry only updates its own key
+//
+// keys are an integer part followed by a fraction, the first character gives the length of the integer
+// ("a0" is zero, "a1" one, "b00" sixty-two...), appending at the end increments the integer
+// so keys only grow by a character every few thousand entries
+// https://observablehq.com/@dgreensp/implementing-fractional-indexing
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// Digits are the base 62 digits of a key, in ascending byte order so keys compare like plain strings
+const Digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxLength is the length after which the keys should be rebalanced
+const MaxLength = 24
+
+var (
+	ErrInvalidKey = errors.New("invalid rank key")
+	ErrOrder      = errors.New("rank keys are not in order")
+	ErrExhausted  = errors.New("no rank key left at the end of the range")
+)
+
+const integerZero = "a0"
+
+// the key space starts at the smallest integer, nothing can go before it
+var smallestInteger = "A" + strings.Repeat("0", 26)
+
+// Between returns a key which sorts after a and before b
+// an empty a is the start of the list and an empty b its end, so Between("", "") is the first key
+func Between(a string, b string) (string, error) {
+	if a != "" {
+		err := Validate(a)
+		if err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		err := Validate(b)
+		if err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrOrder
+	}
+
+	if a == "" {
+		if b == "" {
+			return integerZero, nil
+		}
+
+		ib, _ := integerPart(b)
+		fb := b[len(ib):]
+		if ib == smallestInteger {
+			return ib + midpoint("", fb), nil
+		}
+		if ib < b {
+			return ib, nil
+		}
+		key := decrementInteger(ib)
+		if key == "" {
+			return "", ErrExhausted
+		}
+		return key, nil
+	}
+
+	ia, _ := integerPart(a)
+	fa := a[len(ia):]
+
+	if b == "" {
+		key := incrementInteger(ia)
+		if key == "" {
+			return ia + midpoint(fa, ""), nil
+		}
+		return key, nil
+	}
+
+	ib, _ := integerPart(b)
+	fb := b[len(ib):]
+	if ia == ib {
+		return ia + midpoint(fa, fb), nil
+	}
+
+	key := incrementInteger(ia)
+	if key == "" {
+		return "", ErrExhausted
+	}
+	if key < b {
+		return key, nil
+	}
+	return ia + midpoint(fa, ""), nil
+}
+
+// Validate reports whether key is a key generated by Between
+func Validate(key string) error {
+	if key == "" || key == smallestInteger {
+		return ErrInvalidKey
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(Digits, key[i]) < 0 {
+			return ErrInvalidKey
+		}
+	}
+
+	integer, err := integerPart(key)
+	if err != nil {
+		return err
+	}
+	// a trailing zero would leave no room for a key right before it
+	if len(key) > len(integer) && key[len(key)-1] == Digits[0] {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// midpoint returns a fraction between the fractions a and b, an empty b is the end of the range
+func midpoint(a string, b string) string {
+	if b != "" {
+		// the common prefix is kept, a is padded with zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(Digits, a[0])
+	}
+	digitB := len(Digits)
+	if b != "" {
+		digitB = strings.IndexByte(Digits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(Digits[(digitA+digitB+1)/2])
+	}
+
+	// the first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(Digits[digitA]) + midpoint(rest, "")
+}
+
+func digitAt(fraction string, i int) byte {
+	if i < len(fraction) {
+		return fraction[i]
+	}
+	return Digits[0]
+}
+
+func integerLength(head byte) (int, error) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, nil
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, nil
+	}
+	return 0, ErrInvalidKey
+}
+
+func integerPart(key string) (string, error) {
+	length, err := integerLength(key[0])
+	if err != nil {
+		return "", err
+	}
+	if length > len(key) {
+		return "", ErrInvalidKey
+	}
+	return key[:length], nil
+}
+
+// incrementInteger returns the next integer, or "" after the largest one
+func incrementInteger(integer string) string {
+	head, digits := integer[0], []byte(integer[1:])
+
+	carry := true
+	for i := len(digits) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(Digits, digits[i]) + 1
+		if d == len(Digits) {
+			digits[i] = Digits[0]
+		} else {
+			digits[i] = Digits[d]
+			carry = false
+		}
+	}
+	if !carry {
+		return string(head) + string(digits)
+	}
+
+	switch head {
+	case 'Z':
+		return integerZero
+	case 'z':
+		return ""
+	}
+	head++
+	if head > 'a' {
+		digits = append(digits, Digits[0])
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits)
+}
+
+// decrementInteger returns the previous integer, or "" before the smallest one
+func decrementInteger(integer string) string {
+	head, digits := integer[0], []byte(integer[1:])
+
+	borrow := true
+	for i := len(digits) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(Digits, digits[i]) - 1
+		if d == -1 {
+			digits[i] = Digits[len(Digits)-1]
+		} else {
+			digits[i] = Digits[d]
+			borrow = false
+		}
+	}
+	if !borrow {
+		return string(head) + string(digits)
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + string(Digits[len(Digits)-1])
+	case 'A':
+		return ""
+	}
+	head--
+	if head < 'Z' {
+		digits = append(digits, Digits[len(Digits)-1])
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits)
+}
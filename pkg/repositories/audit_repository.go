@@ -0,0 +1,315 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+type AuditModelInterface interface {
+	GetAuditEvents(query models.AuditQuery) ([]models.AuditEvent, error)
+	GetWatchListHistory(watchlist_id string) ([]models.AuditEvent, error)
+}
+
+type AuditModel struct {
+	DB *sql.DB
+}
+
+// DefaultAuditLimit is the number of events returned when AuditQuery.Limit is not set
+const DefaultAuditLimit = 100
+
+// the entity of the watchlist entries in the audit
+const watchListEntity = "watchlist"
+
+const auditColumns = `event_id, occurred_at, actor, action, entity, entity_id, before, after, request_id`
+
+func scanAuditEvent(scanner interface{ Scan(dest ...any) error }) (models.AuditEvent, error) {
+	event := models.AuditEvent{}
+	var before, after sql.NullString
+	err := scanner.Scan(&event.EventID, &event.OccurredAt, &event.Actor, &event.Action, &event.Entity, &event.EntityID, &before, &after, &event.RequestID)
+	if err != nil {
+		return event, err
+	}
+
+	event.Before = json.RawMessage("null")
+	if before.Valid {
+		event.Before = json.RawMessage(before.String)
+	}
+	event.After = json.RawMessage("null")
+	if after.Valid {
+		event.After = json.RawMessage(after.String)
+	}
+	return event, nil
+}
+
+func (auditModel *AuditModel) queryAuditEvents(statement string, args ...any) ([]models.AuditEvent, error) {
+	rows, err := auditModel.DB.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetAuditEvents lists the events matching the query, newest first
+func (auditModel *AuditModel) GetAuditEvents(query models.AuditQuery) ([]models.AuditEvent, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+
+	statement := `SELECT ` + auditColumns + ` FROM audit_events
+	WHERE (?1 = '' OR actor = ?1) AND (?2 = '' OR action = ?2) AND (?3 = '' OR entity = ?3) AND (?4 = 0 OR entity_id = ?4)
+	AND (?5 = '' OR request_id = ?5) AND (?6 OR occurred_at >= ?7) AND (?8 OR occurred_at < ?9) AND (?10 = 0 OR event_id < ?10)
+	ORDER BY event_id DESC LIMIT ?11;`
+
+	return auditModel.queryAuditEvents(statement,
+		query.Actor, query.Action, query.Entity, query.EntityID, query.RequestID,
+		query.Since.IsZero(), query.Since.UTC(), query.Until.IsZero(), query.Until.UTC(),
+		query.BeforeID, limit)
+}
+
+// GetWatchListHistory lists the events of an entry in the order they happened, the entry may be in the trash or purged
+// sql.ErrNoRows is returned when the entry has no event and does not exist
+func (auditModel *AuditModel) GetWatchListHistory(watchlist_id string) ([]models.AuditEvent, error) {
+	events, err := auditModel.queryAuditEvents(`SELECT `+auditColumns+` FROM audit_events WHERE entity = ? AND entity_id = ? ORDER BY event_id;`,
+		watchListEntity, watchlist_id)
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+
+	// entries from before the audit have no event yet
+	var watchlistID int
+	err = auditModel.DB.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ?;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Recording
+// =====================================================================================
+
+// fields of an entry which are not compared, the version changes with every write
+// the aggregates of the reviews and viewings and the progress of a series are compared, their writes are writes of the entry
+var unauditedFields = []string{"watchlist_id", "version"}
+
+// readWatchList reads an entry with its genres, credits, tags and progress in the transaction writing it, trashed or not
+func readWatchList(tx *sql.Tx, watchlistID int) (models.Watchlist, error) {
+	watchList, err := scanWatchList(tx.QueryRow(`SELECT `+watchListColumns+` FROM Watchlist WHERE watchlist_id = ?;`, watchlistID))
+	if err != nil {
+		return watchList, err
+	}
+
+	watchLists := []models.Watchlist{watchList}
+	err = loadRelations(tx, watchLists)
+	if err != nil {
+		return watchList, err
+	}
+	return watchLists[0], nil
+}
+
+// auditedFields returns the fields of an entry by their JSON name, nil for a missing entry
+func auditedFields(watchList *models.Watchlist) (map[string]any, error) {
+	if watchList == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(watchList)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range unauditedFields {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+// diffFields keeps the fields which differ, a field missing on one side is null there
+// a missing side stays nil so a create has no before and a purge no after
+func diffFields(before map[string]any, after map[string]any) (map[string]any, map[string]any) {
+	changedBefore := map[string]any{}
+	changedAfter := map[string]any{}
+	for field, value := range before {
+		if !reflect.DeepEqual(value, after[field]) {
+			changedBefore[field] = value
+			changedAfter[field] = after[field]
+		}
+	}
+	for field, value := range after {
+		if _, found := before[field]; !found && value != nil {
+			changedBefore[field] = nil
+			changedAfter[field] = value
+		}
+	}
+
+	if before == nil {
+		changedBefore = nil
+	}
+	if after == nil {
+		changedAfter = nil
+	}
+	return changedBefore, changedAfter
+}
+
+// watchListAudit holds the state of an entry before a write, record compares it with the state after
+type watchListAudit struct {
+	actor       models.Actor
+	action      string
+	watchlistID int
+	before      *models.Watchlist
+}
+
+// beginAudit reads the entry before action writes it, an entry which does not exist yet has no state before
+func beginAudit(tx *sql.Tx, actor models.Actor, action string, watchlistID int) (*watchListAudit, error) {
+	audit := &watchListAudit{actor: actor, action: action, watchlistID: watchlistID}
+
+	before, err := readWatchList(tx, watchlistID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return audit, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	audit.before = &before
+	return audit, nil
+}
+
+// beginAudits is beginAudit for each of the entries, for the writes of several entries at once
+func beginAudits(tx *sql.Tx, actor models.Actor, action string, watchlistIDs []int) ([]*watchListAudit, error) {
+	audits := []*watchListAudit{}
+	for _, watchlistID := range watchlistIDs {
+		audit, err := beginAudit(tx, actor, action, watchlistID)
+		if err != nil {
+			return nil, err
+		}
+		audits = append(audits, audit)
+	}
+	return audits, nil
+}
+
+// diff reads the entry again and returns it with the fields which changed since beginAudit
+// after is nil once the entry is gone, both sides are nil for an entry which never existed
+func (audit *watchListAudit) diff(tx *sql.Tx) (*models.Watchlist, map[string]any, map[string]any, error) {
+	var after *models.Watchlist
+	watchList, err := readWatchList(tx, audit.watchlistID)
+	if err == nil {
+		after = &watchList
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil, err
+	}
+
+	beforeFields, err := auditedFields(audit.before)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	afterFields, err := auditedFields(after)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	changedBefore, changedAfter := diffFields(beforeFields, afterFields)
+	return after, changedBefore, changedAfter, nil
+}
+
+// record reads the entry again and adds the event and the new version in the same transaction,
+// a write which changed nothing is not audited but the version it wrote is kept, so the versions have no gap
+func (audit *watchListAudit) record(tx *sql.Tx) error {
+	after, changedBefore, changedAfter, err := audit.diff(tx)
+	if err != nil {
+		return err
+	}
+
+	if changedBefore != nil && changedAfter != nil && len(changedBefore) == 0 {
+		if after.Version == audit.before.Version {
+			return nil
+		}
+		return saveVersions(tx, audit.actor, audit.action, audit.before, after)
+	}
+
+	err = saveVersions(tx, audit.actor, audit.action, audit.before, after)
+	if err != nil {
+		return err
+	}
+	return insertAuditEvent(tx, audit.actor, audit.action, watchListEntity, audit.watchlistID, changedBefore, changedAfter)
+}
+
+// touch records the writes of what an entry shows, like its reviews, viewings, episodes or tags
+// the version of the entry only goes up when what it shows changed, a missing entry is skipped
+func (audit *watchListAudit) touch(tx *sql.Tx) error {
+	_, changedBefore, changedAfter, err := audit.diff(tx)
+	if err != nil {
+		return err
+	}
+	if changedBefore == nil || changedAfter == nil || len(changedBefore) == 0 {
+		return nil
+	}
+
+	err = touchWatchList(tx, audit.watchlistID)
+	if err != nil {
+		return err
+	}
+	return audit.record(tx)
+}
+
+// touchAll is touch for each of the entries of beginAudits
+func touchAll(tx *sql.Tx, audits []*watchListAudit) error {
+	for _, audit := range audits {
+		err := audit.touch(tx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertAuditEvent appends an event, nil before or after is stored as NULL
+func insertAuditEvent(tx *sql.Tx, actor models.Actor, action string, entity string, entityID int, before map[string]any, after map[string]any) error {
+	if actor.Name == "" {
+		actor = models.SystemActor
+	}
+
+	beforeJSON, err := nullableJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := nullableJSON(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO audit_events (occurred_at, actor, action, entity, entity_id, before, after, request_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+		time.Now().UTC(), actor.Name, action, entity, entityID, beforeJSON, afterJSON, actor.RequestID)
+	return err
+}
+
+func nullableJSON(fields map[string]any) (sql.NullString, error) {
+	if fields == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+type GenreModelInterface interface {
+	GetGenres() ([]models.Genre, error)
+	GetWatchListByGenre(genre_id string) ([]models.Watchlist, error)
+}
+
+type GenreModel struct {
+	DB *sql.DB
+}
+
+// GetGenres lists every genre with the number of entries tagged with it
+func (genreModel *GenreModel) GetGenres() ([]models.Genre, error) {
+	statement := `SELECT genres.genre_id, genres.name, COUNT(watchlist_genres.watchlist_id) FROM genres
+	LEFT JOIN watchlist_genres ON watchlist_genres.genre_id = genres.genre_id
+	AND watchlist_genres.watchlist_id IN (SELECT watchlist_id FROM Watchlist WHERE deleted_at IS NULL)
+	GROUP BY genres.genre_id ORDER BY genres.name;`
+
+	rows, err := genreModel.DB.Query(statement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []models.Genre{}
+	for rows.Next() {
+		genre := models.Genre{}
+		err := rows.Scan(&genre.GenreID, &genre.Name, &genre.WatchListCount)
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+// GetWatchListByGenre lists the entries tagged with a genre
+// sql.ErrNoRows is returned when the genre does not exist
+func (genreModel *GenreModel) GetWatchListByGenre(genre_id string) ([]models.Watchlist, error) {
+	var exists int
+	err := genreModel.DB.QueryRow(`SELECT 1 FROM genres WHERE genre_id = ?;`, genre_id).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := `SELECT ` + watchListColumns + ` FROM Watchlist
+	JOIN watchlist_genres ON watchlist_genres.watchlist_id = Watchlist.watchlist_id
+	WHERE watchlist_genres.genre_id = ? AND Watchlist.deleted_at IS NULL ORDER BY Watchlist.watchlist_id;`
+
+	watchListModel := &WatchListModel{DB: genreModel.DB}
+	return watchListModel.queryWatchLists(statement, genre_id)
+}
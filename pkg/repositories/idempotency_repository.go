@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+var (
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was used for another request")
+)
+
+type IdempotencyModel struct {
+	DB *sql.DB
+}
+
+// BeginIdempotentRequest claims key in scope for the request with this fingerprint until ttl runs out
+// scope is where the key was sent, like the method, route and user, a key is only looked up in its scope
+// it returns the stored response and true when the request already completed with the key,
+// ErrIdempotencyKeyInProgress while it is running and ErrIdempotencyKeyReused when the key belongs to another request
+// an expired key is claimed again
+func (idempotencyModel *IdempotencyModel) BeginIdempotentRequest(scope string, key string, fingerprint string, ttl time.Duration) (models.IdempotentResponse, bool, error) {
+	tx, err := idempotencyModel.DB.Begin()
+	if err != nil {
+		return models.IdempotentResponse{}, false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	_, err = tx.Exec(`DELETE FROM idempotency_keys WHERE scope = ? AND idempotency_key = ? AND expires_at <= ?;`, scope, key, now)
+	if err != nil {
+		return models.IdempotentResponse{}, false, err
+	}
+
+	statement := `INSERT INTO idempotency_keys (scope, idempotency_key, fingerprint, created_at, expires_at) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(scope, idempotency_key) DO NOTHING;`
+
+	result, err := tx.Exec(statement, scope, key, fingerprint, now, now.Add(ttl))
+	if err != nil {
+		return models.IdempotentResponse{}, false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return models.IdempotentResponse{}, false, err
+	}
+	if claimed == 1 {
+		return models.IdempotentResponse{}, false, tx.Commit()
+	}
+
+	var storedFingerprint, state, headers string
+	response := models.IdempotentResponse{}
+
+	statement = `SELECT fingerprint, state, status, headers, body FROM idempotency_keys WHERE scope = ? AND idempotency_key = ?;`
+	err = tx.QueryRow(statement, scope, key).Scan(&storedFingerprint, &state, &response.Status, &headers, &response.Body)
+	if err != nil {
+		return models.IdempotentResponse{}, false, err
+	}
+
+	if storedFingerprint != fingerprint {
+		return models.IdempotentResponse{}, false, ErrIdempotencyKeyReused
+	}
+	if state != "completed" {
+		return models.IdempotentResponse{}, false, ErrIdempotencyKeyInProgress
+	}
+
+	err = json.Unmarshal([]byte(headers), &response.Header)
+	if err != nil {
+		return models.IdempotentResponse{}, false, err
+	}
+	return response, true, nil
+}
+
+// CompleteIdempotentRequest stores the response of the request which claimed key in scope
+func (idempotencyModel *IdempotencyModel) CompleteIdempotentRequest(scope string, key string, response models.IdempotentResponse) error {
+	statement := `UPDATE idempotency_keys SET state = 'completed', status = ?, headers = ?, body = ? WHERE scope = ? AND idempotency_key = ?;`
+
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	_, err = idempotencyModel.DB.Exec(statement, response.Status, string(headers), response.Body, scope, key)
+	return err
+}
+
+// ReleaseIdempotencyKey forgets a key of scope which has no response to store, a retry with it runs again
+func (idempotencyModel *IdempotencyModel) ReleaseIdempotencyKey(scope string, key string) error {
+	_, err := idempotencyModel.DB.Exec(`DELETE FROM idempotency_keys WHERE scope = ? AND idempotency_key = ? AND state = 'in progress';`, scope, key)
+	return err
+}
+
+// PurgeIdempotencyKeys deletes the expired keys and returns how many were deleted
+func (idempotencyModel *IdempotencyModel) PurgeIdempotencyKeys() (int, error) {
+	result, err := idempotencyModel.DB.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?;`, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
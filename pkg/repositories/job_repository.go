@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+type JobModelInterface interface {
+	GetJobs(query models.JobQuery) ([]models.Job, error)
+	GetJobById(job_id string) (models.Job, error)
+
+	EnqueueJob(kind string, payload json.RawMessage, maxAttempts int) (models.Job, error)
+	CancelJob(job_id string) (int, error)
+}
+
+type JobModel struct {
+	DB *sql.DB
+}
+
+// DefaultJobLimit is the number of jobs returned when JobQuery.Limit is not set
+const DefaultJobLimit = 100
+
+const jobColumns = `job_id, kind, payload, state, attempts, max_attempts, last_error, run_at, created_at, updated_at, finished_at, result`
+
+func scanJob(scanner interface{ Scan(dest ...any) error }) (models.Job, error) {
+	job := models.Job{}
+	var payload string
+	var result sql.NullString
+
+	err := scanner.Scan(
+		&job.JobID,
+		&job.Kind,
+		&payload,
+		&job.State,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.RunAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.FinishedAt,
+		&result,
+	)
+	if err != nil {
+		return job, err
+	}
+
+	job.Payload = json.RawMessage(payload)
+	if result.Valid {
+		job.Result = json.RawMessage(result.String)
+	}
+	return job, nil
+}
+
+// GetJobs lists a page of the jobs, newest first, optionally filtered by state
+func (jobModel *JobModel) GetJobs(query models.JobQuery) ([]models.Job, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultJobLimit
+	}
+
+	statement := `SELECT ` + jobColumns + ` FROM jobs WHERE (? = '' OR state = ?) ORDER BY job_id DESC LIMIT ? OFFSET ?;`
+
+	rows, err := jobModel.DB.Query(statement, query.State, query.State, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (jobModel *JobModel) GetJobById(job_id string) (models.Job, error) {
+	statement := `SELECT ` + jobColumns + ` FROM jobs WHERE job_id = ?;`
+
+	return scanJob(jobModel.DB.QueryRow(statement, job_id))
+}
+
+// EnqueueJob stores a new "queued" job which can run right away
+func (jobModel *JobModel) EnqueueJob(kind string, payload json.RawMessage, maxAttempts int) (models.Job, error) {
+	statement := `INSERT INTO jobs (kind, payload, state, max_attempts, run_at, created_at, updated_at) VALUES (?, ?, 'queued', ?, ?, ?, ?) RETURNING ` + jobColumns + `;`
+
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+
+	now := time.Now().UTC()
+	return scanJob(jobModel.DB.QueryRow(statement, kind, string(payload), maxAttempts, now, now, now))
+}
+
+// CancelJob cancels a job which has not finished yet
+// it returns 0 when the job does not exist or already finished
+func (jobModel *JobModel) CancelJob(job_id string) (int, error) {
+	statement := `UPDATE jobs SET state = 'cancelled', updated_at = ?, finished_at = ? WHERE job_id = ? AND state IN ('queued', 'running');`
+
+	now := time.Now().UTC()
+	result, err := jobModel.DB.Exec(statement, now, now, job_id)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
+
+// Worker methods (used by pkg/jobs)
+// =====================================================================================
+
+// ClaimNextJob marks the oldest due job as "running" and returns it
+// the UPDATE ... RETURNING runs as one statement, so two workers never claim the same job
+func (jobModel *JobModel) ClaimNextJob(now time.Time) (models.Job, bool, error) {
+	statement := `UPDATE jobs SET state = 'running', attempts = attempts + 1, updated_at = ?
+	WHERE job_id = (SELECT job_id FROM jobs WHERE state = 'queued' AND run_at <= ? ORDER BY run_at, job_id LIMIT 1)
+	RETURNING ` + jobColumns + `;`
+
+	now = now.UTC()
+	job, err := scanJob(jobModel.DB.QueryRow(statement, now, now))
+	if err == sql.ErrNoRows {
+		return job, false, nil
+	}
+	if err != nil {
+		return job, false, err
+	}
+
+	return job, true, nil
+}
+
+// CompleteJob, RetryJob and FailJob only touch "running" jobs
+// so a job cancelled while it was running stays cancelled
+
+func (jobModel *JobModel) CompleteJob(jobID int) error {
+	statement := `UPDATE jobs SET state = 'succeeded', last_error = '', updated_at = ?, finished_at = ? WHERE job_id = ? AND state = 'running';`
+
+	now := time.Now().UTC()
+	_, err := jobModel.DB.Exec(statement, now, now, jobID)
+	return err
+}
+
+// RetryJob puts the job back in the queue, it will be claimed again after runAt
+func (jobModel *JobModel) RetryJob(jobID int, lastError string, runAt time.Time) error {
+	statement := `UPDATE jobs SET state = 'queued', last_error = ?, run_at = ?, updated_at = ? WHERE job_id = ? AND state = 'running';`
+
+	_, err := jobModel.DB.Exec(statement, lastError, runAt.UTC(), time.Now().UTC(), jobID)
+	return err
+}
+
+// FailJob moves the job to the dead-letter state
+func (jobModel *JobModel) FailJob(jobID int, lastError string) error {
+	statement := `UPDATE jobs SET state = 'dead', last_error = ?, updated_at = ?, finished_at = ? WHERE job_id = ? AND state = 'running';`
+
+	now := time.Now().UTC()
+	_, err := jobModel.DB.Exec(statement, lastError, now, now, jobID)
+	return err
+}
+
+// SaveJobResult stores what a running job reports, it is kept when the job is retried or requeued
+func (jobModel *JobModel) SaveJobResult(jobID int, result json.RawMessage) error {
+	statement := `UPDATE jobs SET result = ?, updated_at = ? WHERE job_id = ?;`
+
+	_, err := jobModel.DB.Exec(statement, string(result), time.Now().UTC(), jobID)
+	return err
+}
+
+// PurgeJobs deletes the succeeded and cancelled jobs which finished longer than retention ago
+// dead jobs are kept until they are looked at, it returns how many jobs were purged
+func (jobModel *JobModel) PurgeJobs(retention time.Duration) (int, error) {
+	statement := `DELETE FROM jobs WHERE state IN ('succeeded', 'cancelled') AND finished_at <= ?;`
+
+	result, err := jobModel.DB.Exec(statement, time.Now().UTC().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
+
+// RequeueRunningJobs puts back jobs left "running" by a server which stopped mid-job
+func (jobModel *JobModel) RequeueRunningJobs() (int, error) {
+	statement := `UPDATE jobs SET state = 'queued', updated_at = ? WHERE state = 'running';`
+
+	result, err := jobModel.DB.Exec(statement, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
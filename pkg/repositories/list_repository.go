@@ -0,0 +1,673 @@
+package repositories
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/query"
+)
+
+var (
+	// ErrListExists is returned when a list is created or renamed to a name already taken
+	ErrListExists = errors.New("list already exists")
+
+	// ErrListEntryExists is returned when an entry is added twice to the same list
+	ErrListEntryExists = errors.New("entry is already in the list")
+
+	// ErrListOrder is returned when a new order does not list every entry of the list exactly once
+	ErrListOrder = errors.New("the order must list every entry of the list exactly once")
+
+	// ErrListKind is returned when entries are added to a smart list or a filter is set on a static list
+	ErrListKind = errors.New("the entries of a smart list come from its filter, only smart lists have a filter")
+
+	// ErrInvalidFilter wraps what is wrong with the filter of a smart list
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+type ListModelInterface interface {
+	GetLists() ([]models.List, error)
+	GetListById(list_id string) (models.List, error)
+	GetPublicLists() ([]models.List, error)
+	GetSharedList(share_token string) (models.List, error)
+	GetListEntries(list_id string, page models.PageQuery) (models.ListEntriesPage, error)
+	GetSharedListEntries(share_token string, page models.PageQuery) (models.ListEntriesPage, error)
+
+	AddList(list models.ListRequest) (models.List, error)
+	UpdateList(list_id string, list models.ListUpdateRequest) (models.List, error)
+	DeleteList(list_id string) (int, error)
+
+	AddListEntry(list_id string, entry models.ListEntryRequest) (models.List, error)
+	RemoveListEntry(list_id string, watchlist_id string) (int, error)
+	ReorderList(list_id string, watchlist_ids []int) (models.List, error)
+}
+
+type ListModel struct {
+	DB *sql.DB
+}
+
+// the entry count is the one of static lists, smart lists are counted by evaluating their filter
+const listColumns = `lists.list_id, lists.name, lists.description, lists.visibility, lists.share_token, lists.created_at, lists.updated_at,
+	lists.filter, lists.pinned, (SELECT COUNT(*) FROM list_entries WHERE list_entries.list_id = lists.list_id)`
+
+func scanList(scanner interface{ Scan(dest ...any) error }) (models.List, error) {
+	list := models.List{}
+	var filter sql.NullString
+	err := scanner.Scan(&list.ListID, &list.Name, &list.Description, &list.Visibility, &list.ShareToken,
+		&list.CreatedAt, &list.UpdatedAt, &filter, &list.Pinned, &list.EntryCount)
+	if err != nil || !filter.Valid {
+		return list, err
+	}
+
+	list.Smart = true
+	list.Filter = &models.SmartFilter{}
+	err = json.Unmarshal([]byte(filter.String), list.Filter)
+	return list, err
+}
+
+// GetLists lists every list of the owner without their entries, pinned lists first
+func (listModel *ListModel) GetLists() ([]models.List, error) {
+	return listModel.queryLists(`SELECT ` + listColumns + ` FROM lists ORDER BY lists.pinned DESC, lists.name;`)
+}
+
+// GetPublicLists lists the public lists without their entries
+func (listModel *ListModel) GetPublicLists() ([]models.List, error) {
+	return listModel.queryLists(`SELECT ` + listColumns + ` FROM lists WHERE lists.visibility = 'public' ORDER BY lists.name;`)
+}
+
+// GetListById returns a list with its entries in order
+func (listModel *ListModel) GetListById(list_id string) (models.List, error) {
+	return listModel.queryList(`SELECT `+listColumns+` FROM lists WHERE lists.list_id = ?;`, list_id)
+}
+
+// GetSharedList returns an unlisted or public list by its share token
+// sql.ErrNoRows is returned for private lists so their existence is not leaked
+func (listModel *ListModel) GetSharedList(share_token string) (models.List, error) {
+	return listModel.queryList(`SELECT `+listColumns+` FROM lists WHERE lists.share_token = ? AND lists.visibility <> 'private';`, share_token)
+}
+
+func (listModel *ListModel) queryLists(statement string, args ...any) ([]models.List, error) {
+	rows, err := listModel.DB.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []models.List{}
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range lists {
+		err = listModel.countSmartList(&lists[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lists, nil
+}
+
+// queryList reads a single list and loads its entries
+func (listModel *ListModel) queryList(statement string, args ...any) (models.List, error) {
+	list, err := scanList(listModel.DB.QueryRow(statement, args...))
+	if err != nil {
+		return list, err
+	}
+	if list.Smart {
+		return list, listModel.countSmartList(&list)
+	}
+
+	// the positions are read first, the single connection can not hold two row sets
+	entries := []models.ListEntry{}
+	rows, err := listModel.DB.Query(`SELECT position, added_at FROM list_entries WHERE list_id = ? ORDER BY position, watchlist_id;`, list.ListID)
+	if err != nil {
+		return list, err
+	}
+	for rows.Next() {
+		entry := models.ListEntry{AddedAt: &time.Time{}}
+		err := rows.Scan(&entry.Position, entry.AddedAt)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return list, err
+	}
+
+	watchListModel := &WatchListModel{DB: listModel.DB}
+	watchLists, err := watchListModel.queryWatchLists(`SELECT `+watchListColumns+` FROM Watchlist
+	JOIN list_entries ON list_entries.watchlist_id = Watchlist.watchlist_id
+	WHERE list_entries.list_id = ? ORDER BY list_entries.position, list_entries.watchlist_id;`, list.ListID)
+	if err != nil {
+		return list, err
+	}
+
+	// both queries use the same order, an entry deleted in between drops the tail
+	if len(watchLists) < len(entries) {
+		entries = entries[:len(watchLists)]
+	}
+	for i := range entries {
+		entries[i].Watchlist = watchLists[i]
+	}
+	list.Entries = entries
+
+	return list, nil
+}
+
+// AddList creates a list with a new share token, it is a smart list when it has a filter
+// ErrListExists is returned when the name is taken and ErrInvalidFilter when the filter does not compile
+func (listModel *ListModel) AddList(list models.ListRequest) (models.List, error) {
+	name := strings.TrimSpace(list.Name)
+	visibility := list.Visibility
+	if visibility == "" {
+		visibility = "private"
+	}
+	now := time.Now().UTC()
+
+	filter, err := marshalFilter(list.Filter)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	result, err := listModel.DB.Exec(`INSERT INTO lists (name, description, visibility, share_token, created_at, updated_at, filter, pinned)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT(name) DO NOTHING;`, name, list.Description, visibility, newShareToken(), now, now, filter, list.Pinned)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.List{}, err
+	}
+	if rowAffected == 0 {
+		return models.List{}, ErrListExists
+	}
+
+	listID, err := result.LastInsertId()
+	if err != nil {
+		return models.List{}, err
+	}
+
+	return listModel.queryList(`SELECT `+listColumns+` FROM lists WHERE lists.list_id = ?;`, listID)
+}
+
+// UpdateList changes the fields of a list which are sent
+// sql.ErrNoRows is returned when the list does not exist, ErrListExists when another list has the name
+// and ErrListKind when a filter is sent for a static list
+func (listModel *ListModel) UpdateList(list_id string, list models.ListUpdateRequest) (models.List, error) {
+	filter, err := marshalFilter(list.Filter)
+	if err != nil {
+		return models.List{}, err
+	}
+	if filter != nil {
+		var smart bool
+		err = listModel.DB.QueryRow(`SELECT filter IS NOT NULL FROM lists WHERE list_id = ?;`, list_id).Scan(&smart)
+		if err != nil {
+			return models.List{}, err
+		}
+		if !smart {
+			return models.List{}, ErrListKind
+		}
+	}
+
+	var name *string
+	if list.Name != nil {
+		trimmed := strings.TrimSpace(*list.Name)
+		name = &trimmed
+
+		var other int
+		err := listModel.DB.QueryRow(`SELECT list_id FROM lists WHERE name = ? AND list_id <> ?;`, trimmed, list_id).Scan(&other)
+		if err == nil {
+			return models.List{}, ErrListExists
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return models.List{}, err
+		}
+	}
+
+	result, err := listModel.DB.Exec(`UPDATE lists SET name = COALESCE(?, name), description = COALESCE(?, description),
+	visibility = COALESCE(?, visibility), filter = COALESCE(?, filter), pinned = COALESCE(?, pinned), updated_at = ? WHERE list_id = ?;`,
+		name, list.Description, list.Visibility, filter, list.Pinned, time.Now().UTC(), list_id)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.List{}, err
+	}
+	if rowAffected == 0 {
+		return models.List{}, sql.ErrNoRows
+	}
+
+	return listModel.GetListById(list_id)
+}
+
+// DeleteList deletes a list, its entries stay in the watchlist
+func (listModel *ListModel) DeleteList(list_id string) (int, error) {
+	tx, err := listModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// foreign keys are not enforced by default in SQLite, so the entries are deleted by hand
+	_, err = tx.Exec(`DELETE FROM list_entries WHERE list_id = ?;`, list_id)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM lists WHERE list_id = ?;`, list_id)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
+
+// AddListEntry adds an entry to a list at the position, or at the end
+// the entries from the position on move down by one
+// sql.ErrNoRows is returned when the list or the entry does not exist, ErrListEntryExists when it is already in the list
+// and ErrListKind when the list is a smart list
+func (listModel *ListModel) AddListEntry(list_id string, entry models.ListEntryRequest) (models.List, error) {
+	tx, err := listModel.DB.Begin()
+	if err != nil {
+		return models.List{}, err
+	}
+	defer tx.Rollback()
+
+	var listID, count int
+	var smart bool
+	err = tx.QueryRow(`SELECT list_id, (SELECT COUNT(*) FROM list_entries WHERE list_entries.list_id = lists.list_id), filter IS NOT NULL
+	FROM lists WHERE list_id = ?;`, list_id).Scan(&listID, &count, &smart)
+	if err != nil {
+		return models.List{}, err
+	}
+	if smart {
+		return models.List{}, ErrListKind
+	}
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, entry.WatchlistID).Scan(&watchlistID)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	var exists int
+	err = tx.QueryRow(`SELECT 1 FROM list_entries WHERE list_id = ? AND watchlist_id = ?;`, listID, watchlistID).Scan(&exists)
+	if err == nil {
+		return models.List{}, ErrListEntryExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.List{}, err
+	}
+
+	position := count + 1
+	if entry.Position != nil && *entry.Position < position {
+		position = *entry.Position
+	}
+
+	_, err = tx.Exec(`UPDATE list_entries SET position = position + 1 WHERE list_id = ? AND position >= ?;`, listID, position)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	now := time.Now().UTC()
+	_, err = tx.Exec(`INSERT INTO list_entries (list_id, watchlist_id, position, added_at) VALUES (?, ?, ?, ?);`,
+		listID, watchlistID, position, now)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	_, err = tx.Exec(`UPDATE lists SET updated_at = ? WHERE list_id = ?;`, now, listID)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.List{}, err
+	}
+
+	return listModel.GetListById(list_id)
+}
+
+// RemoveListEntry removes an entry from a list, the entries after it move up by one
+func (listModel *ListModel) RemoveListEntry(list_id string, watchlist_id string) (int, error) {
+	tx, err := listModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var listID, position int
+	err = tx.QueryRow(`SELECT list_id, position FROM list_entries WHERE list_id = ? AND watchlist_id = ?;`, list_id, watchlist_id).Scan(&listID, &position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM list_entries WHERE list_id = ? AND watchlist_id = ?;`, listID, watchlist_id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = compactList(tx, listID, position)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return 1, nil
+}
+
+// ReorderList gives the entries of a list the order of watchlist_ids
+// sql.ErrNoRows is returned when the list does not exist and ErrListOrder when an entry is missing, repeated or not in the list
+func (listModel *ListModel) ReorderList(list_id string, watchlist_ids []int) (models.List, error) {
+	tx, err := listModel.DB.Begin()
+	if err != nil {
+		return models.List{}, err
+	}
+	defer tx.Rollback()
+
+	var listID, count int
+	var smart bool
+	err = tx.QueryRow(`SELECT list_id, (SELECT COUNT(*) FROM list_entries WHERE list_entries.list_id = lists.list_id), filter IS NOT NULL
+	FROM lists WHERE list_id = ?;`, list_id).Scan(&listID, &count, &smart)
+	if err != nil {
+		return models.List{}, err
+	}
+	if smart {
+		return models.List{}, ErrListKind
+	}
+	if len(watchlist_ids) != count {
+		return models.List{}, ErrListOrder
+	}
+
+	seen := map[int]bool{}
+	for i, watchlistID := range watchlist_ids {
+		if seen[watchlistID] {
+			return models.List{}, ErrListOrder
+		}
+		seen[watchlistID] = true
+
+		result, err := tx.Exec(`UPDATE list_entries SET position = ? WHERE list_id = ? AND watchlist_id = ?;`, i+1, listID, watchlistID)
+		if err != nil {
+			return models.List{}, err
+		}
+
+		rowAffected, err := result.RowsAffected()
+		if err != nil {
+			return models.List{}, err
+		}
+		if rowAffected == 0 {
+			return models.List{}, ErrListOrder
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE lists SET updated_at = ? WHERE list_id = ?;`, time.Now().UTC(), listID)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.List{}, err
+	}
+
+	return listModel.GetListById(list_id)
+}
+
+// Smart lists
+// =====================================================================================
+
+// DefaultPageSize is the page size of the entries when none is asked
+const DefaultPageSize = 20
+
+// GetListEntries returns a page of the entries of a list, the entries matching the filter for a smart list
+// sql.ErrNoRows is returned when the list does not exist
+func (listModel *ListModel) GetListEntries(list_id string, page models.PageQuery) (models.ListEntriesPage, error) {
+	list, err := scanList(listModel.DB.QueryRow(`SELECT `+listColumns+` FROM lists WHERE lists.list_id = ?;`, list_id))
+	if err != nil {
+		return models.ListEntriesPage{}, err
+	}
+	return listModel.listEntries(list, page)
+}
+
+// GetSharedListEntries is GetListEntries for an unlisted or public list opened by its share token
+func (listModel *ListModel) GetSharedListEntries(share_token string, page models.PageQuery) (models.ListEntriesPage, error) {
+	list, err := scanList(listModel.DB.QueryRow(`SELECT `+listColumns+` FROM lists WHERE lists.share_token = ? AND lists.visibility <> 'private';`, share_token))
+	if err != nil {
+		return models.ListEntriesPage{}, err
+	}
+	return listModel.listEntries(list, page)
+}
+
+func (listModel *ListModel) listEntries(list models.List, page models.PageQuery) (models.ListEntriesPage, error) {
+	if page.Page == 0 {
+		page.Page = 1
+	}
+	if page.PageSize == 0 {
+		page.PageSize = DefaultPageSize
+	}
+	result := models.ListEntriesPage{Entries: []models.ListEntry{}, Page: page.Page, PageSize: page.PageSize}
+	offset := (page.Page - 1) * page.PageSize
+
+	if !list.Smart {
+		list, err := listModel.GetListById(strconv.Itoa(list.ListID))
+		if err != nil {
+			return result, err
+		}
+
+		result.Total = len(list.Entries)
+		if offset < len(list.Entries) {
+			result.Entries = list.Entries[offset:min(offset+page.PageSize, len(list.Entries))]
+		}
+		return result, nil
+	}
+
+	condition, args, err := compileSmartFilter(*list.Filter)
+	if err != nil {
+		return result, err
+	}
+
+	err = listModel.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE `+condition+`;`, args...).Scan(&result.Total)
+	if err != nil {
+		return result, err
+	}
+
+	watchListModel := &WatchListModel{DB: listModel.DB}
+	watchLists, err := watchListModel.queryWatchLists(`SELECT `+watchListColumns+` FROM Watchlist WHERE `+condition+`
+	ORDER BY `+watchListOrderBy(list.Filter.Sort)+` LIMIT ? OFFSET ?;`, append(args, page.PageSize, offset)...)
+	if err != nil {
+		return result, err
+	}
+
+	for i, watchList := range watchLists {
+		result.Entries = append(result.Entries, models.ListEntry{Position: offset + i + 1, Watchlist: watchList})
+	}
+	return result, nil
+}
+
+// countSmartList sets the entry count of a smart list to the number of entries matching its filter
+func (listModel *ListModel) countSmartList(list *models.List) error {
+	if !list.Smart {
+		return nil
+	}
+
+	condition, args, err := compileSmartFilter(*list.Filter)
+	if err != nil {
+		return err
+	}
+	return listModel.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE `+condition+`;`, args...).Scan(&list.EntryCount)
+}
+
+// marshalFilter checks that a filter compiles and returns the JSON stored for it, nil stays nil
+func marshalFilter(filter *models.SmartFilter) (*string, error) {
+	if filter == nil {
+		return nil, nil
+	}
+
+	_, _, err := compileSmartFilter(*filter)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	stored := string(data)
+	return &stored, nil
+}
+
+// compileSmartFilter turns a filter into a WHERE condition on Watchlist
+// every value is a parameter, only fixed column names and the whitelisted sort end up in the SQL
+func compileSmartFilter(filter models.SmartFilter) (string, []any, error) {
+	err := filter.Validate()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	// the entries in the trash never match
+	conditions := []string{"Watchlist.deleted_at IS NULL"}
+	args := []any{}
+
+	if len(filter.Statuses) > 0 {
+		in, inArgs := stringPlaceholders(filter.Statuses)
+		conditions = append(conditions, `Watchlist.status IN (`+in+`)`)
+		args = append(args, inArgs...)
+	}
+	if len(filter.Kinds) > 0 {
+		in, inArgs := stringPlaceholders(filter.Kinds)
+		conditions = append(conditions, `Watchlist.kind IN (`+in+`)`)
+		args = append(args, inArgs...)
+	}
+	if genres := uniqueNames(filter.Genres); len(genres) > 0 {
+		// genre names compare case-insensitively through the NOCASE collation of genres.name
+		in, inArgs := stringPlaceholders(genres)
+		conditions = append(conditions, `Watchlist.watchlist_id IN (SELECT watchlist_genres.watchlist_id FROM watchlist_genres
+		JOIN genres ON genres.genre_id = watchlist_genres.genre_id WHERE genres.name IN (`+in+`))`)
+		args = append(args, inArgs...)
+	}
+	if tags := uniqueNames(filter.Tags); len(tags) > 0 {
+		condition, tagArgs := tagCondition(tags, filter.TagMatch)
+		conditions = append(conditions, condition)
+		args = append(args, tagArgs...)
+	}
+	if filter.MinYear > 0 {
+		conditions = append(conditions, `Watchlist.release_year >= ?`)
+		args = append(args, filter.MinYear)
+	}
+	if filter.MaxYear > 0 {
+		conditions = append(conditions, `Watchlist.release_year <= ?`)
+		args = append(args, filter.MaxYear)
+	}
+	if filter.MinRating > 0 {
+		conditions = append(conditions, averageRatingColumn+` >= ?`)
+		args = append(args, filter.MinRating)
+	}
+	if filter.MaxRating > 0 {
+		conditions = append(conditions, averageRatingColumn+` <= ?`)
+		args = append(args, filter.MaxRating)
+	}
+	if title := strings.TrimSpace(filter.Title); title != "" {
+		conditions = append(conditions, `Watchlist.title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+query.EscapeLike(title)+"%")
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// compactList closes the gap left at the position of a removed entry
+func compactList(tx *sql.Tx, listID int, position int) error {
+	_, err := tx.Exec(`UPDATE list_entries SET position = position - 1 WHERE list_id = ? AND position > ?;`, listID, position)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE lists SET updated_at = ? WHERE list_id = ?;`, time.Now().UTC(), listID)
+	return err
+}
+
+// removeFromLists removes a deleted entry from every list it is in
+func removeFromLists(tx *sql.Tx, watchlistID int) error {
+	// the positions are read first, the single connection can not hold two row sets
+	rows, err := tx.Query(`SELECT list_id, position FROM list_entries WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return err
+	}
+	positions := map[int]int{}
+	for rows.Next() {
+		var listID, position int
+		err := rows.Scan(&listID, &position)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		positions[listID] = position
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM list_entries WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return err
+	}
+
+	for listID, position := range positions {
+		err = compactList(tx, listID, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newShareToken() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
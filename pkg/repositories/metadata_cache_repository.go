@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+type MetadataCacheModel struct {
+	DB *sql.DB
+}
+
+// GetMetadata returns the cached response when it is younger than maxAge
+// a maxAge of 0 accepts any cached response
+func (metadataCacheModel *MetadataCacheModel) GetMetadata(cacheKey string, maxAge time.Duration) (models.Metadata, bool, error) {
+	statement := `SELECT response, fetched_at FROM metadata_cache WHERE cache_key = ?;`
+
+	var response string
+	var fetchedAt time.Time
+
+	err := metadataCacheModel.DB.QueryRow(statement, cacheKey).Scan(&response, &fetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Metadata{}, false, nil
+	}
+	if err != nil {
+		return models.Metadata{}, false, err
+	}
+
+	if maxAge > 0 && time.Since(fetchedAt) > maxAge {
+		return models.Metadata{}, false, nil
+	}
+
+	metadata := models.Metadata{}
+	err = json.Unmarshal([]byte(response), &metadata)
+	if err != nil {
+		return models.Metadata{}, false, err
+	}
+
+	return metadata, true, nil
+}
+
+// PutMetadata stores (or refreshes) the response for cacheKey
+func (metadataCacheModel *MetadataCacheModel) PutMetadata(cacheKey string, metadata models.Metadata) error {
+	statement := `INSERT INTO metadata_cache (cache_key, provider, response, fetched_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(cache_key) DO UPDATE SET provider = excluded.provider, response = excluded.response, fetched_at = excluded.fetched_at;`
+
+	response, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = metadataCacheModel.DB.Exec(statement, cacheKey, metadata.Provider, string(response), time.Now().UTC())
+	return err
+}
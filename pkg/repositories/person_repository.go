@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+type PersonModelInterface interface {
+	GetPersonById(person_id string) (models.Person, error)
+	GetWatchListByPerson(person_id string, role string) ([]models.Watchlist, error)
+}
+
+type PersonModel struct {
+	DB *sql.DB
+}
+
+func (personModel *PersonModel) GetPersonById(person_id string) (models.Person, error) {
+	statement := `SELECT person_id, name FROM people WHERE person_id = ?;`
+
+	person := models.Person{}
+	err := personModel.DB.QueryRow(statement, person_id).Scan(&person.PersonID, &person.Name)
+	return person, err
+}
+
+// GetWatchListByPerson lists the entries a person is credited on
+// an empty role matches every role
+func (personModel *PersonModel) GetWatchListByPerson(person_id string, role string) ([]models.Watchlist, error) {
+	statement := `SELECT DISTINCT ` + watchListColumns + ` FROM Watchlist
+	JOIN watchlist_credits ON watchlist_credits.watchlist_id = Watchlist.watchlist_id
+	WHERE watchlist_credits.person_id = ? AND (? = '' OR watchlist_credits.role = ?) AND Watchlist.deleted_at IS NULL
+	ORDER BY Watchlist.watchlist_id;`
+
+	watchListModel := &WatchListModel{DB: personModel.DB}
+	return watchListModel.queryWatchLists(statement, person_id, role, role)
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// ErrReviewExists is returned by AddReview when the entry already has a review
+var ErrReviewExists = errors.New("review already exists")
+
+type ReviewModelInterface interface {
+	GetReview(watchlist_id string) (models.Review, error)
+
+	AddReview(watchlist_id string, review models.ReviewRequest) (models.Review, error)
+	UpdateReview(watchlist_id string, review models.ReviewRequest) (models.Review, error)
+	DeleteReview(watchlist_id string) (int, error)
+
+	As(actor models.Actor) ReviewModelInterface
+}
+
+type ReviewModel struct {
+	DB *sql.DB
+
+	// who makes the writes, set with As, the zero value is models.SystemActor
+	actor models.Actor
+}
+
+// As returns the model making its writes as actor, a write of a review is a write of its entry in the audit
+func (reviewModel *ReviewModel) As(actor models.Actor) ReviewModelInterface {
+	model := *reviewModel
+	model.actor = actor
+	return &model
+}
+
+// GetReview returns the review of an entry with its edit history, newest edit first
+// sql.ErrNoRows is returned when the entry has no review
+func (reviewModel *ReviewModel) GetReview(watchlist_id string) (models.Review, error) {
+	statement := `SELECT review_id, watchlist_id, rating, review_text, spoiler, created_at, updated_at FROM reviews
+	WHERE watchlist_id = ? AND watchlist_id IN (SELECT watchlist_id FROM Watchlist WHERE deleted_at IS NULL);`
+
+	review := models.Review{}
+	err := reviewModel.DB.QueryRow(statement, watchlist_id).Scan(
+		&review.ReviewID,
+		&review.WatchlistID,
+		&review.Rating,
+		&review.Text,
+		&review.Spoiler,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return review, err
+	}
+
+	rows, err := reviewModel.DB.Query(`SELECT revision_id, rating, review_text, spoiler, edited_at FROM review_revisions
+	WHERE review_id = ? ORDER BY revision_id DESC;`, review.ReviewID)
+	if err != nil {
+		return review, err
+	}
+	defer rows.Close()
+
+	review.History = []models.ReviewRevision{}
+	for rows.Next() {
+		revision := models.ReviewRevision{}
+		err := rows.Scan(&revision.RevisionID, &revision.Rating, &revision.Text, &revision.Spoiler, &revision.EditedAt)
+		if err != nil {
+			return review, err
+		}
+		review.History = append(review.History, revision)
+	}
+
+	return review, rows.Err()
+}
+
+// AddReview creates the review of an entry
+// sql.ErrNoRows is returned when the entry does not exist and ErrReviewExists when it is already reviewed
+func (reviewModel *ReviewModel) AddReview(watchlist_id string, review models.ReviewRequest) (models.Review, error) {
+	tx, err := reviewModel.DB.Begin()
+	if err != nil {
+		return models.Review{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	var exists int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM reviews WHERE watchlist_id = ?;`, watchlistID).Scan(&exists)
+	if err != nil {
+		return models.Review{}, err
+	}
+	if exists > 0 {
+		return models.Review{}, ErrReviewExists
+	}
+
+	audit, err := beginAudit(tx, reviewModel.actor, "review", watchlistID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	now := time.Now().UTC()
+	_, err = tx.Exec(`INSERT INTO reviews (watchlist_id, rating, review_text, spoiler, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?);`,
+		watchlistID, review.Rating, review.Text, review.Spoiler, now, now)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	err = audit.touch(tx)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	return reviewModel.GetReview(watchlist_id)
+}
+
+// UpdateReview replaces the review of an entry, the previous version is added to the history
+// sql.ErrNoRows is returned when the entry has no review
+func (reviewModel *ReviewModel) UpdateReview(watchlist_id string, review models.ReviewRequest) (models.Review, error) {
+	tx, err := reviewModel.DB.Begin()
+	if err != nil {
+		return models.Review{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM reviews WHERE watchlist_id = ?;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	audit, err := beginAudit(tx, reviewModel.actor, "review", watchlistID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	now := time.Now().UTC()
+	_, err = tx.Exec(`INSERT INTO review_revisions (review_id, watchlist_id, rating, review_text, spoiler, edited_at)
+	SELECT review_id, watchlist_id, rating, review_text, spoiler, ? FROM reviews WHERE watchlist_id = ?;`, now, watchlistID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	_, err = tx.Exec(`UPDATE reviews SET rating = ?, review_text = ?, spoiler = ?, updated_at = ? WHERE watchlist_id = ?;`,
+		review.Rating, review.Text, review.Spoiler, now, watchlistID)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	// only a new rating changes the entry
+	err = audit.touch(tx)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	return reviewModel.GetReview(watchlist_id)
+}
+
+// DeleteReview removes the review of an entry with its history
+func (reviewModel *ReviewModel) DeleteReview(watchlist_id string) (int, error) {
+	tx, err := reviewModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM reviews WHERE watchlist_id = ?;`, watchlist_id).Scan(&watchlistID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	audit, err := beginAudit(tx, reviewModel.actor, "review", watchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM review_revisions WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM reviews WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	err = audit.touch(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
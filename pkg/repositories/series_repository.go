@@ -0,0 +1,309 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// ErrNotEpisodic is returned when seasons are added to a movie or a short
+var ErrNotEpisodic = errors.New("only series, miniseries and documentaries have seasons")
+
+type SeriesModelInterface interface {
+	GetSeasons(watchlist_id string) ([]models.Season, error)
+
+	SaveSeason(watchlist_id string, season_number int, season models.SeasonRequest) (models.Season, error)
+	DeleteSeason(watchlist_id string, season_number int) (int, error)
+	MarkEpisodes(watchlist_id string, season_number int, episodes models.EpisodeRangeRequest, watched bool) (models.Watchlist, error)
+
+	As(actor models.Actor) SeriesModelInterface
+}
+
+type SeriesModel struct {
+	DB *sql.DB
+
+	// who makes the writes, set with As, the zero value is models.SystemActor
+	actor models.Actor
+}
+
+// As returns the model making its writes as actor, a write of the seasons is a write of their entry in the audit
+func (seriesModel *SeriesModel) As(actor models.Actor) SeriesModelInterface {
+	model := *seriesModel
+	model.actor = actor
+	return &model
+}
+
+const episodeColumns = `episodes.episode_id, seasons.season_number, episodes.episode_number, episodes.title, episodes.air_date, episodes.watched_at`
+
+// GetSeasons returns the seasons of an entry with their episodes, in order
+// sql.ErrNoRows is returned when the entry does not exist
+func (seriesModel *SeriesModel) GetSeasons(watchlist_id string) ([]models.Season, error) {
+	var watchlistID int
+	err := seriesModel.DB.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	return seriesModel.querySeasons(`WHERE seasons.watchlist_id = ?`, watchlistID)
+}
+
+// querySeasons reads the seasons matching the filter, episodes are read in the same query
+func (seriesModel *SeriesModel) querySeasons(filter string, args ...any) ([]models.Season, error) {
+	rows, err := seriesModel.DB.Query(`SELECT seasons.season_id, seasons.watchlist_id, seasons.title, `+episodeColumns+` FROM seasons
+	LEFT JOIN episodes ON episodes.season_id = seasons.season_id
+	`+filter+` ORDER BY seasons.season_number, episodes.episode_number;`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []models.Season{}
+	for rows.Next() {
+		season := models.Season{}
+		var episodeID, episodeNumber sql.NullInt64
+		var episodeTitle sql.NullString
+		episode := models.Episode{}
+
+		err := rows.Scan(&season.SeasonID, &season.WatchlistID, &season.Title,
+			&episodeID, &season.SeasonNumber, &episodeNumber, &episodeTitle, &episode.AirDate, &episode.WatchedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		// a season without episodes comes back as a single row of nulls
+		if len(seasons) == 0 || seasons[len(seasons)-1].SeasonID != season.SeasonID {
+			season.Episodes = []models.Episode{}
+			seasons = append(seasons, season)
+		}
+		if !episodeID.Valid {
+			continue
+		}
+
+		episode.EpisodeID = int(episodeID.Int64)
+		episode.SeasonNumber = season.SeasonNumber
+		episode.EpisodeNumber = int(episodeNumber.Int64)
+		episode.Title = episodeTitle.String
+
+		last := &seasons[len(seasons)-1]
+		last.Episodes = append(last.Episodes, episode)
+	}
+
+	return seasons, rows.Err()
+}
+
+// SaveSeason creates a season of an entry or adds the missing episodes to it
+// titles and air dates sent are updated, the watched state of stored episodes is kept
+// sql.ErrNoRows is returned when the entry does not exist and ErrNotEpisodic when it is not a series
+func (seriesModel *SeriesModel) SaveSeason(watchlist_id string, season_number int, season models.SeasonRequest) (models.Season, error) {
+	tx, err := seriesModel.DB.Begin()
+	if err != nil {
+		return models.Season{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	var kind string
+	err = tx.QueryRow(`SELECT watchlist_id, kind FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID, &kind)
+	if err != nil {
+		return models.Season{}, err
+	}
+	if !models.IsEpisodic(kind) {
+		return models.Season{}, ErrNotEpisodic
+	}
+
+	audit, err := beginAudit(tx, seriesModel.actor, "season", watchlistID)
+	if err != nil {
+		return models.Season{}, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO seasons (watchlist_id, season_number, title) VALUES (?, ?, ?)
+	ON CONFLICT(watchlist_id, season_number) DO UPDATE SET title = COALESCE(NULLIF(excluded.title, ''), title);`,
+		watchlistID, season_number, season.Title)
+	if err != nil {
+		return models.Season{}, err
+	}
+
+	var seasonID int
+	err = tx.QueryRow(`SELECT season_id FROM seasons WHERE watchlist_id = ? AND season_number = ?;`, watchlistID, season_number).Scan(&seasonID)
+	if err != nil {
+		return models.Season{}, err
+	}
+
+	for episodeNumber := 1; episodeNumber <= season.EpisodeCount; episodeNumber++ {
+		_, err = tx.Exec(`INSERT OR IGNORE INTO episodes (season_id, episode_number) VALUES (?, ?);`, seasonID, episodeNumber)
+		if err != nil {
+			return models.Season{}, err
+		}
+	}
+
+	for _, episode := range season.Episodes {
+		_, err = tx.Exec(`INSERT INTO episodes (season_id, episode_number, title, air_date) VALUES (?, ?, ?, ?)
+		ON CONFLICT(season_id, episode_number) DO UPDATE SET title = COALESCE(NULLIF(excluded.title, ''), title), air_date = COALESCE(excluded.air_date, air_date);`,
+			seasonID, episode.EpisodeNumber, episode.Title, episode.AirDate)
+		if err != nil {
+			return models.Season{}, err
+		}
+	}
+
+	err = audit.touch(tx)
+	if err != nil {
+		return models.Season{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Season{}, err
+	}
+
+	seasons, err := seriesModel.querySeasons(`WHERE seasons.season_id = ?`, seasonID)
+	if err != nil {
+		return models.Season{}, err
+	}
+	if len(seasons) == 0 {
+		return models.Season{}, sql.ErrNoRows
+	}
+
+	return seasons[0], nil
+}
+
+// DeleteSeason removes a season of an entry with its episodes
+func (seriesModel *SeriesModel) DeleteSeason(watchlist_id string, season_number int) (int, error) {
+	tx, err := seriesModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID, seasonID int
+	err = tx.QueryRow(`SELECT watchlist_id, season_id FROM seasons WHERE watchlist_id = ? AND season_number = ?;`, watchlist_id, season_number).Scan(&watchlistID, &seasonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	audit, err := beginAudit(tx, seriesModel.actor, "season", watchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	// foreign keys are not enforced by default in SQLite, so the episodes are deleted by hand
+	_, err = tx.Exec(`DELETE FROM episodes WHERE season_id = ?;`, seasonID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM seasons WHERE season_id = ?;`, seasonID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	err = audit.touch(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
+
+// MarkEpisodes marks a range of episodes of a season as watched or not watched and returns the entry with its progress
+// episodes already watched keep their date, watching an episode moves the entry to watching
+// and watching the last one moves it to watched
+// sql.ErrNoRows is returned when the season does not exist
+func (seriesModel *SeriesModel) MarkEpisodes(watchlist_id string, season_number int, episodes models.EpisodeRangeRequest, watched bool) (models.Watchlist, error) {
+	at := time.Now().UTC()
+	if episodes.At != nil {
+		at = episodes.At.UTC()
+	}
+
+	tx, err := seriesModel.DB.Begin()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID, seasonID int
+	err = tx.QueryRow(`SELECT watchlist_id, season_id FROM seasons WHERE watchlist_id = ? AND season_number = ?;`, watchlist_id, season_number).Scan(&watchlistID, &seasonID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	audit, err := beginAudit(tx, seriesModel.actor, "episodes", watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	// to = 0 is the end of the season
+	statement := `UPDATE episodes SET watched_at = COALESCE(watched_at, ?1) WHERE season_id = ?2 AND episode_number >= ?3 AND (?4 = 0 OR episode_number <= ?4);`
+	if !watched {
+		statement = `UPDATE episodes SET watched_at = NULL WHERE season_id = ?2 AND episode_number >= ?3 AND (?4 = 0 OR episode_number <= ?4);`
+	}
+
+	_, err = tx.Exec(statement, at, seasonID, episodes.From, episodes.To)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	if watched {
+		// watching an episode counts as progress for continue watching
+		_, err = tx.Exec(`UPDATE Watchlist SET progress_updated_at = ? WHERE watchlist_id = ?;`, at, watchlistID)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+
+		err = syncSeriesStatus(tx, watchlistID, at)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+	}
+
+	err = audit.touch(tx)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	watchListModel := &WatchListModel{DB: seriesModel.DB}
+	return watchListModel.GetWatchListById(watchlist_id)
+}
+
+// syncSeriesStatus moves a series to watching once an episode is watched and to watched once every episode is
+// a watched series with a new season goes back to watching
+func syncSeriesStatus(tx *sql.Tx, watchlistID int, at time.Time) error {
+	var status string
+	var episodeCount, watchedCount int
+	err := tx.QueryRow(`SELECT Watchlist.status, COUNT(episodes.episode_id), COUNT(episodes.watched_at) FROM Watchlist
+	LEFT JOIN seasons ON seasons.watchlist_id = Watchlist.watchlist_id
+	LEFT JOIN episodes ON episodes.season_id = seasons.season_id
+	WHERE Watchlist.watchlist_id = ? GROUP BY Watchlist.watchlist_id;`, watchlistID).Scan(&status, &episodeCount, &watchedCount)
+	if err != nil {
+		return err
+	}
+
+	to := "watching"
+	if episodeCount > 0 && watchedCount == episodeCount {
+		to = "watched"
+	}
+	if watchedCount == 0 || !models.CanTransition(status, to) {
+		return nil
+	}
+
+	return transitionStatus(tx, watchlistID, to, at)
+}
@@ -0,0 +1,370 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// ErrTagExists is returned when a tag is created or renamed to a name already taken
+var ErrTagExists = errors.New("tag already exists")
+
+type TagModelInterface interface {
+	GetTags() ([]models.Tag, error)
+	GetTagById(tag_id string) (models.Tag, error)
+
+	AddTag(name string) (models.Tag, error)
+	RenameTag(tag_id string, name string) (models.Tag, error)
+	DeleteTag(tag_id string) (int, error)
+	MergeTags(tag_id string, source_ids []int) (models.Tag, error)
+
+	TagWatchLists(watchlist_ids []int, tags []string) (int, error)
+	UntagWatchLists(watchlist_ids []int, tags []string) (int, error)
+
+	As(actor models.Actor) TagModelInterface
+}
+
+type TagModel struct {
+	DB *sql.DB
+
+	// who makes the writes, set with As, the zero value is models.SystemActor
+	actor models.Actor
+}
+
+// As returns the model making its writes as actor, a write of the tags is a write of every entry it changes in the audit
+func (tagModel *TagModel) As(actor models.Actor) TagModelInterface {
+	model := *tagModel
+	model.actor = actor
+	return &model
+}
+
+const tagColumns = `tags.tag_id, tags.name,
+	(SELECT COUNT(*) FROM watchlist_tags WHERE watchlist_tags.tag_id = tags.tag_id
+	AND watchlist_tags.watchlist_id IN (SELECT watchlist_id FROM Watchlist WHERE deleted_at IS NULL))`
+
+// GetTags lists every tag with the number of entries tagged with it
+func (tagModel *TagModel) GetTags() ([]models.Tag, error) {
+	rows, err := tagModel.DB.Query(`SELECT ` + tagColumns + ` FROM tags ORDER BY tags.name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		tag := models.Tag{}
+		err := rows.Scan(&tag.TagID, &tag.Name, &tag.WatchListCount)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (tagModel *TagModel) GetTagById(tag_id string) (models.Tag, error) {
+	tag := models.Tag{}
+	err := tagModel.DB.QueryRow(`SELECT `+tagColumns+` FROM tags WHERE tags.tag_id = ?;`, tag_id).
+		Scan(&tag.TagID, &tag.Name, &tag.WatchListCount)
+	return tag, err
+}
+
+// AddTag creates a tag, ErrTagExists is returned when the name is taken
+func (tagModel *TagModel) AddTag(name string) (models.Tag, error) {
+	name = strings.TrimSpace(name)
+
+	result, err := tagModel.DB.Exec(`INSERT INTO tags (name) VALUES (?) ON CONFLICT(name) DO NOTHING;`, name)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if rowAffected == 0 {
+		return models.Tag{}, ErrTagExists
+	}
+
+	tagID, err := result.LastInsertId()
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return models.Tag{TagID: int(tagID), Name: name}, nil
+}
+
+// RenameTag changes the name of a tag, the case of a name can be changed
+// sql.ErrNoRows is returned when the tag does not exist and ErrTagExists when another tag has the name
+func (tagModel *TagModel) RenameTag(tag_id string, name string) (models.Tag, error) {
+	name = strings.TrimSpace(name)
+
+	tx, err := tagModel.DB.Begin()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+
+	var other int
+	err = tx.QueryRow(`SELECT tag_id FROM tags WHERE name = ? AND tag_id <> ?;`, name, tag_id).Scan(&other)
+	if err == nil {
+		return models.Tag{}, ErrTagExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.Tag{}, err
+	}
+
+	// the entries show the new name
+	audits, err := tagModel.beginTagAudits(tx, `SELECT watchlist_id FROM watchlist_tags WHERE tag_id = ?;`, tag_id)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	result, err := tx.Exec(`UPDATE tags SET name = ? WHERE tag_id = ?;`, name, tag_id)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if rowAffected == 0 {
+		return models.Tag{}, sql.ErrNoRows
+	}
+
+	err = touchAll(tx, audits)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return tagModel.GetTagById(tag_id)
+}
+
+// DeleteTag removes a tag from every entry and deletes it
+func (tagModel *TagModel) DeleteTag(tag_id string) (int, error) {
+	tx, err := tagModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	audits, err := tagModel.beginTagAudits(tx, `SELECT watchlist_id FROM watchlist_tags WHERE tag_id = ?;`, tag_id)
+	if err != nil {
+		return 0, err
+	}
+
+	// foreign keys are not enforced by default in SQLite, so the mapping is cleaned by hand
+	_, err = tx.Exec(`DELETE FROM watchlist_tags WHERE tag_id = ?;`, tag_id)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM tags WHERE tag_id = ?;`, tag_id)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	err = touchAll(tx, audits)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
+
+// MergeTags moves the entries of the source tags to the tag and deletes the sources
+// sql.ErrNoRows is returned when the tag does not exist, unknown sources are skipped
+func (tagModel *TagModel) MergeTags(tag_id string, source_ids []int) (models.Tag, error) {
+	tx, err := tagModel.DB.Begin()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+
+	var tagID int
+	err = tx.QueryRow(`SELECT tag_id FROM tags WHERE tag_id = ?;`, tag_id).Scan(&tagID)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	in, args := intPlaceholders(source_ids)
+
+	audits, err := tagModel.beginTagAudits(tx, `SELECT DISTINCT watchlist_id FROM watchlist_tags WHERE tag_id IN (`+in+`) AND tag_id <> ?;`, append(args, tagID)...)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO watchlist_tags (watchlist_id, tag_id)
+	SELECT watchlist_id, ? FROM watchlist_tags WHERE tag_id IN (`+in+`);`, append([]any{tagID}, args...)...)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	// the tag itself may be listed as a source, it is kept
+	_, err = tx.Exec(`DELETE FROM watchlist_tags WHERE tag_id IN (`+in+`) AND tag_id <> ?;`, append(args, tagID)...)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM tags WHERE tag_id IN (`+in+`) AND tag_id <> ?;`, append(args, tagID)...)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	err = touchAll(tx, audits)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return tagModel.GetTagById(tag_id)
+}
+
+// TagWatchLists adds the tags to the entries, missing tags are created and unknown entries are skipped
+// the number of tags added is returned, tags an entry already has are not counted
+func (tagModel *TagModel) TagWatchLists(watchlist_ids []int, tags []string) (int, error) {
+	tx, err := tagModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	in, args := intPlaceholders(watchlist_ids)
+
+	audits, err := tagModel.beginTagAudits(tx, `SELECT watchlist_id FROM Watchlist WHERE watchlist_id IN (`+in+`) AND deleted_at IS NULL;`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, name := range uniqueNames(tags) {
+		tagID, err := upsertName(tx, "tags", "tag_id", name)
+		if err != nil {
+			return 0, err
+		}
+
+		result, err := tx.Exec(`INSERT OR IGNORE INTO watchlist_tags (watchlist_id, tag_id)
+		SELECT watchlist_id, ? FROM Watchlist WHERE watchlist_id IN (`+in+`) AND deleted_at IS NULL;`, append([]any{tagID}, args...)...)
+		if err != nil {
+			return 0, err
+		}
+
+		rowAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		added += int(rowAffected)
+	}
+
+	err = touchAll(tx, audits)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
+
+// UntagWatchLists removes the tags from the entries, the tags themselves are kept
+func (tagModel *TagModel) UntagWatchLists(watchlist_ids []int, tags []string) (int, error) {
+	in, args := intPlaceholders(watchlist_ids)
+
+	names, nameArgs := stringPlaceholders(uniqueNames(tags))
+
+	tx, err := tagModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	audits, err := tagModel.beginTagAudits(tx, `SELECT watchlist_id FROM Watchlist WHERE watchlist_id IN (`+in+`);`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM watchlist_tags WHERE watchlist_id IN (`+in+`)
+	AND tag_id IN (SELECT tag_id FROM tags WHERE name IN (`+names+`));`, append(args, nameArgs...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	err = touchAll(tx, audits)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowAffected), nil
+}
+
+// beginTagAudits begins the audit of the entries selected by statement, before a write of their tags
+func (tagModel *TagModel) beginTagAudits(tx *sql.Tx, statement string, args ...any) ([]*watchListAudit, error) {
+	ids, err := watchListIds(tx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	return beginAudits(tx, tagModel.actor, "tag", ids)
+}
+
+// intPlaceholders returns the "?, ?" list and the arguments of an IN clause
+func intPlaceholders(ids []int) (string, []any) {
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// stringPlaceholders is intPlaceholders for strings
+func stringPlaceholders(values []string) (string, []any) {
+	placeholders := make([]string, len(values))
+	args := make([]any, len(values))
+	for i, value := range values {
+		placeholders[i] = "?"
+		args[i] = value
+	}
+	return strings.Join(placeholders, ", "), args
+}
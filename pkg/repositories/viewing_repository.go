@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+type ViewingModelInterface interface {
+	GetViewings(watchlist_id string) ([]models.Viewing, error)
+	GetDiary(from time.Time, to time.Time) ([]models.Viewing, error)
+
+	AddViewing(watchlist_id string, viewing models.ViewingRequest) (models.Viewing, error)
+
+	As(actor models.Actor) ViewingModelInterface
+}
+
+type ViewingModel struct {
+	DB *sql.DB
+
+	// who makes the writes, set with As, the zero value is models.SystemActor
+	actor models.Actor
+}
+
+// As returns the model making its writes as actor, a viewing is a write of its entry in the audit
+func (viewingModel *ViewingModel) As(actor models.Actor) ViewingModelInterface {
+	model := *viewingModel
+	model.actor = actor
+	return &model
+}
+
+// a viewing is a rewatch when an earlier viewing of the same entry exists
+const viewingColumns = `viewings.viewing_id, viewings.watchlist_id, Watchlist.title, viewings.watched_on, viewings.rating,
+	viewings.location, viewings.platform, viewings.notes, viewings.created_at,
+	EXISTS (SELECT 1 FROM viewings AS earlier WHERE earlier.watchlist_id = viewings.watchlist_id
+		AND (earlier.watched_on < viewings.watched_on OR (earlier.watched_on = viewings.watched_on AND earlier.viewing_id < viewings.viewing_id)))`
+
+func (viewingModel *ViewingModel) queryViewings(statement string, args ...any) ([]models.Viewing, error) {
+	rows, err := viewingModel.DB.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	viewings := []models.Viewing{}
+	for rows.Next() {
+		viewing := models.Viewing{}
+		var rating sql.NullFloat64
+
+		err := rows.Scan(
+			&viewing.ViewingID,
+			&viewing.WatchlistID,
+			&viewing.Title,
+			&viewing.WatchedOn,
+			&rating,
+			&viewing.Location,
+			&viewing.Platform,
+			&viewing.Notes,
+			&viewing.CreatedAt,
+			&viewing.Rewatch,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if rating.Valid {
+			viewing.Rating = &rating.Float64
+		}
+
+		viewings = append(viewings, viewing)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return viewings, nil
+}
+
+// GetViewings lists the viewings of an entry, oldest first
+func (viewingModel *ViewingModel) GetViewings(watchlist_id string) ([]models.Viewing, error) {
+	statement := `SELECT ` + viewingColumns + ` FROM viewings
+	JOIN Watchlist ON Watchlist.watchlist_id = viewings.watchlist_id
+	WHERE viewings.watchlist_id = ? AND Watchlist.deleted_at IS NULL ORDER BY viewings.watched_on, viewings.viewing_id;`
+
+	return viewingModel.queryViewings(statement, watchlist_id)
+}
+
+// GetDiary lists the viewings of every entry between from (inclusive) and to (exclusive), newest first
+// a zero time leaves that side of the range open
+func (viewingModel *ViewingModel) GetDiary(from time.Time, to time.Time) ([]models.Viewing, error) {
+	statement := `SELECT ` + viewingColumns + ` FROM viewings
+	JOIN Watchlist ON Watchlist.watchlist_id = viewings.watchlist_id
+	WHERE (? OR viewings.watched_on >= ?) AND (? OR viewings.watched_on < ?) AND Watchlist.deleted_at IS NULL
+	ORDER BY viewings.watched_on DESC, viewings.viewing_id DESC;`
+
+	return viewingModel.queryViewings(statement, from.IsZero(), from.UTC(), to.IsZero(), to.UTC())
+}
+
+// AddViewing logs a viewing and marks the entry as watched
+// sql.ErrNoRows is returned when the entry does not exist
+func (viewingModel *ViewingModel) AddViewing(watchlist_id string, viewing models.ViewingRequest) (models.Viewing, error) {
+	now := time.Now().UTC()
+	watchedOn := now
+	if viewing.WatchedOn != nil {
+		watchedOn = viewing.WatchedOn.UTC()
+	}
+
+	tx, err := viewingModel.DB.Begin()
+	if err != nil {
+		return models.Viewing{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Viewing{}, err
+	}
+
+	audit, err := beginAudit(tx, viewingModel.actor, "viewing", watchlistID)
+	if err != nil {
+		return models.Viewing{}, err
+	}
+
+	result, err := tx.Exec(`INSERT INTO viewings (watchlist_id, watched_on, rating, location, platform, notes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		watchlistID, watchedOn, viewing.Rating, viewing.Location, viewing.Platform, viewing.Notes, now)
+	if err != nil {
+		return models.Viewing{}, err
+	}
+
+	viewingID, err := result.LastInsertId()
+	if err != nil {
+		return models.Viewing{}, err
+	}
+
+	// a logged viewing means the entry has been watched, it is finished on the day of the viewing
+	err = transitionStatus(tx, watchlistID, "watched", watchedOn)
+	if err != nil {
+		return models.Viewing{}, err
+	}
+	err = audit.touch(tx)
+	if err != nil {
+		return models.Viewing{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Viewing{}, err
+	}
+
+	viewings, err := viewingModel.queryViewings(`SELECT `+viewingColumns+` FROM viewings
+	JOIN Watchlist ON Watchlist.watchlist_id = viewings.watchlist_id
+	WHERE viewings.viewing_id = ?;`, viewingID)
+	if err != nil {
+		return models.Viewing{}, err
+	}
+	if len(viewings) == 0 {
+		return models.Viewing{}, sql.ErrNoRows
+	}
+
+	return viewings[0], nil
+}
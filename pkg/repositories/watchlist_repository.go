@@ -2,91 +2,155 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/mattn/go-sqlite3"
 	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/query"
+	"github.com/saketV8/cine-dots/pkg/rank"
+	"github.com/saketV8/cine-dots/pkg/validation"
 )
 
 type WatchListModelInterface interface {
-	GetAllWatchList() ([]models.Watchlist, error)
-	GetWatchedList() ([]models.Watchlist, error)
-	GetWatchingList() ([]models.Watchlist, error)
-	GetNotWatchedList() ([]models.Watchlist, error)
+	GetAllWatchList(query models.WatchListQuery) ([]models.Watchlist, error)
+	GetWatchedList(query models.WatchListQuery) ([]models.Watchlist, error)
+	GetWatchingList(query models.WatchListQuery) ([]models.Watchlist, error)
+	GetNotWatchedList(query models.WatchListQuery) ([]models.Watchlist, error)
 	GetWatchListById(watchlist_id string) (models.Watchlist, error)
+	GetWatchListByExternalId(provider string, external_id string) (models.Watchlist, error)
+	GetTrash() ([]models.Watchlist, error)
 
 	AddWatchList(watchList models.Watchlist) (models.Watchlist, error)
 	DeleteWatchList(watchList models.WatchListDeleteRequest) (int, error)
 	UpdateWatchList(watchList models.WatchListUpdateRequest) (int, error)
+	TransitionWatchList(watchlist_id string, status string, at time.Time) (models.Watchlist, error)
+	UpdateProgress(watchlist_id string, progress models.ProgressRequest) (models.Watchlist, error)
+	MoveWatchList(watchlist_id string, move models.WatchListMoveRequest) (models.Watchlist, error)
+	BatchWatchList(writes []models.WatchListBatchWrite, atomic bool) ([]models.Watchlist, []error, error)
+	RestoreWatchList(watchlist_id string) (models.Watchlist, error)
+
+	GetWatchListVersions(watchlist_id string) ([]models.WatchListVersion, error)
+	DiffWatchListVersions(watchlist_id string, from int, to int) (models.WatchListVersionDiff, error)
+	RevertWatchList(watchlist_id string, version int) (models.Watchlist, error)
+	UndoWatchList(token string) ([]models.Watchlist, error)
+
+	As(actor models.Actor) WatchListModelInterface
 }
 
 type WatchListModel struct {
 	DB *sql.DB
+
+	// fraction of the runtime after which UpdateProgress marks an entry as watched
+	// 0 uses DefaultCompletionThreshold
+	CompletionThreshold float64
+
+	// most operations of a batch, 0 uses DefaultMaxBatchSize
+	MaxBatchSize int
+
+	// how long an undo token can be used, 0 uses DefaultUndoTTL
+	UndoTTL time.Duration
+
+	// who makes the writes, set with As, the zero value is models.SystemActor
+	actor models.Actor
 }
 
-func (watchListModel *WatchListModel) GetAllWatchList() ([]models.Watchlist, error) {
-	statement := `SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist;`
+// As returns the model making its writes as actor, each write is recorded with it in the audit
+func (watchListModel *WatchListModel) As(actor models.Actor) WatchListModelInterface {
+	model := *watchListModel
+	model.actor = actor
+	return &model
+}
 
-	rows, err := watchListModel.DB.Query(statement)
-	if err != nil {
-		return nil, err
-	}
+// DefaultCompletionThreshold is used when WatchListModel.CompletionThreshold is not set
+const DefaultCompletionThreshold = 0.9
 
-	// empty watchList model slice
-	watchLists := []models.Watchlist{}
-	for rows.Next() {
-		// empty watchList model
-		watchList := models.Watchlist{}
-
-		// setting data to the watchList model from the rows
-		err := rows.Scan(
-			&watchList.WatchlistID,
-			&watchList.Title,
-			&watchList.ReleaseYear,
-			&watchList.Genre,
-			&watchList.Director,
-			&watchList.Status,
-			&watchList.AddedDate,
-		)
-		if err != nil {
-			return nil, err
-		}
+// DefaultMaxBatchSize is used when WatchListModel.MaxBatchSize is not set
+const DefaultMaxBatchSize = 100
 
-		// adding watchList data to watchLists slice
-		watchLists = append(watchLists, watchList)
-	}
+// columns shared by every watchlist SELECT, external IDs live in the watchlist_external_ids side table
+const watchListColumns = `Watchlist.watchlist_id, Watchlist.title, Watchlist.release_year, Watchlist.genre, Watchlist.director, Watchlist.status, Watchlist.added_date,
+	Watchlist.started_at, Watchlist.finished_at, Watchlist.status_changed_at, Watchlist.kind,
+	IFNULL(Watchlist.runtime, 0), IFNULL(Watchlist.poster_path, ''), Watchlist.position_seconds, Watchlist.progress_updated_at, Watchlist.notes,
+	IFNULL(Watchlist.rank_key, ''), Watchlist.version, Watchlist.deleted_at,
+	(SELECT external_id FROM watchlist_external_ids WHERE watchlist_external_ids.watchlist_id = Watchlist.watchlist_id AND provider = 'imdb'),
+	(SELECT external_id FROM watchlist_external_ids WHERE watchlist_external_ids.watchlist_id = Watchlist.watchlist_id AND provider = 'tmdb'),
+	(SELECT external_id FROM watchlist_external_ids WHERE watchlist_external_ids.watchlist_id = Watchlist.watchlist_id AND provider = 'wikidata'),
+	` + averageRatingColumn + `,
+	(SELECT COUNT(*) FROM reviews WHERE reviews.watchlist_id = Watchlist.watchlist_id),
+	(SELECT COUNT(*) FROM viewings WHERE viewings.watchlist_id = Watchlist.watchlist_id)`
 
-	err = rows.Err()
+// averageRatingColumn is also used to filter and sort the lists by rating
+const averageRatingColumn = `(SELECT AVG(rating) FROM reviews WHERE reviews.watchlist_id = Watchlist.watchlist_id)`
+
+// scanWatchList reads one row selected with watchListColumns
+func scanWatchList(scanner interface{ Scan(dest ...any) error }) (models.Watchlist, error) {
+	// empty watchList model
+	watchList := models.Watchlist{}
+	var imdbID, tmdbID, wikidataID sql.NullString
+	var averageRating sql.NullFloat64
+
+	// setting data to the watchList model from the row
+	err := scanner.Scan(
+		&watchList.WatchlistID,
+		&watchList.Title,
+		&watchList.ReleaseYear,
+		&watchList.Genre,
+		&watchList.Director,
+		&watchList.Status,
+		&watchList.AddedDate,
+		&watchList.StartedAt,
+		&watchList.FinishedAt,
+		&watchList.StatusChangedAt,
+		&watchList.Kind,
+		&watchList.Runtime,
+		&watchList.PosterPath,
+		&watchList.PositionSeconds,
+		&watchList.ProgressUpdatedAt,
+		&watchList.Notes,
+		&watchList.Rank,
+		&watchList.Version,
+		&watchList.DeletedAt,
+		&imdbID,
+		&tmdbID,
+		&wikidataID,
+		&averageRating,
+		&watchList.RatingCount,
+		&watchList.ViewingCount,
+	)
 	if err != nil {
-		return nil, err
+		return watchList, err
 	}
 
-	return watchLists, nil
-}
+	watchList.ExternalIDs.IMDbID = imdbID.String
+	watchList.ExternalIDs.TMDbID, _ = strconv.Atoi(tmdbID.String)
+	watchList.ExternalIDs.WikidataID = wikidataID.String
+	if averageRating.Valid {
+		watchList.AverageRating = &averageRating.Float64
+	}
+	if watchList.ViewingCount > 1 {
+		watchList.RewatchCount = watchList.ViewingCount - 1
+	}
 
-func (watchListModel *WatchListModel) GetWatchedList() ([]models.Watchlist, error) {
-	statement := `SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist WHERE status = "watched";`
+	return watchList, nil
+}
 
-	rows, err := watchListModel.DB.Query(statement)
+// queryWatchLists runs a SELECT of watchListColumns and collects every row
+func (watchListModel *WatchListModel) queryWatchLists(statement string, args ...any) ([]models.Watchlist, error) {
+	rows, err := watchListModel.DB.Query(statement, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	// empty watchList model slice
 	watchLists := []models.Watchlist{}
 	for rows.Next() {
-		// empty watchList model
-		watchList := models.Watchlist{}
-
-		// setting data to the watchList model from the rows
-		err := rows.Scan(
-			&watchList.WatchlistID,
-			&watchList.Title,
-			&watchList.ReleaseYear,
-			&watchList.Genre,
-			&watchList.Director,
-			&watchList.Status,
-			&watchList.AddedDate,
-		)
+		watchList, err := scanWatchList(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -100,166 +164,1711 @@ func (watchListModel *WatchListModel) GetWatchedList() ([]models.Watchlist, erro
 		return nil, err
 	}
 
+	err = loadRelations(watchListModel.DB, watchLists)
+	if err != nil {
+		return nil, err
+	}
+
 	return watchLists, nil
 }
 
-func (watchListModel *WatchListModel) GetWatchingList() ([]models.Watchlist, error) {
-	statement := `SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist WHERE status = "watching";`
+// queryWatchList runs a SELECT of watchListColumns which returns a single row
+func (watchListModel *WatchListModel) queryWatchList(statement string, args ...any) (models.Watchlist, error) {
+	watchList, err := scanWatchList(watchListModel.DB.QueryRow(statement, args...))
+	if err != nil {
+		return watchList, err
+	}
 
-	rows, err := watchListModel.DB.Query(statement)
+	watchLists := []models.Watchlist{watchList}
+	err = loadRelations(watchListModel.DB, watchLists)
 	if err != nil {
-		return nil, err
+		return watchList, err
 	}
 
-	// empty watchList model slice
-	watchLists := []models.Watchlist{}
-	for rows.Next() {
-		// empty watchList model
-		watchList := models.Watchlist{}
-
-		// setting data to the watchList model from the rows
-		err := rows.Scan(
-			&watchList.WatchlistID,
-			&watchList.Title,
-			&watchList.ReleaseYear,
-			&watchList.Genre,
-			&watchList.Director,
-			&watchList.Status,
-			&watchList.AddedDate,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return watchLists[0], nil
+}
 
-		// adding watchList data to watchLists slice
-		watchLists = append(watchLists, watchList)
+// listWatchLists selects the entries matching the status, an empty status matches every entry
+// the sort column comes from the whitelist of WatchListQuery, never from raw input
+func (watchListModel *WatchListModel) listWatchLists(status string, listQuery models.WatchListQuery) ([]models.Watchlist, error) {
+	statement := `SELECT ` + watchListColumns + ` FROM Watchlist WHERE Watchlist.deleted_at IS NULL AND (? = '' OR status = ?)`
+	args := []any{status, status}
+
+	if listQuery.MinRating > 0 {
+		statement += ` AND ` + averageRatingColumn + ` >= ?`
+		args = append(args, listQuery.MinRating)
+	}
+	if listQuery.MaxRating > 0 {
+		statement += ` AND ` + averageRatingColumn + ` <= ?`
+		args = append(args, listQuery.MaxRating)
 	}
 
-	err = rows.Err()
+	if tags := splitNames(listQuery.Tags); len(tags) > 0 {
+		condition, tagArgs := tagCondition(tags, listQuery.TagMatch)
+		statement += ` AND ` + condition
+		args = append(args, tagArgs...)
+	}
+
+	condition, queryArgs, err := CompileWatchListQuery(listQuery.Q)
 	if err != nil {
 		return nil, err
 	}
+	if condition != "" {
+		statement += ` AND ` + condition
+		args = append(args, queryArgs...)
+	}
 
-	return watchLists, nil
+	statement += ` ORDER BY ` + watchListOrderBy(listQuery.Sort) + `;`
+
+	return watchListModel.queryWatchLists(statement, args...)
 }
 
-func (watchListModel *WatchListModel) GetNotWatchedList() ([]models.Watchlist, error) {
-	statement := `SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist WHERE status = "not watched";`
+// watchListQuerySchema maps the fields of the query language to the columns of Watchlist
+// genre, tag and person match whole names case-insensitively through the NOCASE collation of the name columns
+var watchListQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"title":    {Kind: query.TextField, Column: `Watchlist.title`},
+		"director": {Kind: query.TextField, Column: `Watchlist.director`},
+		"notes":    {Kind: query.TextField, Column: `Watchlist.notes`},
+		"status":   {Kind: query.EnumField, Column: `Watchlist.status`, Values: []string{"not watched", "watching", "watched", "on hold", "dropped"}},
+		"kind":     {Kind: query.EnumField, Column: `Watchlist.kind`, Values: models.Kinds},
+		"year":     {Kind: query.NumberField, Column: `Watchlist.release_year`},
+		"runtime":  {Kind: query.NumberField, Column: `Watchlist.runtime`},
+		"rating":   {Kind: query.NumberField, Column: averageRatingColumn},
+		"genre": {Kind: query.MatchField, Condition: `Watchlist.watchlist_id IN (SELECT watchlist_genres.watchlist_id FROM watchlist_genres
+		JOIN genres ON genres.genre_id = watchlist_genres.genre_id WHERE genres.name = ?)`},
+		"tag": {Kind: query.MatchField, Condition: `Watchlist.watchlist_id IN (SELECT watchlist_tags.watchlist_id FROM watchlist_tags
+		JOIN tags ON tags.tag_id = watchlist_tags.tag_id WHERE tags.name = ?)`},
+		"person": {Kind: query.MatchField, Condition: `Watchlist.watchlist_id IN (SELECT watchlist_credits.watchlist_id FROM watchlist_credits
+		JOIN people ON people.person_id = watchlist_credits.person_id WHERE people.name = ?)`},
+	},
+	Text: []string{`Watchlist.title`, `Watchlist.director`},
+}
 
-	rows, err := watchListModel.DB.Query(statement)
-	if err != nil {
-		return nil, err
+// CompileWatchListQuery compiles a query of the list endpoints into a condition on Watchlist
+// an empty query compiles to an empty condition, errors are *query.Error with the column at fault
+func CompileWatchListQuery(q string) (string, []any, error) {
+	node, err := query.Parse(q)
+	if err != nil || node == nil {
+		return "", nil, err
 	}
+	return query.Compile(node, watchListQuerySchema)
+}
 
-	// empty watchList model slice
-	watchLists := []models.Watchlist{}
-	for rows.Next() {
-		// empty watchList model
-		watchList := models.Watchlist{}
-
-		// setting data to the watchList model from the rows
-		err := rows.Scan(
-			&watchList.WatchlistID,
-			&watchList.Title,
-			&watchList.ReleaseYear,
-			&watchList.Genre,
-			&watchList.Director,
-			&watchList.Status,
-			&watchList.AddedDate,
-		)
-		if err != nil {
-			return nil, err
-		}
+// tagCondition matches the entries with any of the tags, or all of them when match is all
+// tag names compare case-insensitively through the NOCASE collation of tags.name
+func tagCondition(tags []string, match string) (string, []any) {
+	in, args := stringPlaceholders(tags)
 
-		// adding watchList data to watchLists slice
-		watchLists = append(watchLists, watchList)
+	condition := `Watchlist.watchlist_id IN (SELECT watchlist_tags.watchlist_id FROM watchlist_tags
+	JOIN tags ON tags.tag_id = watchlist_tags.tag_id WHERE tags.name IN (` + in + `)
+	GROUP BY watchlist_tags.watchlist_id`
+	if match == "all" {
+		condition += ` HAVING COUNT(DISTINCT watchlist_tags.tag_id) = ?`
+		args = append(args, len(tags))
 	}
+	return condition + `)`, args
+}
 
-	err = rows.Err()
-	if err != nil {
-		return nil, err
+// watchListOrderBy maps a sort value to an ORDER BY clause, unrated entries always come last
+func watchListOrderBy(sort string) string {
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		sort = strings.TrimPrefix(sort, "-")
 	}
 
-	return watchLists, nil
+	switch sort {
+	case "rating":
+		return averageRatingColumn + ` IS NULL, ` + averageRatingColumn + ` ` + direction + `, Watchlist.watchlist_id`
+	case "title":
+		return `Watchlist.title COLLATE NOCASE ` + direction + `, Watchlist.watchlist_id`
+	case "release_year":
+		return `Watchlist.release_year ` + direction + `, Watchlist.watchlist_id`
+	case "added_date":
+		return `Watchlist.added_date ` + direction + `, Watchlist.watchlist_id`
+	case "progress_updated_at":
+		return `Watchlist.progress_updated_at IS NULL, Watchlist.progress_updated_at ` + direction + `, Watchlist.watchlist_id`
+	case "rank":
+		// entries added before the ranks existed come last, in the order they will be ranked
+		return `Watchlist.rank_key IS NULL, Watchlist.rank_key ` + direction + `, Watchlist.watchlist_id`
+	}
+	return `Watchlist.watchlist_id`
+}
+
+func (watchListModel *WatchListModel) GetAllWatchList(query models.WatchListQuery) ([]models.Watchlist, error) {
+	return watchListModel.listWatchLists("", query)
+}
+
+func (watchListModel *WatchListModel) GetWatchedList(query models.WatchListQuery) ([]models.Watchlist, error) {
+	return watchListModel.listWatchLists("watched", query)
 }
 
-// GetWatchListById
+func (watchListModel *WatchListModel) GetWatchingList(query models.WatchListQuery) ([]models.Watchlist, error) {
+	return watchListModel.listWatchLists("watching", query)
+}
+
+func (watchListModel *WatchListModel) GetNotWatchedList(query models.WatchListQuery) ([]models.Watchlist, error) {
+	return watchListModel.listWatchLists("not watched", query)
+}
+
+// GetWatchListById, an entry in the trash is not found
 func (watchListModel *WatchListModel) GetWatchListById(watchlist_id string) (models.Watchlist, error) {
-	statement := `SELECT watchlist_id, title, release_year, genre, director, status, added_date FROM Watchlist WHERE watchlist_id = ?;`
-	// empty watchList model
-	watchList := models.Watchlist{}
+	statement := `SELECT ` + watchListColumns + ` FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`
 
-	err := watchListModel.DB.QueryRow(statement, watchlist_id).Scan(
-		&watchList.WatchlistID,
-		&watchList.Title,
-		&watchList.ReleaseYear,
-		&watchList.Genre,
-		&watchList.Director,
-		&watchList.Status,
-		&watchList.AddedDate,
-	)
+	return watchListModel.queryWatchList(statement, watchlist_id)
+}
+
+// GetWatchListByExternalId finds the entry linked to an IMDb, TMDb or Wikidata ID
+func (watchListModel *WatchListModel) GetWatchListByExternalId(provider string, external_id string) (models.Watchlist, error) {
+	statement := `SELECT ` + watchListColumns + ` FROM Watchlist
+	JOIN watchlist_external_ids AS external ON external.watchlist_id = Watchlist.watchlist_id
+	WHERE external.provider = ? AND external.external_id = ? AND Watchlist.deleted_at IS NULL;`
+
+	return watchListModel.queryWatchList(statement, provider, external_id)
+}
+
+// FindDuplicateWatchList looks for an entry which is the same title as the given one
+// a shared external ID is a duplicate, otherwise titles are compared case-insensitively
+// with the same release year (or an unknown one) so remakes are not duplicates
+func (watchListModel *WatchListModel) FindDuplicateWatchList(title string, releaseYear int, externalIDs models.ExternalIDs) (models.Watchlist, bool, error) {
+	for provider, externalID := range externalIDValues(externalIDs) {
+		watchList, err := watchListModel.GetWatchListByExternalId(provider, externalID)
+		if err == nil {
+			return watchList, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return watchList, false, err
+		}
+	}
+
+	statement := `SELECT ` + watchListColumns + ` FROM Watchlist
+	WHERE LOWER(TRIM(title)) = LOWER(TRIM(?)) AND (? = 0 OR IFNULL(release_year, 0) IN (0, ?)) AND deleted_at IS NULL LIMIT 1;`
+
+	watchList, err := scanWatchList(watchListModel.DB.QueryRow(statement, title, releaseYear, releaseYear))
+	if errors.Is(err, sql.ErrNoRows) {
+		return watchList, false, nil
+	}
 	if err != nil {
-		return watchList, err
+		return watchList, false, err
 	}
 
-	return watchList, nil
+	return watchList, true, nil
 }
 
 func (watchListModel *WatchListModel) AddWatchList(watchList models.Watchlist) (models.Watchlist, error) {
-	statement := `INSERT INTO Watchlist (title, release_year, genre, director, status) VALUES (?, ?, ?, ?, ?);`
-
-	watchListResult := models.Watchlist{}
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	defer tx.Rollback()
 
-	result, err := watchListModel.DB.Exec(statement, watchList.Title, watchList.ReleaseYear, watchList.Genre, watchList.Director, watchList.Status)
+	statements, err := prepareWatchListStatements(tx, watchListModel.actor)
 	if err != nil {
 		return models.Watchlist{}, err
 	}
+	defer statements.Close()
 
-	lastInsertedId, err := result.LastInsertId()
+	watchListResult, err := statements.addWatchList(tx, watchList)
 	if err != nil {
 		return models.Watchlist{}, err
 	}
 
-	// adding Id to model inserted autmatically by sqlite before returning to end user
-	watchListResult.WatchlistID = int(lastInsertedId)
-	watchListResult.Title = watchList.Title
-	watchListResult.ReleaseYear = watchList.ReleaseYear
-	watchListResult.Genre = watchList.Genre
-	watchListResult.Director = watchList.Director
-	watchListResult.Status = watchList.Status
-	watchListResult.AddedDate = watchList.AddedDate
+	err = tx.Commit()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
 
 	return watchListResult, nil
 }
 
 func (watchListModel *WatchListModel) DeleteWatchList(watchList models.WatchListDeleteRequest) (int, error) {
-	statement := `DELETE FROM Watchlist WHERE watchlist_id = ?;`
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	result, err := watchListModel.DB.Exec(statement, watchList.WatchlistID)
+	statements, err := prepareWatchListStatements(tx, watchListModel.actor)
 	if err != nil {
 		return 0, err
 	}
+	defer statements.Close()
 
-	rowAffected, err := result.RowsAffected()
+	rowAffected, err := statements.deleteWatchList(tx, watchList)
 	if err != nil {
 		return 0, err
 	}
 
-	return int(rowAffected), nil
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowAffected, nil
 }
 
 func (watchListModel *WatchListModel) UpdateWatchList(watchList models.WatchListUpdateRequest) (int, error) {
-	statement := `UPDATE Watchlist SET title = ?, release_year = ?, genre = ?, director = ?, status = ?, added_date = ? WHERE watchlist_id = ?;`
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	result, err := watchListModel.DB.Exec(statement, watchList.Title, watchList.ReleaseYear, watchList.Genre, watchList.Director, watchList.Status, time.Now(), watchList.WatchlistID)
+	statements, err := prepareWatchListStatements(tx, watchListModel.actor)
 	if err != nil {
 		return 0, err
 	}
+	defer statements.Close()
 
-	rowAffected, err := result.RowsAffected()
+	rowAffected, err := statements.updateWatchList(tx, watchList)
 	if err != nil {
 		return 0, err
 	}
 
-	return int(rowAffected), nil
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowAffected, nil
+}
+
+// TransitionWatchList moves an entry to another status of the lifecycle
+// sql.ErrNoRows is returned when the entry does not exist and a *StatusTransitionError when the move is not allowed
+func (watchListModel *WatchListModel) TransitionWatchList(watchlist_id string, status string, at time.Time) (models.Watchlist, error) {
+	if !models.IsValidStatus(status) {
+		return models.Watchlist{}, ErrInvalidStatus
+	}
+
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	audit, err := beginAudit(tx, watchListModel.actor, "transition", watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = transitionStatus(tx, watchlistID, status, at.UTC())
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	err = touchWatchList(tx, watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = audit.record(tx)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	return watchListModel.GetWatchListById(watchlist_id)
+}
+
+// UpdateProgress stores where playback of an entry stopped
+// positions before the stored one are ignored unless progress.Reset is set, so replaying an update changes nothing
+// a position is watching, and past the completion threshold of the runtime it is watched
+// sql.ErrNoRows is returned when the entry does not exist
+func (watchListModel *WatchListModel) UpdateProgress(watchlist_id string, progress models.ProgressRequest) (models.Watchlist, error) {
+	now := time.Now().UTC()
+	position := *progress.PositionSeconds
+
+	threshold := watchListModel.CompletionThreshold
+	if threshold <= 0 {
+		threshold = DefaultCompletionThreshold
+	}
+
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID, storedPosition, runtime int
+	var status string
+	err = tx.QueryRow(`SELECT watchlist_id, position_seconds, IFNULL(runtime, 0), status FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).
+		Scan(&watchlistID, &storedPosition, &runtime, &status)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	audit, err := beginAudit(tx, watchListModel.actor, "progress", watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	changed := false
+	if progress.Runtime > 0 && progress.Runtime != runtime {
+		runtime = progress.Runtime
+		changed = true
+		_, err = tx.Exec(`UPDATE Watchlist SET runtime = ? WHERE watchlist_id = ?;`, runtime, watchlistID)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+	}
+
+	if position > storedPosition || (progress.Reset && position != storedPosition) {
+		changed = true
+		_, err = tx.Exec(`UPDATE Watchlist SET position_seconds = ?, progress_updated_at = ? WHERE watchlist_id = ?;`, position, now, watchlistID)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+
+		// a watched entry only starts again on a reset, late updates of the end credits keep it watched
+		to := ""
+		switch {
+		case runtime > 0 && float64(position) >= float64(runtime*60)*threshold:
+			to = "watched"
+		case position > 0 && (status != "watched" || progress.Reset):
+			to = "watching"
+		}
+		if to != "" && models.CanTransition(status, to) {
+			err = transitionStatus(tx, watchlistID, to, now)
+			if err != nil {
+				return models.Watchlist{}, err
+			}
+		}
+	}
+
+	// a replayed update changes nothing, so the version stays
+	if changed {
+		err = touchWatchList(tx, watchlistID)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+
+		err = audit.record(tx)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	return watchListModel.GetWatchListById(watchlist_id)
+}
+
+// Writes
+// =====================================================================================
+
+const (
+	insertWatchListStatement = `INSERT INTO Watchlist (title, release_year, genre, director, status, added_date, started_at, finished_at, status_changed_at, kind, runtime, poster_path, notes) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, ''), ?);`
+
+	// the status goes through the lifecycle like POST /watchlist/{id}/transition
+	updateWatchListStatement = `UPDATE Watchlist SET title = ?, release_year = ?, genre = ?, director = ?, added_date = COALESCE(?, added_date), kind = COALESCE(NULLIF(?, ''), kind), runtime = COALESCE(NULLIF(?, 0), runtime), poster_path = COALESCE(NULLIF(?, ''), poster_path), notes = COALESCE(?, notes), version = version + 1 WHERE watchlist_id = ? AND deleted_at IS NULL;`
+
+	// a deleted entry goes to the trash, it leaves the manual order and gets a new place when it is restored
+	deleteWatchListStatement = `UPDATE Watchlist SET deleted_at = ?, rank_key = NULL, version = version + 1 WHERE watchlist_id = ? AND deleted_at IS NULL;`
+)
+
+// watchListStatements are the writes of an entry prepared in a transaction, a batch runs them for each of its operations
+type watchListStatements struct {
+	insert *sql.Stmt
+	update *sql.Stmt
+	delete *sql.Stmt
+
+	// the writes are audited as actor
+	actor models.Actor
+}
+
+func prepareWatchListStatements(tx *sql.Tx, actor models.Actor) (*watchListStatements, error) {
+	statements := &watchListStatements{actor: actor}
+
+	var err error
+	statements.insert, err = tx.Prepare(insertWatchListStatement)
+	if err == nil {
+		statements.update, err = tx.Prepare(updateWatchListStatement)
+	}
+	if err == nil {
+		statements.delete, err = tx.Prepare(deleteWatchListStatement)
+	}
+	if err != nil {
+		statements.Close()
+		return nil, err
+	}
+	return statements, nil
+}
+
+func (statements *watchListStatements) Close() {
+	for _, statement := range []*sql.Stmt{statements.insert, statements.update, statements.delete} {
+		if statement != nil {
+			statement.Close()
+		}
+	}
+}
+
+// addWatchList validates and inserts an entry with its relations
+func (statements *watchListStatements) addWatchList(tx *sql.Tx, watchList models.Watchlist) (models.Watchlist, error) {
+	watchListResult := models.Watchlist{}
+
+	if !models.IsValidStatus(watchList.Status) {
+		return models.Watchlist{}, ErrInvalidStatus
+	}
+	// the importer has no added_date, genre nor director
+	err := validation.Except(watchList, "AddedDate", "Genre", "Director")
+	if err != nil {
+		return models.Watchlist{}, fmt.Errorf("%w: %w", ErrInvalidWatchList, err)
+	}
+
+	kind := watchList.Kind
+	if kind == "" {
+		kind = "movie"
+	}
+
+	// an entry added as watched has been started and finished now
+	now := time.Now().UTC()
+	var startedAt, finishedAt *time.Time
+	if watchList.Status != "not watched" {
+		startedAt = &now
+	}
+	if watchList.Status == "watched" {
+		finishedAt = &now
+	}
+
+	// without an added_date the entry is added today like the default of the column
+	addedDate := watchList.AddedDate.UTC()
+	if watchList.AddedDate.IsZero() {
+		addedDate = now.Truncate(24 * time.Hour)
+	}
+
+	// the legacy genre and director columns are kept in sync with the normalized tables
+	genres := normalizeGenres(watchList.Genre, watchList.Genres)
+	credits := normalizeCredits(watchList.Director, watchList.Credits)
+
+	result, err := statements.insert.Exec(watchList.Title, watchList.ReleaseYear, strings.Join(genres, ", "), directorNames(credits), watchList.Status, addedDate, startedAt, finishedAt, now, kind, watchList.Runtime, watchList.PosterPath, watchList.Notes)
+	if err != nil {
+		return models.Watchlist{}, watchListConflict(err)
+	}
+
+	lastInsertedId, err := result.LastInsertId()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	// new entries go to the end of the manual order
+	rankKey, err := rankUnranked(tx)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = saveExternalIDs(tx, int(lastInsertedId), watchList.ExternalIDs)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = saveGenres(tx, int(lastInsertedId), genres)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	credits, err = saveCredits(tx, int(lastInsertedId), credits, true)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	tags := uniqueNames(watchList.Tags)
+	err = saveTags(tx, int(lastInsertedId), tags)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	audit := &watchListAudit{actor: statements.actor, action: "create", watchlistID: int(lastInsertedId)}
+	err = audit.record(tx)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	// adding Id to model inserted autmatically by sqlite before returning to end user
+	watchListResult.WatchlistID = int(lastInsertedId)
+	watchListResult.Title = watchList.Title
+	watchListResult.ReleaseYear = watchList.ReleaseYear
+	watchListResult.Genre = strings.Join(genres, ", ")
+	watchListResult.Director = directorNames(credits)
+	watchListResult.Status = watchList.Status
+	watchListResult.AddedDate = addedDate
+	watchListResult.ExternalIDs = watchList.ExternalIDs
+	watchListResult.Genres = genres
+	watchListResult.Credits = credits
+	watchListResult.StartedAt = startedAt
+	watchListResult.FinishedAt = finishedAt
+	watchListResult.StatusChangedAt = &now
+	watchListResult.Kind = kind
+	watchListResult.Runtime = watchList.Runtime
+	watchListResult.PosterPath = watchList.PosterPath
+	watchListResult.Tags = tags
+	watchListResult.Notes = watchList.Notes
+	watchListResult.Rank = rankKey
+	watchListResult.Version = 1
+	if models.IsEpisodic(kind) {
+		watchListResult.Progress = &models.SeriesProgress{}
+	}
+
+	return watchListResult, nil
+}
+
+// deleteWatchList moves an entry to the trash, it returns 0 when the entry does not exist or is already in the trash
+// its relations are kept for a restore, only its places in the lists are lost
+func (statements *watchListStatements) deleteWatchList(tx *sql.Tx, watchList models.WatchListDeleteRequest) (int, error) {
+	err := checkVersion(tx, watchList.WatchlistID, watchList.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	audit, err := beginAudit(tx, statements.actor, "delete", watchList.WatchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := statements.delete.Exec(time.Now().UTC(), watchList.WatchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowAffected > 0 {
+		err = removeFromLists(tx, watchList.WatchlistID)
+		if err != nil {
+			return 0, err
+		}
+
+		err = audit.record(tx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int(rowAffected), nil
+}
+
+// updateWatchList validates and replaces an entry with its relations, it returns 0 when the entry does not exist
+func (statements *watchListStatements) updateWatchList(tx *sql.Tx, watchList models.WatchListUpdateRequest) (int, error) {
+	if !models.IsValidStatus(watchList.Status) {
+		return 0, ErrInvalidStatus
+	}
+	err := validation.Except(watchList, "Genre", "Director")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidWatchList, err)
+	}
+
+	genres := normalizeGenres(watchList.Genre, watchList.Genres)
+	credits := normalizeCredits(watchList.Director, watchList.Credits)
+
+	err = checkVersion(tx, watchList.WatchlistID, watchList.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	audit, err := beginAudit(tx, statements.actor, "update", watchList.WatchlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := statements.update.Exec(watchList.Title, watchList.ReleaseYear, strings.Join(genres, ", "), directorNames(credits), watchList.AddedDate, watchList.Kind, watchList.Runtime, watchList.PosterPath, watchList.Notes, watchList.WatchlistID)
+	if err != nil {
+		return 0, watchListConflict(err)
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rowAffected > 0 {
+		err = transitionStatus(tx, watchList.WatchlistID, watchList.Status, time.Now().UTC())
+		if err != nil {
+			return 0, err
+		}
+
+		// only the IDs sent in the request are replaced
+		if watchList.ExternalIDs != nil {
+			err = saveExternalIDs(tx, watchList.WatchlistID, *watchList.ExternalIDs)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = saveGenres(tx, watchList.WatchlistID, genres)
+		if err != nil {
+			return 0, err
+		}
+
+		// the legacy director field only replaces the director credits
+		_, err = saveCredits(tx, watchList.WatchlistID, credits, watchList.Credits != nil)
+		if err != nil {
+			return 0, err
+		}
+
+		if watchList.Tags != nil {
+			err = saveTags(tx, watchList.WatchlistID, uniqueNames(watchList.Tags))
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = audit.record(tx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int(rowAffected), nil
+}
+
+// Batch
+// =====================================================================================
+
+// ErrInvalidBatch is returned for a batch without operations or with more than the max batch size
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// BatchError is the operation which failed an atomic batch, nothing of the batch was written
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (batchError *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %v", batchError.Index, batchError.Err)
+}
+
+func (batchError *BatchError) Unwrap() error {
+	return batchError.Err
+}
+
+// BatchWatchList runs the writes in one transaction with the statements prepared once
+// atomic writes all or nothing: the first failing write rolls the batch back and is returned as a *BatchError
+// otherwise each write runs in a savepoint, a failing one is rolled back alone and its error is errs[i]
+// watchLists[i] is the created or updated entry, a missing entry is sql.ErrNoRows
+func (watchListModel *WatchListModel) BatchWatchList(writes []models.WatchListBatchWrite, atomic bool) ([]models.Watchlist, []error, error) {
+	maxBatchSize := watchListModel.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+	if len(writes) == 0 || len(writes) > maxBatchSize {
+		return nil, nil, fmt.Errorf("%w: a batch has 1 to %d operations", ErrInvalidBatch, maxBatchSize)
+	}
+
+	// an atomic batch with an operation which could not be read does not start
+	if atomic {
+		for i, write := range writes {
+			if write.Err != nil {
+				return nil, nil, &BatchError{Index: i, Err: write.Err}
+			}
+		}
+	}
+
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	statements, err := prepareWatchListStatements(tx, watchListModel.actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer statements.Close()
+
+	watchLists := make([]models.Watchlist, len(writes))
+	errs := make([]error, len(writes))
+
+	for i, write := range writes {
+		if write.Err != nil {
+			errs[i] = write.Err
+			continue
+		}
+
+		if atomic {
+			watchLists[i], err = statements.batchWrite(tx, write)
+			if err != nil {
+				return nil, nil, &BatchError{Index: i, Err: err}
+			}
+			continue
+		}
+
+		_, err = tx.Exec(`SAVEPOINT batch_write;`)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		watchLists[i], errs[i] = statements.batchWrite(tx, write)
+		if errs[i] != nil {
+			_, err = tx.Exec(`ROLLBACK TO batch_write;`)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		_, err = tx.Exec(`RELEASE batch_write;`)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the updated entries are read once the batch is written
+	for i, write := range writes {
+		if write.Op != "update" || errs[i] != nil {
+			continue
+		}
+		watchLists[i], err = watchListModel.GetWatchListById(strconv.Itoa(write.Update.WatchlistID))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return watchLists, errs, nil
+}
+
+// batchWrite runs a write of a batch, an update or delete of a missing entry is sql.ErrNoRows
+func (statements *watchListStatements) batchWrite(tx *sql.Tx, write models.WatchListBatchWrite) (models.Watchlist, error) {
+	var rowAffected int
+	var err error
+
+	switch write.Op {
+	case "create":
+		return statements.addWatchList(tx, write.Create)
+	case "update":
+		rowAffected, err = statements.updateWatchList(tx, write.Update)
+	case "delete":
+		rowAffected, err = statements.deleteWatchList(tx, write.Delete)
+	default:
+		return models.Watchlist{}, fmt.Errorf("%w: unknown operation %q", ErrInvalidBatch, write.Op)
+	}
+
+	if err == nil && rowAffected == 0 {
+		err = sql.ErrNoRows
+	}
+	return models.Watchlist{}, err
+}
+
+// Trash
+// =====================================================================================
+
+// GetTrash lists the entries in the trash, the last deleted first
+func (watchListModel *WatchListModel) GetTrash() ([]models.Watchlist, error) {
+	statement := `SELECT ` + watchListColumns + ` FROM Watchlist WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, watchlist_id;`
+
+	return watchListModel.queryWatchLists(statement)
+}
+
+// RestoreWatchList takes an entry out of the trash, it goes to the end of the manual order
+// sql.ErrNoRows is returned when the entry is not in the trash
+// and ErrWatchListExists when the title and year were added again in the meantime
+func (watchListModel *WatchListModel) RestoreWatchList(watchlist_id string) (models.Watchlist, error) {
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NOT NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = restoreWatchList(tx, watchListModel.actor, watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	return watchListModel.GetWatchListById(watchlist_id)
+}
+
+// restoreWatchList takes an entry which is in the trash out of it
+func restoreWatchList(tx *sql.Tx, actor models.Actor, watchlistID int) error {
+	audit, err := beginAudit(tx, actor, "restore", watchlistID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE Watchlist SET deleted_at = NULL, version = version + 1 WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return watchListConflict(err)
+	}
+
+	_, err = rankUnranked(tx)
+	if err != nil {
+		return err
+	}
+
+	return audit.record(tx)
+}
+
+// PurgeTrash deletes the entries which are in the trash for longer than retention, with their relations
+// it returns how many entries were purged
+func (watchListModel *WatchListModel) PurgeTrash(retention time.Duration) (int, error) {
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ids, err := watchListIds(tx, `SELECT watchlist_id FROM Watchlist WHERE deleted_at IS NOT NULL AND deleted_at <= ? ORDER BY watchlist_id;`, time.Now().UTC().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		audit, err := beginAudit(tx, watchListModel.actor, "purge", id)
+		if err != nil {
+			return 0, err
+		}
+
+		err = purgeWatchList(tx, id)
+		if err != nil {
+			return 0, err
+		}
+
+		err = audit.record(tx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// purgeWatchList deletes an entry for good
+func purgeWatchList(tx *sql.Tx, watchlistID int) error {
+	// foreign keys are not enforced by default in SQLite, so the side table is cleaned by hand
+	_, err := tx.Exec(`DELETE FROM episodes WHERE season_id IN (SELECT season_id FROM seasons WHERE watchlist_id = ?);`, watchlistID)
+	if err != nil {
+		return err
+	}
+	for _, sideTable := range []string{"watchlist_external_ids", "watchlist_genres", "watchlist_credits", "review_revisions", "reviews", "viewings", "seasons", "watchlist_tags", "watchlist_versions"} {
+		_, err = tx.Exec(`DELETE FROM `+sideTable+` WHERE watchlist_id = ?;`, watchlistID)
+		if err != nil {
+			return err
+		}
+	}
+	err = removeFromLists(tx, watchlistID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM Watchlist WHERE watchlist_id = ?;`, watchlistID)
+	return err
+}
+
+// Manual order
+// =====================================================================================
+
+// ErrInvalidMove is returned when an entry is moved next to itself
+var ErrInvalidMove = errors.New("an entry can not be moved next to itself")
+
+// ErrRankConflict is returned when the anchors of a move are not next to each other,
+// or kept changing while the move was retried
+var ErrRankConflict = errors.New("the order changed, reload it and move again")
+
+// moveAttempts is the number of times a move is retried when a concurrent move changed its anchors
+const moveAttempts = 5
+
+// MoveWatchList moves an entry right before move.Before or right after move.After, or between both
+// only the key of the moved entry is updated, and only if no other entry took the place in the meantime
+// sql.ErrNoRows is returned when the entry or an anchor does not exist
+func (watchListModel *WatchListModel) MoveWatchList(watchlist_id string, move models.WatchListMoveRequest) (models.Watchlist, error) {
+	var watchlistID int
+	err := watchListModel.DB.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	if (move.Before != nil && *move.Before == watchlistID) || (move.After != nil && *move.After == watchlistID) {
+		return models.Watchlist{}, ErrInvalidMove
+	}
+
+	err = watchListModel.ensureRanks()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	for attempt := 0; attempt < moveAttempts; attempt++ {
+		var current string
+		err := watchListModel.DB.QueryRow(`SELECT IFNULL(rank_key, '') FROM Watchlist WHERE watchlist_id = ?;`, watchlistID).Scan(&current)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+
+		low, high, err := watchListModel.moveBounds(watchlistID, move)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+
+		key, err := rank.Between(low, high)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+
+		moved, err := watchListModel.moveTo(watchlistID, current, key, low, high)
+		if err != nil {
+			return models.Watchlist{}, err
+		}
+		if !moved {
+			// an anchor moved, it is read again
+			continue
+		}
+
+		if len(key) > rank.MaxLength {
+			_, err = watchListModel.RebalanceRanks()
+			if err != nil {
+				return models.Watchlist{}, err
+			}
+		}
+
+		return watchListModel.GetWatchListById(watchlist_id)
+	}
+
+	return models.Watchlist{}, ErrRankConflict
+}
+
+// moveTo gives the entry the key between low and high in place of current, it returns false when the entry or the anchors
+// changed in the meantime
+// the update is the first statement of the transaction, a read first could not take the write lock next to concurrent moves,
+// so only the key is audited
+// the rank is not part of the content of the entry, its version stays the same
+func (watchListModel *WatchListModel) moveTo(watchlistID int, current string, key string, low string, high string) (bool, error) {
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// a single statement is atomic, the anchors must still hold their keys and nothing may sit between them
+	result, err := tx.Exec(`UPDATE Watchlist SET rank_key = ?1 WHERE watchlist_id = ?2 AND IFNULL(rank_key, '') = ?5
+	AND (?3 = '' OR EXISTS (SELECT 1 FROM Watchlist WHERE rank_key = ?3))
+	AND (?4 = '' OR EXISTS (SELECT 1 FROM Watchlist WHERE rank_key = ?4))
+	AND NOT EXISTS (SELECT 1 FROM Watchlist WHERE watchlist_id <> ?2 AND rank_key > ?3 AND (?4 = '' OR rank_key < ?4));`,
+		key, watchlistID, low, high, current)
+	if err != nil {
+		return false, err
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil || rowAffected == 0 {
+		return false, err
+	}
+
+	err = insertAuditEvent(tx, watchListModel.actor, "move", watchListEntity, watchlistID, map[string]any{"rank": current}, map[string]any{"rank": key})
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// moveBounds returns the keys the moved entry goes between, "" is the start or the end of the order
+func (watchListModel *WatchListModel) moveBounds(watchlistID int, move models.WatchListMoveRequest) (string, string, error) {
+	var low, high string
+
+	if move.After != nil {
+		err := watchListModel.DB.QueryRow(`SELECT rank_key FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, *move.After).Scan(&low)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	if move.Before != nil {
+		err := watchListModel.DB.QueryRow(`SELECT rank_key FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, *move.Before).Scan(&high)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	switch {
+	case move.After != nil && move.Before != nil:
+		// the client saw them next to each other, anything between them means its order is stale
+		var between int
+		err := watchListModel.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE watchlist_id <> ? AND rank_key > ? AND rank_key < ?;`,
+			watchlistID, low, high).Scan(&between)
+		if err != nil {
+			return "", "", err
+		}
+		if low >= high || between > 0 {
+			return "", "", ErrRankConflict
+		}
+	case move.After != nil:
+		err := watchListModel.DB.QueryRow(`SELECT IFNULL(MIN(rank_key), '') FROM Watchlist WHERE watchlist_id <> ? AND rank_key > ?;`,
+			watchlistID, low).Scan(&high)
+		if err != nil {
+			return "", "", err
+		}
+	case move.Before != nil:
+		err := watchListModel.DB.QueryRow(`SELECT IFNULL(MAX(rank_key), '') FROM Watchlist WHERE watchlist_id <> ? AND rank_key < ?;`,
+			watchlistID, high).Scan(&low)
+		if err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", ErrInvalidMove
+	}
+
+	return low, high, nil
+}
+
+// RebalanceRanks gives every entry a short key again, keeping the order
+// keys grow when entries are often moved to the same place
+func (watchListModel *WatchListModel) RebalanceRanks() (int, error) {
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ids, err := watchListIds(tx, `SELECT watchlist_id FROM Watchlist WHERE deleted_at IS NULL ORDER BY rank_key IS NULL, rank_key, watchlist_id;`)
+	if err != nil {
+		return 0, err
+	}
+
+	keys, err := rankKeys(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	// the keys are unique, they are cleared before the new ones are written
+	// like a move, only the keys change and the versions stay the same
+	_, err = tx.Exec(`UPDATE Watchlist SET rank_key = NULL WHERE deleted_at IS NULL;`)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = assignRanks(tx, ids, "")
+	if err != nil {
+		return 0, err
+	}
+
+	// only the key changes, the entries whose key stays the same are not audited
+	rebalanced, err := rankKeys(tx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if keys[id] == rebalanced[id] {
+			continue
+		}
+		err = insertAuditEvent(tx, watchListModel.actor, "rebalance", watchListEntity, id, map[string]any{"rank": keys[id]}, map[string]any{"rank": rebalanced[id]})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// rankKeys reads the key of every entry in the manual order
+func rankKeys(tx *sql.Tx) (map[int]string, error) {
+	rows, err := tx.Query(`SELECT watchlist_id, rank_key FROM Watchlist WHERE rank_key IS NOT NULL;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := map[int]string{}
+	for rows.Next() {
+		var id int
+		var key string
+		err := rows.Scan(&id, &key)
+		if err != nil {
+			return nil, err
+		}
+		keys[id] = key
+	}
+
+	return keys, rows.Err()
+}
+
+// ensureRanks ranks the entries which have no key yet, in its own transaction
+func (watchListModel *WatchListModel) ensureRanks() error {
+	var unranked int
+	err := watchListModel.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE rank_key IS NULL AND deleted_at IS NULL;`).Scan(&unranked)
+	if err != nil || unranked == 0 {
+		return err
+	}
+
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = rankUnranked(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rankUnranked puts the entries without a key at the end of the order, in ID order
+// entries from before the ranks existed have none, the key of the last one is returned
+func rankUnranked(tx *sql.Tx) (string, error) {
+	ids, err := watchListIds(tx, `SELECT watchlist_id FROM Watchlist WHERE rank_key IS NULL AND deleted_at IS NULL ORDER BY watchlist_id;`)
+	if err != nil {
+		return "", err
+	}
+
+	var last string
+	err = tx.QueryRow(`SELECT IFNULL(MAX(rank_key), '') FROM Watchlist;`).Scan(&last)
+	if err != nil {
+		return "", err
+	}
+
+	return assignRanks(tx, ids, last)
+}
+
+// assignRanks gives the entries consecutive keys after the key last and returns the last key given
+func assignRanks(tx *sql.Tx, ids []int, last string) (string, error) {
+	for _, id := range ids {
+		key, err := rank.Between(last, "")
+		if err != nil {
+			return "", err
+		}
+
+		_, err = tx.Exec(`UPDATE Watchlist SET rank_key = ? WHERE watchlist_id = ?;`, key, id)
+		if err != nil {
+			return "", err
+		}
+		last = key
+	}
+	return last, nil
+}
+
+// watchListIds reads the IDs selected by statement, the rows are closed before the IDs are used
+func watchListIds(tx *sql.Tx, statement string, args ...any) ([]int, error) {
+	rows, err := tx.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Versions
+// =====================================================================================
+
+// ErrVersionMismatch is returned when a write expects another version of the entry than the stored one
+var ErrVersionMismatch = errors.New("the entry changed since this version, read it again")
+
+// checkVersion fails with ErrVersionMismatch unless the entry is at version, 0 skips the check
+// a missing entry passes, the write then finds nothing to change
+func checkVersion(tx *sql.Tx, watchlistID int, version int) error {
+	if version == 0 {
+		return nil
+	}
+
+	var stored int
+	err := tx.QueryRow(`SELECT version FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlistID).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if stored != version {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// touchWatchList counts a write of an entry, the writes of what an entry shows like its reviews or tags count too,
+// see watchListAudit.touch
+func touchWatchList(tx *sql.Tx, watchlistID int) error {
+	_, err := tx.Exec(`UPDATE Watchlist SET version = version + 1 WHERE watchlist_id = ?;`, watchlistID)
+	return err
+}
+
+// Status lifecycle
+// =====================================================================================
+
+// ErrInvalidStatus is returned when a status is not part of the lifecycle
+var ErrInvalidStatus = errors.New("status must be one of not watched, watching, watched, on hold, dropped")
+
+// ErrInvalidWatchList is returned when an entry breaks a rule of the validation package,
+// the validator.ValidationErrors of the fields at fault are wrapped with it
+var ErrInvalidWatchList = errors.New("invalid watchlist entry")
+
+// StatusTransitionError is returned when the lifecycle does not allow a status change
+type StatusTransitionError struct {
+	From string
+	To   string
+}
+
+func (transitionError *StatusTransitionError) Error() string {
+	return fmt.Sprintf("can not move from %q to %q, allowed: %s", transitionError.From, transitionError.To,
+		strings.Join(models.StatusTransitions[transitionError.From], ", "))
+}
+
+// transitionStatus moves an entry to a status and keeps its timeline up to date
+//   - watching starts the entry again unless it resumes from on hold
+//   - watched finishes it
+//   - not watched clears the timeline
+//
+// staying on the same status changes nothing
+func transitionStatus(tx *sql.Tx, watchlistID int, to string, at time.Time) error {
+	var from string
+	err := tx.QueryRow(`SELECT status FROM Watchlist WHERE watchlist_id = ?;`, watchlistID).Scan(&from)
+	if err != nil {
+		return err
+	}
+
+	if !models.CanTransition(from, to) {
+		return &StatusTransitionError{From: from, To: to}
+	}
+	if from == to {
+		return nil
+	}
+
+	// ?1 is the status, ?2 the time of the change, ?3 the entry
+	statement := `UPDATE Watchlist SET status = ?1, status_changed_at = ?2, started_at = COALESCE(started_at, ?2) WHERE watchlist_id = ?3;`
+	switch {
+	case to == "watching" && from != "on hold":
+		statement = `UPDATE Watchlist SET status = ?1, status_changed_at = ?2, started_at = ?2, finished_at = NULL WHERE watchlist_id = ?3;`
+	case to == "watched":
+		statement = `UPDATE Watchlist SET status = ?1, status_changed_at = ?2, started_at = COALESCE(started_at, ?2), finished_at = ?2 WHERE watchlist_id = ?3;`
+	case to == "not watched":
+		statement = `UPDATE Watchlist SET status = ?1, status_changed_at = ?2, started_at = NULL, finished_at = NULL WHERE watchlist_id = ?3;`
+	}
+
+	_, err = tx.Exec(statement, to, at, watchlistID)
+	return err
+}
+
+// External IDs
+// =====================================================================================
+
+// externalIDValues maps the non-empty IDs by provider name
+func externalIDValues(externalIDs models.ExternalIDs) map[string]string {
+	values := map[string]string{}
+	if externalIDs.IMDbID != "" {
+		values["imdb"] = externalIDs.IMDbID
+	}
+	if externalIDs.TMDbID != 0 {
+		values["tmdb"] = strconv.Itoa(externalIDs.TMDbID)
+	}
+	if externalIDs.WikidataID != "" {
+		values["wikidata"] = externalIDs.WikidataID
+	}
+	return values
+}
+
+// saveExternalIDs upserts the non-empty IDs of an entry
+// an ID already linked to another entry fails with the UNIQUE(provider, external_id) constraint
+func saveExternalIDs(tx *sql.Tx, watchlistID int, externalIDs models.ExternalIDs) error {
+	statement := `INSERT INTO watchlist_external_ids (watchlist_id, provider, external_id) VALUES (?, ?, ?)
+	ON CONFLICT(watchlist_id, provider) DO UPDATE SET external_id = excluded.external_id;`
+
+	// an ID held by an entry in the trash goes to the entry which takes it
+	trashed := `DELETE FROM watchlist_external_ids WHERE provider = ? AND external_id = ? AND watchlist_id <> ?
+	AND watchlist_id IN (SELECT watchlist_id FROM Watchlist WHERE deleted_at IS NOT NULL);`
+
+	for provider, externalID := range externalIDValues(externalIDs) {
+		_, err := tx.Exec(trashed, provider, externalID, watchlistID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(statement, watchlistID, provider, externalID)
+		if err != nil {
+			return watchListConflict(err)
+		}
+	}
+	return nil
+}
+
+// ErrWatchListExists is returned when another entry has the same title and release year or external ID
+var ErrWatchListExists = errors.New("an entry with the same title and release year or external ID already exists")
+
+// watchListConflict turns the unique constraint errors of an entry into ErrWatchListExists
+func watchListConflict(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return fmt.Errorf("%w: %v", ErrWatchListExists, err)
+	}
+	return err
+}
+
+// Genres and credits
+// =====================================================================================
+
+// splitNames splits a legacy comma-separated field like "Lana Wachowski, Lilly Wachowski"
+func splitNames(value string) []string {
+	return uniqueNames(strings.Split(value, ","))
+}
+
+// uniqueNames trims the names and drops blanks and case-insensitive repeats, keeping the order
+func uniqueNames(names []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// normalizeGenres prefers the genres array over the legacy genre string
+func normalizeGenres(genre string, genres []string) []string {
+	if genres != nil {
+		return uniqueNames(genres)
+	}
+	return splitNames(genre)
+}
+
+// normalizeCredits cleans the credits and adds the legacy director string
+// when the credits do not name a director already
+func normalizeCredits(director string, credits []models.Credit) []models.Credit {
+	seen := map[string]bool{}
+	normalized := []models.Credit{}
+	hasDirector := false
+
+	add := func(name string, role string) {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name) + "|" + role
+		if name == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		if role == "director" {
+			hasDirector = true
+		}
+		normalized = append(normalized, models.Credit{Name: name, Role: role})
+	}
+
+	for _, credit := range credits {
+		add(credit.Name, credit.Role)
+	}
+	if !hasDirector {
+		for _, name := range splitNames(director) {
+			add(name, "director")
+		}
+	}
+	return normalized
+}
+
+// directorNames is the legacy director string of the credits
+func directorNames(credits []models.Credit) string {
+	names := []string{}
+	for _, credit := range credits {
+		if credit.Role == "director" {
+			names = append(names, credit.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// upsertName returns the ID of the genre, person or tag with this name, creating it when missing
+// names are unique case-insensitively, so the first spelling is kept
+func upsertName(tx *sql.Tx, table string, idColumn string, name string) (int, error) {
+	_, err := tx.Exec(`INSERT INTO `+table+` (name) VALUES (?) ON CONFLICT(name) DO NOTHING;`, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = tx.QueryRow(`SELECT `+idColumn+` FROM `+table+` WHERE name = ?;`, name).Scan(&id)
+	return id, err
+}
+
+// saveGenres replaces the genres of an entry
+func saveGenres(tx *sql.Tx, watchlistID int, genres []string) error {
+	_, err := tx.Exec(`DELETE FROM watchlist_genres WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return err
+	}
+
+	for position, name := range genres {
+		genreID, err := upsertName(tx, "genres", "genre_id", name)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`INSERT INTO watchlist_genres (watchlist_id, genre_id, position) VALUES (?, ?, ?);`, watchlistID, genreID, position)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveCredits replaces every credit of an entry, or only the director credits when replaceAll is false
+// the credits are returned with their person IDs
+func saveCredits(tx *sql.Tx, watchlistID int, credits []models.Credit, replaceAll bool) ([]models.Credit, error) {
+	statement := `DELETE FROM watchlist_credits WHERE watchlist_id = ?;`
+	if !replaceAll {
+		statement = `DELETE FROM watchlist_credits WHERE watchlist_id = ? AND role = 'director';`
+	}
+
+	_, err := tx.Exec(statement, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := []models.Credit{}
+	for position, credit := range credits {
+		if !replaceAll && credit.Role != "director" {
+			continue
+		}
+
+		credit.PersonID, err = upsertName(tx, "people", "person_id", credit.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(`INSERT INTO watchlist_credits (watchlist_id, person_id, role, position) VALUES (?, ?, ?, ?);`, watchlistID, credit.PersonID, credit.Role, position)
+		if err != nil {
+			return nil, err
+		}
+		saved = append(saved, credit)
+	}
+	return saved, nil
+}
+
+// saveTags replaces the tags of an entry, missing tags are created
+func saveTags(tx *sql.Tx, watchlistID int, tags []string) error {
+	_, err := tx.Exec(`DELETE FROM watchlist_tags WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		tagID, err := upsertName(tx, "tags", "tag_id", name)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`INSERT INTO watchlist_tags (watchlist_id, tag_id) VALUES (?, ?);`, watchlistID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRelations fills what the given entries get from the side tables
+func loadRelations(db queryer, watchLists []models.Watchlist) error {
+	err := loadGenresAndCredits(db, watchLists)
+	if err != nil {
+		return err
+	}
+
+	err = loadTags(db, watchLists)
+	if err != nil {
+		return err
+	}
+
+	return loadSeriesProgress(db, watchLists)
+}
+
+// loadTags fills the tags of the given entries, sorted by name
+func loadTags(db queryer, watchLists []models.Watchlist) error {
+	if len(watchLists) == 0 {
+		return nil
+	}
+
+	byID := map[int]*models.Watchlist{}
+	placeholders := make([]string, len(watchLists))
+	args := make([]any, len(watchLists))
+	for i := range watchLists {
+		watchLists[i].Tags = []string{}
+		byID[watchLists[i].WatchlistID] = &watchLists[i]
+		placeholders[i] = "?"
+		args[i] = watchLists[i].WatchlistID
+	}
+
+	return queryRelations(db, `SELECT watchlist_tags.watchlist_id, tags.name FROM watchlist_tags
+	JOIN tags ON tags.tag_id = watchlist_tags.tag_id
+	WHERE watchlist_tags.watchlist_id IN (`+strings.Join(placeholders, ", ")+`) ORDER BY tags.name;`, args, func(rows *sql.Rows) error {
+		var watchlistID int
+		var name string
+		err := rows.Scan(&watchlistID, &name)
+		if err != nil {
+			return err
+		}
+		byID[watchlistID].Tags = append(byID[watchlistID].Tags, name)
+		return nil
+	})
+}
+
+// loadGenresAndCredits fills the genres and credits of the given entries
+func loadGenresAndCredits(db queryer, watchLists []models.Watchlist) error {
+	if len(watchLists) == 0 {
+		return nil
+	}
+
+	byID := map[int]*models.Watchlist{}
+	placeholders := make([]string, len(watchLists))
+	args := make([]any, len(watchLists))
+	for i := range watchLists {
+		watchLists[i].Genres = []string{}
+		watchLists[i].Credits = []models.Credit{}
+		byID[watchLists[i].WatchlistID] = &watchLists[i]
+		placeholders[i] = "?"
+		args[i] = watchLists[i].WatchlistID
+	}
+	in := strings.Join(placeholders, ", ")
+
+	// the queries run one after the other so a single connection is enough
+	err := queryRelations(db, `SELECT watchlist_genres.watchlist_id, genres.name FROM watchlist_genres
+	JOIN genres ON genres.genre_id = watchlist_genres.genre_id
+	WHERE watchlist_genres.watchlist_id IN (`+in+`) ORDER BY watchlist_genres.position;`, args, func(rows *sql.Rows) error {
+		var watchlistID int
+		var name string
+		err := rows.Scan(&watchlistID, &name)
+		if err != nil {
+			return err
+		}
+		byID[watchlistID].Genres = append(byID[watchlistID].Genres, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return queryRelations(db, `SELECT watchlist_credits.watchlist_id, people.person_id, people.name, watchlist_credits.role FROM watchlist_credits
+	JOIN people ON people.person_id = watchlist_credits.person_id
+	WHERE watchlist_credits.watchlist_id IN (`+in+`) ORDER BY watchlist_credits.position;`, args, func(rows *sql.Rows) error {
+		var watchlistID int
+		credit := models.Credit{}
+		err := rows.Scan(&watchlistID, &credit.PersonID, &credit.Name, &credit.Role)
+		if err != nil {
+			return err
+		}
+		byID[watchlistID].Credits = append(byID[watchlistID].Credits, credit)
+		return nil
+	})
+}
+
+// loadSeriesProgress fills the progress of the series, miniseries and documentaries among the given entries
+func loadSeriesProgress(db queryer, watchLists []models.Watchlist) error {
+	byID := map[int]*models.Watchlist{}
+	placeholders := []string{}
+	args := []any{}
+	for i := range watchLists {
+		if !models.IsEpisodic(watchLists[i].Kind) {
+			continue
+		}
+		watchLists[i].Progress = &models.SeriesProgress{}
+		byID[watchLists[i].WatchlistID] = &watchLists[i]
+		placeholders = append(placeholders, "?")
+		args = append(args, watchLists[i].WatchlistID)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	in := strings.Join(placeholders, ", ")
+
+	err := queryRelations(db, `SELECT seasons.watchlist_id, COUNT(*), COUNT(episodes.watched_at) FROM episodes
+	JOIN seasons ON seasons.season_id = episodes.season_id
+	WHERE seasons.watchlist_id IN (`+in+`) GROUP BY seasons.watchlist_id;`, args, func(rows *sql.Rows) error {
+		var watchlistID, episodeCount, watchedCount int
+		err := rows.Scan(&watchlistID, &episodeCount, &watchedCount)
+		if err != nil {
+			return err
+		}
+
+		progress := byID[watchlistID].Progress
+		progress.EpisodeCount = episodeCount
+		progress.WatchedCount = watchedCount
+		progress.Percent = math.Round(float64(watchedCount)*1000/float64(episodeCount)) / 10
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the first episode not watched yet of every entry, in season and episode order
+	return queryRelations(db, `SELECT seasons.watchlist_id, `+episodeColumns+` FROM episodes
+	JOIN seasons ON seasons.season_id = episodes.season_id
+	WHERE seasons.watchlist_id IN (`+in+`) AND episodes.watched_at IS NULL
+	ORDER BY seasons.watchlist_id, seasons.season_number, episodes.episode_number;`, args, func(rows *sql.Rows) error {
+		var watchlistID int
+		episode := models.Episode{}
+		err := rows.Scan(&watchlistID, &episode.EpisodeID, &episode.SeasonNumber, &episode.EpisodeNumber, &episode.Title, &episode.AirDate, &episode.WatchedAt)
+		if err != nil {
+			return err
+		}
+
+		progress := byID[watchlistID].Progress
+		if progress.NextEpisode == nil {
+			progress.NextEpisode = &episode
+		}
+		return nil
+	})
+}
+
+// queryer is a *sql.DB or a *sql.Tx, the relations of an entry are also read in the transaction writing it
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// queryRelations calls scan for every row of a side table query
+func queryRelations(db queryer, statement string, args []any, scan func(rows *sql.Rows) error) error {
+	rows, err := db.Query(statement, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = scan(rows)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
@@ -0,0 +1,368 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// DefaultUndoTTL is used when WatchListModel.UndoTTL is not set
+const DefaultUndoTTL = 10 * time.Minute
+
+var (
+	ErrUndoExpired  = errors.New("the undo token expired")
+	ErrUndoConflict = errors.New("the entries changed since, the undo token can not undo them anymore")
+)
+
+const versionColumns = `watchlist_id, version, action, actor, created_at, snapshot`
+
+func scanVersion(scanner interface{ Scan(dest ...any) error }) (models.WatchListVersion, error) {
+	version := models.WatchListVersion{}
+	var snapshot string
+	err := scanner.Scan(&version.WatchlistID, &version.Version, &version.Action, &version.Actor, &version.CreatedAt, &snapshot)
+	if err != nil {
+		return version, err
+	}
+
+	err = json.Unmarshal([]byte(snapshot), &version.Entry)
+	return version, err
+}
+
+// getVersion reads a version of an entry, sql.ErrNoRows is returned when it is not kept
+func getVersion(db queryer, watchlistID any, version int) (models.WatchListVersion, error) {
+	return scanVersion(db.QueryRow(`SELECT `+versionColumns+` FROM watchlist_versions WHERE watchlist_id = ? AND version = ?;`, watchlistID, version))
+}
+
+// saveVersions keeps the content of an entry after a write, and before it when that version is not kept yet
+// like for the first write of an entry from before the versions existed, a purged entry keeps no version
+func saveVersions(tx *sql.Tx, actor models.Actor, action string, before *models.Watchlist, after *models.Watchlist) error {
+	if after == nil {
+		return nil
+	}
+	if actor.Name == "" {
+		actor.Name = models.SystemActor.Name
+	}
+
+	now := time.Now().UTC()
+	if before != nil {
+		err := insertVersion(tx, `INSERT OR IGNORE`, *before, "", "", "", now)
+		if err != nil {
+			return err
+		}
+	}
+	return insertVersion(tx, `INSERT OR REPLACE`, *after, action, actor.Name, actor.UndoToken, now)
+}
+
+func insertVersion(tx *sql.Tx, insert string, watchList models.Watchlist, action string, actor string, undoToken string, at time.Time) error {
+	snapshot, err := json.Marshal(watchList)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(insert+` INTO watchlist_versions (watchlist_id, version, snapshot, action, actor, undo_token, created_at) VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		watchList.WatchlistID, watchList.Version, string(snapshot), action, actor, undoToken, at)
+	return err
+}
+
+// GetWatchListVersions lists the kept versions of an entry, oldest first
+// sql.ErrNoRows is returned when the entry does not exist
+func (watchListModel *WatchListModel) GetWatchListVersions(watchlist_id string) ([]models.WatchListVersion, error) {
+	var watchlistID int
+	err := watchListModel.DB.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ?;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := watchListModel.DB.Query(`SELECT `+versionColumns+` FROM watchlist_versions WHERE watchlist_id = ? ORDER BY version;`, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.WatchListVersion{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// DiffWatchListVersions compares two versions of an entry, only the fields which differ are returned
+// sql.ErrNoRows is returned when one of them is not kept
+func (watchListModel *WatchListModel) DiffWatchListVersions(watchlist_id string, from int, to int) (models.WatchListVersionDiff, error) {
+	fromVersion, err := getVersion(watchListModel.DB, watchlist_id, from)
+	if err != nil {
+		return models.WatchListVersionDiff{}, err
+	}
+	toVersion, err := getVersion(watchListModel.DB, watchlist_id, to)
+	if err != nil {
+		return models.WatchListVersionDiff{}, err
+	}
+
+	fromFields, err := auditedFields(&fromVersion.Entry)
+	if err != nil {
+		return models.WatchListVersionDiff{}, err
+	}
+	toFields, err := auditedFields(&toVersion.Entry)
+	if err != nil {
+		return models.WatchListVersionDiff{}, err
+	}
+
+	before, after := diffFields(fromFields, toFields)
+	return models.WatchListVersionDiff{WatchlistID: fromVersion.WatchlistID, From: from, To: to, Before: before, After: after}, nil
+}
+
+// RevertWatchList writes the content of an old version of an entry back as a new version
+// the place in the manual order is kept, sql.ErrNoRows is returned when the entry is not live or the version is not kept
+// and ErrWatchListExists when another entry took its title and year or an external ID since
+func (watchListModel *WatchListModel) RevertWatchList(watchlist_id string, version int) (models.Watchlist, error) {
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+	defer tx.Rollback()
+
+	var watchlistID int
+	err = tx.QueryRow(`SELECT watchlist_id FROM Watchlist WHERE watchlist_id = ? AND deleted_at IS NULL;`, watchlist_id).Scan(&watchlistID)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	target, err := getVersion(tx, watchlistID, version)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = revertWatchList(tx, watchListModel.actor, "revert", target.Entry)
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Watchlist{}, err
+	}
+
+	return watchListModel.GetWatchListById(watchlist_id)
+}
+
+// fields of an entry which a revert does not write back, the trash has its own writes
+// and the aggregates and the progress of a series come from the reviews, viewings and episodes
+var unrevertedFields = []string{"rank", "deleted_at", "average_rating", "rating_count", "viewing_count", "rewatch_count", "progress"}
+
+// contentFields are the fields of an entry a revert writes back
+func contentFields(watchList *models.Watchlist) (map[string]any, error) {
+	fields, err := auditedFields(watchList)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range unrevertedFields {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+// revertWatchList writes the content of target over the entry with the same ID, nothing is written when it is the same
+func revertWatchList(tx *sql.Tx, actor models.Actor, action string, target models.Watchlist) error {
+	watchlistID := target.WatchlistID
+
+	audit, err := beginAudit(tx, actor, action, watchlistID)
+	if err != nil {
+		return err
+	}
+	if audit.before == nil {
+		return sql.ErrNoRows
+	}
+
+	current, err := contentFields(audit.before)
+	if err != nil {
+		return err
+	}
+	wanted, err := contentFields(&target)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(current, wanted) {
+		return nil
+	}
+
+	// the status and its timeline are written as they were, a revert does not go through the lifecycle
+	_, err = tx.Exec(`UPDATE Watchlist SET title = ?, release_year = ?, genre = ?, director = ?, status = ?, added_date = ?, kind = ?, runtime = NULLIF(?, 0), poster_path = NULLIF(?, ''), notes = ?,
+	started_at = ?, finished_at = ?, status_changed_at = ?, position_seconds = ?, progress_updated_at = ?, version = version + 1 WHERE watchlist_id = ?;`,
+		target.Title, target.ReleaseYear, target.Genre, target.Director, target.Status, target.AddedDate, target.Kind, target.Runtime, target.PosterPath, target.Notes,
+		target.StartedAt, target.FinishedAt, target.StatusChangedAt, target.PositionSeconds, target.ProgressUpdatedAt, watchlistID)
+	if err != nil {
+		return watchListConflict(err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM watchlist_external_ids WHERE watchlist_id = ?;`, watchlistID)
+	if err != nil {
+		return err
+	}
+	err = saveExternalIDs(tx, watchlistID, target.ExternalIDs)
+	if err != nil {
+		return err
+	}
+
+	err = saveGenres(tx, watchlistID, target.Genres)
+	if err != nil {
+		return err
+	}
+	_, err = saveCredits(tx, watchlistID, target.Credits, true)
+	if err != nil {
+		return err
+	}
+	err = saveTags(tx, watchlistID, target.Tags)
+	if err != nil {
+		return err
+	}
+
+	return audit.record(tx)
+}
+
+// Undo
+// =====================================================================================
+
+// undoneWrites are the versions an undo token wrote to one entry, the first and the last one
+type undoneWrites struct {
+	watchlistID int
+	first       models.WatchListVersion
+	last        models.WatchListVersion
+}
+
+// UndoWatchList puts back the entries written with an undo token as they were before, in one transaction
+// a created entry goes to the trash and a deleted one comes back, the undo is written with a new token so it can be undone too
+// sql.ErrNoRows is returned for an unknown token, ErrUndoExpired once it is older than the undo TTL
+// and ErrUndoConflict when an entry was written again since
+func (watchListModel *WatchListModel) UndoWatchList(token string) ([]models.Watchlist, error) {
+	ttl := watchListModel.UndoTTL
+	if ttl <= 0 {
+		ttl = DefaultUndoTTL
+	}
+
+	tx, err := watchListModel.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	writes, err := undoTokenWrites(tx, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(writes) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	statements, err := prepareWatchListStatements(tx, watchListModel.actor)
+	if err != nil {
+		return nil, err
+	}
+	defer statements.Close()
+
+	expiry := time.Now().UTC().Add(-ttl)
+	undone := []models.Watchlist{}
+	for _, write := range writes {
+		if write.first.CreatedAt.Before(expiry) {
+			return nil, ErrUndoExpired
+		}
+
+		current, err := readWatchList(tx, write.watchlistID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && current.Version != write.last.Version) {
+			return nil, ErrUndoConflict
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = undoWrite(tx, statements, watchListModel.actor, write, current)
+		if err != nil {
+			return nil, err
+		}
+
+		watchList, err := readWatchList(tx, write.watchlistID)
+		if err != nil {
+			return nil, err
+		}
+		undone = append(undone, watchList)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return undone, nil
+}
+
+// undoTokenWrites groups the versions written with token by entry, in the order the entries were first written
+func undoTokenWrites(tx *sql.Tx, token string) ([]*undoneWrites, error) {
+	rows, err := tx.Query(`SELECT `+versionColumns+` FROM watchlist_versions WHERE undo_token = ? AND undo_token <> '' ORDER BY created_at, version;`, token)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	writes := []*undoneWrites{}
+	byID := map[int]*undoneWrites{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		write, found := byID[version.WatchlistID]
+		if !found {
+			write = &undoneWrites{watchlistID: version.WatchlistID, first: version}
+			byID[version.WatchlistID] = write
+			writes = append(writes, write)
+		}
+		write.last = version
+	}
+
+	return writes, rows.Err()
+}
+
+// undoWrite puts an entry back as it was before the first write of the token
+func undoWrite(tx *sql.Tx, statements *watchListStatements, actor models.Actor, write *undoneWrites, current models.Watchlist) error {
+	if write.first.Action == "create" {
+		_, err := statements.deleteWatchList(tx, models.WatchListDeleteRequest{WatchlistID: write.watchlistID})
+		return err
+	}
+
+	var target models.WatchListVersion
+	target, err := scanVersion(tx.QueryRow(`SELECT `+versionColumns+` FROM watchlist_versions WHERE watchlist_id = ? AND version < ? ORDER BY version DESC LIMIT 1;`,
+		write.watchlistID, write.first.Version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUndoConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.DeletedAt != nil && target.Entry.DeletedAt == nil {
+		err = restoreWatchList(tx, actor, write.watchlistID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = revertWatchList(tx, actor, "undo", target.Entry)
+	if err != nil {
+		return err
+	}
+
+	if current.DeletedAt == nil && target.Entry.DeletedAt != nil {
+		_, err = statements.deleteWatchList(tx, models.WatchListDeleteRequest{WatchlistID: write.watchlistID})
+		return err
+	}
+	return nil
+}
@@ -17,9 +17,17 @@ func SetupPrivateRouter(app *App, superRouterGroup *gin.Engine) {
 	routerGroup := superRouterGroup.Group(utils.ROUTER_PREFIX)
 	{
 		v1 := routerGroup.Group(utils.ROUTER_PREFIX_VERSION)
-		v1.Use(middleware.BasicAuthMiddleware())
+		v1.Use(middleware.BasicAuthMiddleware(), idempotency(app))
 		{
 			// v1.GET("/test-private-api/", handlers.TestApi)
+
+			v1.GET("/admin/jobs", app.JobHandler.GetJobsHandler)
+			v1.GET("/admin/jobs/:job_id", app.JobHandler.GetJobByIdHandler)
+			v1.POST("/admin/jobs", app.JobHandler.EnqueueJobHandler)
+			v1.POST("/admin/jobs/:job_id/cancel", app.JobHandler.CancelJobHandler)
+			v1.POST("/admin/watchlist/refresh", app.JobHandler.EnqueueWatchListRefreshHandler)
+
+			v1.GET("/admin/audit", app.AuditHandler.GetAuditEventsHandler)
 		}
 	}
 }
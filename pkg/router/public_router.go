@@ -14,21 +14,92 @@ import (
 
 func SetupPublicRouter(app *App, superRouterGroup *gin.Engine) {
 
-	docs.SwaggerInfo.BasePath = "/api/v1"
+	// the routes of the docs start with their version
+	docs.SwaggerInfo.BasePath = utils.ROUTER_PREFIX
 	routerGroup := superRouterGroup.Group(utils.ROUTER_PREFIX)
+	routerGroup.Use(idempotency(app))
 
 	{
 		v1 := routerGroup.Group(utils.ROUTER_PREFIX_VERSION)
 		{
+			v1.GET("/watchlist", app.WatchListHandler.GetAllWatchListHandler)
 			v1.GET("/watchlist/all", app.WatchListHandler.GetAllWatchListHandler)
 			v1.GET("/watchlist/watched", app.WatchListHandler.GetWatchedListHandler)
 			v1.GET("/watchlist/watching", app.WatchListHandler.GetWatchingListHandler)
 			v1.GET("/watchlist/notwatched", app.WatchListHandler.GetNotWatchedListHandler)
+			v1.GET("/watchlist/continue", app.WatchListHandler.GetContinueWatchingHandler)
 			v1.GET("/watchlist/:watchlist_id", app.WatchListHandler.GetWatchListByIdHandler)
+			v1.GET("/watchlist/by-external/:provider/:external_id", app.WatchListHandler.GetWatchListByExternalIdHandler)
 
 			v1.POST("/watchlist/add", app.WatchListHandler.AddWatchListHandler)
 			v1.DELETE("/watchlist/delete", app.WatchListHandler.DeleteWatchListHandler)
 			v1.PATCH("/watchlist/update", app.WatchListHandler.UpdateWatchListHandler)
+			v1.POST("/watchlist/:watchlist_id/transition", app.WatchListHandler.TransitionWatchListHandler)
+			v1.PUT("/watchlist/:watchlist_id/progress", app.WatchListHandler.UpdateProgressHandler)
+			v1.POST("/watchlist/:watchlist_id/move", app.WatchListHandler.MoveWatchListHandler)
+			v1.POST("/watchlist/:watchlist_id/restore", app.WatchListHandler.RestoreWatchListHandler)
+			v1.GET("/trash", app.WatchListHandler.GetTrashHandler)
+			v1.GET("/watchlist/:watchlist_id/history", app.AuditHandler.GetWatchListHistoryHandler)
+			v1.GET("/watchlist/:watchlist_id/versions", app.WatchListHandler.GetWatchListVersionsHandler)
+			v1.GET("/watchlist/:watchlist_id/versions/diff", app.WatchListHandler.DiffWatchListVersionsHandler)
+			v1.POST("/watchlist/:watchlist_id/revert", app.WatchListHandler.RevertWatchListHandler)
+			v1.POST("/undo/:undo_token", app.WatchListHandler.UndoWatchListHandler)
+
+			v1.GET("/watchlist/:watchlist_id/review", app.ReviewHandler.GetReviewHandler)
+			v1.POST("/watchlist/:watchlist_id/review", app.ReviewHandler.AddReviewHandler)
+			v1.PUT("/watchlist/:watchlist_id/review", app.ReviewHandler.UpdateReviewHandler)
+			v1.DELETE("/watchlist/:watchlist_id/review", app.ReviewHandler.DeleteReviewHandler)
+
+			v1.GET("/watchlist/:watchlist_id/viewings", app.ViewingHandler.GetViewingsHandler)
+			v1.POST("/watchlist/:watchlist_id/viewings", app.ViewingHandler.AddViewingHandler)
+			v1.GET("/diary", app.ViewingHandler.GetDiaryHandler)
+
+			v1.GET("/watchlist/:watchlist_id/seasons", app.SeriesHandler.GetSeasonsHandler)
+			v1.PUT("/watchlist/:watchlist_id/seasons/:season_number", app.SeriesHandler.SaveSeasonHandler)
+			v1.DELETE("/watchlist/:watchlist_id/seasons/:season_number", app.SeriesHandler.DeleteSeasonHandler)
+			v1.POST("/watchlist/:watchlist_id/seasons/:season_number/watched", app.SeriesHandler.MarkEpisodesWatchedHandler)
+			v1.DELETE("/watchlist/:watchlist_id/seasons/:season_number/watched", app.SeriesHandler.UnmarkEpisodesWatchedHandler)
+
+			v1.POST("/import/trakt", app.ImportHandler.ImportTraktHandler)
+			v1.GET("/import/jobs/:job_id", app.ImportHandler.GetImportJobHandler)
+
+			v1.GET("/metadata/lookup", app.MetadataHandler.LookupMetadataHandler)
+
+			v1.GET("/tags", app.TagHandler.GetTagsHandler)
+			v1.POST("/tags", app.TagHandler.AddTagHandler)
+			v1.PUT("/tags/:tag_id", app.TagHandler.RenameTagHandler)
+			v1.DELETE("/tags/:tag_id", app.TagHandler.DeleteTagHandler)
+			v1.POST("/tags/:tag_id/merge", app.TagHandler.MergeTagsHandler)
+			v1.POST("/watchlist/tags", app.TagHandler.TagWatchListsHandler)
+			v1.DELETE("/watchlist/tags", app.TagHandler.UntagWatchListsHandler)
+
+			v1.GET("/lists", app.ListHandler.GetListsHandler)
+			v1.POST("/lists", app.ListHandler.AddListHandler)
+			v1.GET("/lists/:list_id", app.ListHandler.GetListByIdHandler)
+			v1.PATCH("/lists/:list_id", app.ListHandler.UpdateListHandler)
+			v1.DELETE("/lists/:list_id", app.ListHandler.DeleteListHandler)
+			v1.GET("/lists/:list_id/entries", app.ListHandler.GetListEntriesHandler)
+			v1.POST("/lists/:list_id/entries", app.ListHandler.AddListEntryHandler)
+			v1.DELETE("/lists/:list_id/entries/:watchlist_id", app.ListHandler.RemoveListEntryHandler)
+			v1.PUT("/lists/:list_id/order", app.ListHandler.ReorderListHandler)
+			v1.GET("/shared/lists", app.ListHandler.GetPublicListsHandler)
+			v1.GET("/shared/lists/:share_token", app.ListHandler.GetSharedListHandler)
+			v1.GET("/shared/lists/:share_token/entries", app.ListHandler.GetSharedListEntriesHandler)
+
+			v1.GET("/genres", app.GenreHandler.GetGenresHandler)
+			v1.GET("/genres/:genre_id/watchlist", app.GenreHandler.GetWatchListByGenreHandler)
+			v1.GET("/people/:person_id/watchlist", app.PersonHandler.GetWatchListByPersonHandler)
+		}
+
+		// v2 is the watchlist as a resource, v1 stays as it is
+		v2 := routerGroup.Group(utils.ROUTER_PREFIX_VERSION_2)
+		{
+			v2.POST("/watchlist", app.WatchListHandler.AddWatchListV2Handler)
+			v2.POST("/watchlist/batch", app.WatchListHandler.BatchWatchListV2Handler)
+			v2.GET("/watchlist/:watchlist_id", app.WatchListHandler.GetWatchListV2Handler)
+			v2.PUT("/watchlist/:watchlist_id", app.WatchListHandler.ReplaceWatchListV2Handler)
+			v2.PATCH("/watchlist/:watchlist_id", app.WatchListHandler.PatchWatchListV2Handler)
+			v2.DELETE("/watchlist/:watchlist_id", app.WatchListHandler.DeleteWatchListV2Handler)
 		}
 	}
 
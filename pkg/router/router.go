@@ -4,11 +4,20 @@
 package router
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/saketV8/cine-dots/pkg/handlers"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/middleware"
 	"github.com/saketV8/cine-dots/pkg/utils"
 	// log "github.com/sirupsen/logrus"
 )
@@ -16,16 +25,55 @@ import (
 type App struct {
 	// Add more handlers as needed
 	WatchListHandler *handlers.WatchListHandler
+	ImportHandler    *handlers.ImportHandler
+	MetadataHandler  *handlers.MetadataHandler
+	JobHandler       *handlers.JobHandler
+	GenreHandler     *handlers.GenreHandler
+	PersonHandler    *handlers.PersonHandler
+	ReviewHandler    *handlers.ReviewHandler
+	ViewingHandler   *handlers.ViewingHandler
+	SeriesHandler    *handlers.SeriesHandler
+	TagHandler       *handlers.TagHandler
+	ListHandler      *handlers.ListHandler
+	AuditHandler     *handlers.AuditHandler
+
+	// IdempotencyStore keeps the responses of the requests sent with an Idempotency-Key, nil turns the keys off
+	IdempotencyStore middleware.IdempotencyStore
+
+	// JobPool runs the background jobs, it starts and stops with the server
+	JobPool *jobs.Pool
+}
+
+// idempotency runs the POST and PATCH requests sent with an Idempotency-Key once
+// it goes after the auth of a group so a request refused for its credentials is not stored
+func idempotency(app *App) gin.HandlerFunc {
+	if app.IdempotencyStore == nil {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+	return middleware.IdempotencyMiddleware(app.IdempotencyStore, utils.IDEMPOTENCY_TTL, utils.IDEMPOTENCY_WAIT)
+}
+
+// NewRouter is the router of the app with its middlewares and routes, the given middlewares run first
+// the server and the API tests serve the same one
+func NewRouter(app *App, middlewares ...gin.HandlerFunc) *gin.Engine {
+	rtr := gin.New()
+
+	// errors are written as problem+json with the correlation ID of the request
+	rtr.Use(middlewares...)
+	rtr.Use(middleware.RecoveryMiddleware(), middleware.CorrelationIDMiddleware(), middleware.ProblemMiddleware())
+	rtr.NoRoute(handlers.NoRouteHandler)
+
+	SetupPublicRouter(app, rtr)
+	SetupPrivateRouter(app, rtr)
+
+	return rtr
 }
 
 // func SetupRouter(DbModel *querydb.DbModel) {
 func SetupRouter(app *App) {
 	// Initializing the GIN Router
 	gin.SetMode(gin.ReleaseMode)
-	rtr := gin.Default()
-
-	SetupPublicRouter(app, rtr)
-	SetupPrivateRouter(app, rtr)
+	rtr := NewRouter(app, gin.Logger())
 
 	fmt.Println("Setting up gin router 😉")
 	fmt.Println()
@@ -36,12 +84,43 @@ func SetupRouter(app *App) {
 	// print list of all available routes
 	utils.ListAllAvailableRoutes(rtr)
 
+	// stopping on Ctrl+C / SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// starting the background workers
+	if app.JobPool != nil {
+		err := app.JobPool.Start(ctx)
+		if err != nil {
+			log.Fatal("ERROR: while starting the job workers: ", err)
+		}
+		defer app.JobPool.Stop()
+	}
+
 	// starting the server
 	// <PORT> = :9090
-	err := rtr.Run(utils.PORT)
+	server := &http.Server{
+		Addr:    utils.PORT,
+		Handler: rtr,
+	}
+
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("============================================")
+			log.Fatal("ERROR: while Initializing the server")
+			fmt.Println("============================================")
+		}
+	}()
+
+	<-ctx.Done()
+	fmt.Println("Shutting down gracefully 👋")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
 	if err != nil {
-		fmt.Println("============================================")
-		log.Fatal("ERROR: while Initializing the server")
-		fmt.Println("============================================")
+		log.Println("ERROR: while shutting down the server: ", err)
 	}
 }
@@ -1,7 +1,46 @@
 package utils
 
+import "time"
+
 // TODO:
 // replace all with env variable
 var PORT string = ":9090"
 var ROUTER_PREFIX string = "/api"
 var ROUTER_PREFIX_VERSION = "/v1"
+var ROUTER_PREFIX_VERSION_2 = "/v2"
+
+// number of background job workers
+var JOB_WORKERS = 2
+
+// how long a succeeded or cancelled job is kept
+var JOB_RETENTION = 7 * 24 * time.Hour
+
+// how often the finished jobs are checked for jobs to purge
+var JOB_PURGE_INTERVAL = time.Hour
+
+// how often the rank keys of the manual order are rebalanced
+var RANK_REBALANCE_INTERVAL = 24 * time.Hour
+
+// how long the response of a request sent with an Idempotency-Key is kept
+var IDEMPOTENCY_TTL = 24 * time.Hour
+
+// how long a retry waits for the request with the same Idempotency-Key which is in progress
+var IDEMPOTENCY_WAIT = 5 * time.Second
+
+// how often the expired idempotency keys are purged
+var IDEMPOTENCY_PURGE_INTERVAL = time.Hour
+
+// how long a deleted entry stays in the trash before it is purged
+var TRASH_RETENTION = 30 * 24 * time.Hour
+
+// how often the trash is checked for entries to purge
+var TRASH_PURGE_INTERVAL = time.Hour
+
+// how long the X-Undo-Token of a write can undo it
+var UNDO_TTL = 10 * time.Minute
+
+// most operations of a POST /api/v2/watchlist/batch
+var BATCH_MAX_SIZE = 100
+
+// fraction of the runtime after which a progress update marks an entry as watched
+var COMPLETION_THRESHOLD = 0.9
@@ -0,0 +1,170 @@
+// Package validation holds the rules of the request models and checks them
+//
+// the rules are registered on gin's validator when the package is loaded, so the binding tags of the models
+// can use them and callers outside of HTTP, like the importer through the repository, check the same rules
+//
+//	watchstatus  a status of the lifecycle, not watched, watching, watched, on hold or dropped
+//	releaseyear  a year between MinReleaseYear and next year
+//	notblank     a string which is not empty once trimmed
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/saketV8/cine-dots/pkg/models"
+)
+
+// MinReleaseYear is the year of the first motion pictures
+const MinReleaseYear = 1870
+
+var rules = map[string]validator.Func{
+	"watchstatus": func(field validator.FieldLevel) bool {
+		return models.IsValidStatus(field.Field().String())
+	},
+	"releaseyear": func(field validator.FieldLevel) bool {
+		year := field.Field().Int()
+		return year >= MinReleaseYear && year <= int64(MaxReleaseYear())
+	},
+	"notblank": func(field validator.FieldLevel) bool {
+		return strings.TrimSpace(field.Field().String()) != ""
+	},
+}
+
+func init() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	err := Register(validate)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Register adds the rules to a validator and names the fields by their JSON name,
+// so the errors match the body sent
+func Register(validate *validator.Validate) error {
+	validate.RegisterTagNameFunc(fieldName)
+
+	for tag, rule := range rules {
+		err := validate.RegisterValidation(tag, rule)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxReleaseYear is next year, an entry can be added before its release
+func MaxReleaseYear() int {
+	return time.Now().Year() + 1
+}
+
+// Error holds the failed rules of a model, its message is the message of each of them
+// like "title can not be blank, release_year must be between 1870 and 2027"
+type Error struct {
+	Fields validator.ValidationErrors
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, fieldError := range e.Fields {
+		messages = append(messages, Message(fieldError))
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Unwrap gives the validator.ValidationErrors, so the problem package reads the fields at fault
+func (e *Error) Unwrap() error {
+	return e.Fields
+}
+
+// Struct checks every rule of a model, like the binding of a request does
+func Struct(model any) error {
+	return check(binding.Validator.ValidateStruct(model))
+}
+
+// Except checks every rule of a model but the ones of the fields named, fields are the Go names like AddedDate
+// it is for callers which fill some fields themselves, like the importer which has no added_date
+func Except(model any, fields ...string) error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return Struct(model)
+	}
+	return check(validate.StructExcept(model, fields...))
+}
+
+func check(err error) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return &Error{Fields: validationErrors}
+	}
+	return err
+}
+
+// Field is the JSON path of a field at fault like credits[0].name, without the name of the model
+func Field(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found || path == "" {
+		return fieldError.Field()
+	}
+	return path
+}
+
+// Message tells which rule a field breaks, like "release_year must be between 1870 and 2027"
+func Message(fieldError validator.FieldError) string {
+	return Field(fieldError) + " " + rule(fieldError)
+}
+
+func rule(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	unit := ""
+	if fieldError.Kind() == reflect.String {
+		unit = " characters"
+	}
+	if fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map {
+		unit = " items"
+	}
+
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required without " + strings.ToLower(param)
+	case "notblank":
+		return "can not be blank"
+	case "watchstatus":
+		return "must be one of not watched, watching, watched, on hold, dropped"
+	case "releaseyear":
+		return "must be between " + strconv.Itoa(MinReleaseYear) + " and " + strconv.Itoa(MaxReleaseYear())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		return "must be at least " + param + unit
+	case "max", "lte":
+		return "must be at most " + param + unit
+	case "len":
+		return "must be " + param + unit + " long"
+	case "url", "http_url":
+		return "must be a URL"
+	default:
+		return "fails the " + fieldError.Tag() + " rule"
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" {
+		name = strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
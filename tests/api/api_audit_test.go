@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAudit(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	req := newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`)
+	req.Header.Set(problem.CorrelationIDHeader, "req-42")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/transition", `{"status": "watching"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// the history of an entry is public, the events carry the correlation ID of their request
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/1/history", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var history []models.AuditEvent
+	err := json.Unmarshal(resp.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "create", history[0].Action)
+	assert.Equal(t, "anonymous", history[0].Actor)
+	assert.Equal(t, "req-42", history[0].RequestID)
+	assert.JSONEq(t, "null", string(history[0].Before))
+	assert.Equal(t, "transition", history[1].Action)
+	assert.Contains(t, string(history[1].After), `"status":"watching"`)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/99/history", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, problem.NotFound.URI(), decodeProblem(t, resp).Type)
+
+	// the whole audit needs the admin credentials
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/admin/audit", ""))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/audit?request_id=req-42", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var events []models.AuditEvent
+	err = json.Unmarshal(resp.Body.Bytes(), &events)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, history[0].EventID, events[0].EventID)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/audit?since=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, problem.Validation.URI(), decodeProblem(t, resp).Type)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWatchListBatch(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	create := `{"op": "create", "item": {"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}}`
+	update := `{"op": "update", "watchlist_id": 2, "version": 1, "item": {"title": "Test Movie 2", "release_year": 2022, "genre": "Comedy", "director": "Director 2", "status": "watched"}}`
+	deleteMissing := `{"op": "delete", "watchlist_id": 99}`
+
+	count := func() int {
+		var count int
+		err := db.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE deleted_at IS NULL;`).Scan(&count)
+		assert.NoError(t, err)
+		return count
+	}
+
+	// an atomic batch answers the problem of the failing operation and writes nothing
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch", "["+create+", "+update+", "+deleteMissing+"]"))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	var members map[string]any
+	err := json.Unmarshal(resp.Body.Bytes(), &members)
+	assert.NoError(t, err)
+	assert.Equal(t, problem.NotFound.URI(), members["type"])
+	assert.Equal(t, float64(2), members["index"])
+	assert.Equal(t, 3, count())
+
+	// without the failing operation it is written
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch?atomic=true", "["+create+", "+update+"]"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var results []models.WatchListBatchResult
+	err = json.Unmarshal(resp.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Equal(t, "Coco", results[0].Item.Title)
+	assert.Equal(t, http.StatusOK, results[1].Status)
+	assert.Equal(t, "watched", results[1].Item.Status)
+	assert.Equal(t, 2, results[1].Item.Version)
+	assert.Equal(t, 4, count())
+
+	// otherwise each operation has its own status and problem
+	invalid := `{"op": "create", "item": {"title": "Up", "release_year": 2009}}`
+	stale := `{"op": "update", "watchlist_id": 2, "version": 1, "item": {"title": "Test Movie 2", "release_year": 2022, "genre": "Comedy", "director": "Director 2", "status": "watched"}}`
+	deleteFirst := `{"op": "delete", "watchlist_id": 1}`
+	unknown := `{"op": "rename", "watchlist_id": 1}`
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch?atomic=false", "["+strings.Join([]string{create, invalid, stale, deleteFirst, unknown}, ", ")+"]"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var raw []map[string]any
+	err = json.Unmarshal(resp.Body.Bytes(), &raw)
+	assert.NoError(t, err)
+	assert.Len(t, raw, 5)
+
+	statuses := []float64{}
+	for i, result := range raw {
+		assert.Equal(t, float64(i), result["index"])
+		statuses = append(statuses, result["status"].(float64))
+	}
+	assert.Equal(t, []float64{409, 422, 412, 204, 422}, statuses)
+
+	conflict := raw[0]["error"].(map[string]any)
+	assert.Equal(t, problem.Conflict.URI(), conflict["type"])
+	assert.Equal(t, "/api/v2/watchlist/batch", conflict["instance"])
+	assert.NotContains(t, resp.Body.String(), "UNIQUE")
+
+	validation := raw[1]["error"].(map[string]any)
+	assert.Equal(t, problem.Validation.URI(), validation["type"])
+	assert.NotEmpty(t, validation["errors"])
+	assert.Nil(t, raw[3]["item"])
+	assert.Nil(t, raw[3]["error"])
+	assert.Equal(t, 3, count())
+
+	// the batch itself must be an array of 1 to BATCH_MAX_SIZE operations
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch", "[]"))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(t, "invalid batch: a batch has 1 to 100 operations", decodeProblem(t, resp).Detail)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch", "["+strings.Repeat(deleteMissing+", ", 100)+deleteMissing+"]"))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch", `{"op": "create"}`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist/batch?atomic=maybe", "["+deleteMissing+"]"))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
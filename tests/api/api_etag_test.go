@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWatchListETags(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, `"1"`, resp.Header().Get("ETag"))
+
+	// a cached entry is not sent again
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v2/watchlist/1", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"1"`, resp.Header().Get("ETag"))
+
+	req := newJSONRequest("GET", "/api/v2/watchlist/1", "")
+	req.Header.Set("If-None-Match", `"1"`)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	assert.Equal(t, `"1"`, resp.Header().Get("ETag"))
+
+	// writes must send the ETag they read
+	replace := `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "watching"}`
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v2/watchlist/1", replace))
+	assert.Equal(t, http.StatusPreconditionRequired, resp.Code)
+	assert.Equal(t, problem.PreconditionRequired.URI(), decodeProblem(t, resp).Type)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PUT", "/api/v2/watchlist/1", replace), `"1"`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+
+	// the second teammate still holds version 1
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PATCH", "/api/v2/watchlist/1", `{"status": "on hold"}`), `"1"`))
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	assert.Equal(t, problem.PreconditionFailed.URI(), decodeProblem(t, resp).Type)
+	assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PATCH", "/api/v2/watchlist/1", `{"status": "on hold"}`), `"2"`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"3"`, resp.Header().Get("ETag"))
+
+	// a weak ETag never matches If-Match
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("DELETE", "/api/v2/watchlist/1", ""), `W/"3"`))
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("DELETE", "/api/v2/watchlist/999", ""), `"1"`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("DELETE", "/api/v2/watchlist/1", ""), `"2", "3"`))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestAPIWatchListsETag(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	listETag := resp.Header().Get("ETag")
+	assert.Regexp(t, `^W/"1-[0-9a-f]+"$`, listETag)
+
+	req := newJSONRequest("GET", "/api/v1/watchlist/all", "")
+	req.Header.Set("If-None-Match", listETag)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	// another filter is another list
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/watched", ""))
+	assert.NotEqual(t, listETag, resp.Header().Get("ETag"))
+
+	// any write changes the ETag of the lists showing the entry
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PATCH", "/api/v2/watchlist/2", `{"notes": "again"}`), "*"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req = newJSONRequest("GET", "/api/v1/watchlist/all", "")
+	req.Header.Set("If-None-Match", listETag)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Regexp(t, `^W/"2-[0-9a-f]+"$`, resp.Header().Get("ETag"))
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIGenresAndPeople(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	// arrays and legacy strings are both accepted
+	bodies := []string{
+		`{"title": "The Matrix", "release_year": 1999, "genre": "Action, Science Fiction", "director": "Lana Wachowski, Lilly Wachowski",
+		"status": "watched", "added_date": "2024-01-01T00:00:00Z"}`,
+		`{"title": "Cloud Atlas", "release_year": 2012, "genres": ["Drama", "Science Fiction"], "status": "not watched", "added_date": "2024-01-01T00:00:00Z",
+		"credits": [{"name": "Lana Wachowski", "role": "director"}, {"name": "Tom Hanks", "role": "actor"}]}`,
+	}
+
+	var added models.Watchlist
+	for _, body := range bodies {
+		req, _ := http.NewRequest("POST", "/api/v1/watchlist/add", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		err := json.Unmarshal(resp.Body.Bytes(), &added)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "Drama, Science Fiction", added.Genre)
+	assert.Equal(t, "Lana Wachowski", added.Director)
+
+	// unknown roles are rejected
+	body := `{"title": "Speed Racer", "release_year": 2008, "genre": "Action", "status": "not watched", "added_date": "2024-01-01T00:00:00Z",
+	"credits": [{"name": "Emile Hirsch", "role": "stunt double"}]}`
+	req, _ := http.NewRequest("POST", "/api/v1/watchlist/add", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/genres", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var genres []models.Genre
+	err := json.Unmarshal(resp.Body.Bytes(), &genres)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(genres))
+	assert.Equal(t, "Science Fiction", genres[2].Name)
+	assert.Equal(t, 2, genres[2].WatchListCount)
+
+	req, _ = http.NewRequest("GET", "/api/v1/genres/"+strconv.Itoa(genres[2].GenreID)+"/watchlist", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchlists []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchlists)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchlists))
+
+	// Lana Wachowski directed both
+	lana := strconv.Itoa(added.Credits[0].PersonID)
+	req, _ = http.NewRequest("GET", "/api/v1/people/"+lana+"/watchlist?role=director", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchlists)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchlists))
+	assert.Equal(t, []string{"Action", "Science Fiction"}, watchlists[0].Genres)
+
+	req, _ = http.NewRequest("GET", "/api/v1/people/"+lana+"/watchlist?role=producer", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/people/999/watchlist", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/genres/999/watchlist", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/middleware"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestIdempotencyAPI serves the app with a slow and a flaky route behind the idempotency middleware
+// the slow route answers once release is closed, a duplicate of a request in progress waits 100ms
+func setupTestIdempotencyAPI(t *testing.T) (*gin.Engine, *database.Database, chan struct{}) {
+	wait := utils.IDEMPOTENCY_WAIT
+	utils.IDEMPOTENCY_WAIT = 100 * time.Millisecond
+	t.Cleanup(func() { utils.IDEMPOTENCY_WAIT = wait })
+
+	app, db := newTestApp(t)
+	router := newTestRouter(app)
+
+	release := make(chan struct{})
+	failures := 0
+
+	routerGroup := router.Group(utils.ROUTER_PREFIX)
+	routerGroup.Use(middleware.IdempotencyMiddleware(app.IdempotencyStore, utils.IDEMPOTENCY_TTL, utils.IDEMPOTENCY_WAIT))
+	v1 := routerGroup.Group(utils.ROUTER_PREFIX_VERSION)
+	{
+		v1.POST("/slow", func(ctx *gin.Context) {
+			<-release
+			ctx.JSON(http.StatusAccepted, gin.H{"done": true})
+		})
+		v1.POST("/flaky", func(ctx *gin.Context) {
+			failures++
+			if failures == 1 {
+				ctx.Error(problem.New(problem.Internal, "try again"))
+				return
+			}
+			ctx.JSON(http.StatusOK, gin.H{"attempt": failures})
+		})
+	}
+
+	return router, db, release
+}
+
+func withIdempotencyKey(req *http.Request, key string) *http.Request {
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	return req
+}
+
+func TestAPIIdempotencyKeys(t *testing.T) {
+	router, db, release := setupTestIdempotencyAPI(t)
+	defer db.DB.Close()
+
+	body := `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`
+
+	// a retry of an add gets the first response, the entry is added once
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, withIdempotencyKey(newJSONRequest("POST", "/api/v1/watchlist/add", body), "add-coco"))
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+
+	retry := httptest.NewRecorder()
+	router.ServeHTTP(retry, withIdempotencyKey(newJSONRequest("POST", "/api/v1/watchlist/add", body), "add-coco"))
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.NotEmpty(t, retry.Header().Get(problem.CorrelationIDHeader))
+
+	var count int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist;`).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// without a key the add runs again and fails on the duplicate
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add", body))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// the key can not be reused for another body on its route
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": "Up"}`), "add-coco"))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(t, problem.IdempotencyKeyReused.URI(), decodeProblem(t, resp).Type)
+
+	// a problem is stored and replayed like any response
+	invalid := `{"release_year": 2017}`
+	first = httptest.NewRecorder()
+	router.ServeHTTP(first, withIdempotencyKey(newJSONRequest("POST", "/api/v1/watchlist/add", invalid), "add-invalid"))
+	assert.Equal(t, http.StatusBadRequest, first.Code)
+
+	retry = httptest.NewRecorder()
+	router.ServeHTTP(retry, withIdempotencyKey(newJSONRequest("POST", "/api/v1/watchlist/add", invalid), "add-invalid"))
+	assert.Equal(t, http.StatusBadRequest, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, problem.Validation.URI(), decodeProblem(t, retry).Type)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	// a server error is not stored, the retry runs again
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("POST", "/api/v1/flaky", ""), "flaky"))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("POST", "/api/v1/flaky", ""), "flaky"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"attempt": 2}`, resp.Body.String())
+	assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// a duplicate of a request in progress waits, then it is a conflict
+	var wg sync.WaitGroup
+	slow := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(slow, withIdempotencyKey(newJSONRequest("POST", "/api/v1/slow", ""), "slow"))
+	}()
+
+	assert.Eventually(t, func() bool {
+		var state string
+		err := db.DB.QueryRow(`SELECT state FROM idempotency_keys WHERE idempotency_key = 'slow';`).Scan(&state)
+		return err == nil && state == "in progress"
+	}, time.Second, 10*time.Millisecond)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("POST", "/api/v1/slow", ""), "slow"))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+	assert.Equal(t, problem.Conflict.URI(), decodeProblem(t, resp).Type)
+
+	// once the first one completes the duplicate waiting for it gets its response
+	duplicate := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(duplicate, withIdempotencyKey(newJSONRequest("POST", "/api/v1/slow", ""), "slow"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusAccepted, slow.Code)
+	assert.Equal(t, http.StatusAccepted, duplicate.Code)
+	assert.Equal(t, "true", duplicate.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.JSONEq(t, `{"done": true}`, duplicate.Body.String())
+
+	// the same key sent to another route is another key, it runs
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("POST", "/api/v1/slow", body), "add-coco"))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// a key which is not printable is refused, reads ignore the key
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("POST", "/api/v1/watchlist/add", body), "not a key"))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, withIdempotencyKey(newJSONRequest("GET", "/api/v1/watchlist/1", ""), "add-coco"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestImportAPI serves the app with the pool which runs its import jobs
+// the pool is not started, tests drive it with RunNext
+func setupTestImportAPI(t *testing.T) (*gin.Engine, *database.Database, *jobs.Pool) {
+	app, db := newTestApp(t)
+	return newTestRouter(app), db, app.JobPool
+}
+
+func readTraktFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "trakt", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// runImportJob runs the queued import and reads it from the job status endpoint
+func runImportJob(t *testing.T, router *gin.Engine, pool *jobs.Pool, jobID int) models.ImportJob {
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	req, _ := http.NewRequest("GET", "/api/v1/import/jobs/"+strconv.Itoa(jobID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var job models.ImportJob
+	err = json.Unmarshal(resp.Body.Bytes(), &job)
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", job.State)
+	return job
+}
+
+func TestAPIImportTraktJSON(t *testing.T) {
+	router, db, pool := setupTestImportAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	body, _ := json.Marshal(models.TraktImportRequest{
+		History: readTraktFixture(t, "history.json"),
+		Ratings: readTraktFixture(t, "ratings.json"),
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/import/trakt", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	var started models.ImportJob
+	err := json.Unmarshal(resp.Body.Bytes(), &started)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, started.JobID)
+	assert.Equal(t, "trakt", started.Source)
+	assert.Equal(t, 3, started.Total)
+	assert.Equal(t, "queued", started.State)
+
+	job := runImportJob(t, router, pool, started.JobID)
+	assert.Equal(t, 3, job.Imported)
+	assert.Equal(t, 0, job.Duplicates)
+}
+
+func TestAPIImportTraktMultipart(t *testing.T) {
+	router, db, pool := setupTestImportAPI(t)
+	defer db.DB.Close()
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("watchlist", "watchlist.json")
+	_, _ = part.Write(readTraktFixture(t, "watchlist.json"))
+	_ = writer.Close()
+
+	req, _ := http.NewRequest("POST", "/api/v1/import/trakt", &form)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	var started models.ImportJob
+	err := json.Unmarshal(resp.Body.Bytes(), &started)
+	assert.NoError(t, err)
+
+	job := runImportJob(t, router, pool, started.JobID)
+	assert.Equal(t, 2, job.Imported)
+
+	// both watchlist titles land as "not watched"
+	req, _ = http.NewRequest("GET", "/api/v1/watchlist/notwatched", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var notWatched []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &notWatched)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(notWatched))
+}
+
+func TestAPIImportTraktErrors(t *testing.T) {
+	router, db, _ := setupTestImportAPI(t)
+	defer db.DB.Close()
+
+	// empty export
+	req, _ := http.NewRequest("POST", "/api/v1/import/trakt", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// malformed history file
+	req, _ = http.NewRequest("POST", "/api/v1/import/trakt", bytes.NewBufferString(`{"history": {"title": "x"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// unknown job
+	req, _ = http.NewRequest("GET", "/api/v1/import/jobs/unknown", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// only the import jobs are read from the import status
+	other, err := (&repositories.JobModel{DB: db.DB}).EnqueueJob(jobs.WatchListRebalanceKind, nil, 1)
+	assert.NoError(t, err)
+	req, _ = http.NewRequest("GET", "/api/v1/import/jobs/"+strconv.Itoa(other.JobID), nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestJobsAPI serves the app with a no-op refresh job, the admin job routes are behind basic auth
+// the pool is not started, tests drive it with RunNext
+func setupTestJobsAPI(t *testing.T) (*gin.Engine, *database.Database, *jobs.Pool) {
+	app, db := newTestApp(t)
+	app.JobPool.Register(jobs.WatchListRefreshKind, func(ctx context.Context, job models.Job) error {
+		return nil
+	})
+
+	return newTestRouter(app), db, app.JobPool
+}
+
+func newAdminRequest(method, path string, body []byte) *http.Request {
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("saket", "1234")
+	return req
+}
+
+func TestAPIJobsRequireAuth(t *testing.T) {
+	router, db, _ := setupTestJobsAPI(t)
+	defer db.DB.Close()
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/jobs", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+	assert.NotEmpty(t, resp.Header().Get("WWW-Authenticate"))
+
+	req.SetBasicAuth("saket", "wrong")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestAPIJobsLifecycle(t *testing.T) {
+	router, db, pool := setupTestJobsAPI(t)
+	defer db.DB.Close()
+
+	// enqueue
+	body := []byte(`{"kind": "watchlist.refresh", "payload": {"watchlist_id": 1}, "max_attempts": 3}`)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/jobs", body))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	var job models.Job
+	err := json.Unmarshal(resp.Body.Bytes(), &job)
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", job.State)
+	assert.Equal(t, 3, job.MaxAttempts)
+
+	// unknown kind
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/jobs", []byte(`{"kind": "nope"}`)))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// list + filter
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/jobs?state=queued", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var jobList []models.Job
+	err = json.Unmarshal(resp.Body.Bytes(), &jobList)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jobList))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/jobs?limit=1&offset=1", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &jobList)
+	assert.NoError(t, err)
+	assert.Empty(t, jobList)
+
+	for _, query := range []string{"state=done", "limit=501", "offset=-1"} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/jobs?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+
+	// run it and inspect
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/jobs/1", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &job)
+	assert.NoError(t, err)
+	assert.Equal(t, "succeeded", job.State)
+
+	// a finished job can not be cancelled
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/jobs/1/cancel", nil))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("GET", "/api/v1/admin/jobs/999", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/jobs/999/cancel", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAPIJobsCancelQueued(t *testing.T) {
+	router, db, _ := setupTestJobsAPI(t)
+	defer db.DB.Close()
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/jobs", []byte(`{"kind": "watchlist.refresh"}`)))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/jobs/1/cancel", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var job models.Job
+	err := json.Unmarshal(resp.Body.Bytes(), &job)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", job.State)
+}
+
+func TestAPIWatchListRefresh(t *testing.T) {
+	router, db, _ := setupTestJobsAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	// every entry
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/watchlist/refresh", []byte(`{}`)))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	var enqueued []models.Job
+	err := json.Unmarshal(resp.Body.Bytes(), &enqueued)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(enqueued))
+	assert.Equal(t, jobs.WatchListRefreshKind, enqueued[0].Kind)
+	assert.JSONEq(t, `{"watchlist_id": 1}`, string(enqueued[0].Payload))
+
+	// selected entries
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newAdminRequest("POST", "/api/v1/admin/watchlist/refresh", []byte(`{"watchlist_ids": [2]}`)))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &enqueued)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(enqueued))
+}
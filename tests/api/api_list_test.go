@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPILists(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/lists", `{"name": "Horror October", "description": "One scary movie a night"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var list models.List
+	err := json.Unmarshal(resp.Body.Bytes(), &list)
+	assert.NoError(t, err)
+	assert.Equal(t, "private", list.Visibility)
+	listPath := "/api/v1/lists/" + strconv.Itoa(list.ListID)
+
+	for body, code := range map[string]int{
+		`{"name": "horror october"}`:                 http.StatusConflict,
+		`{"name": " "}`:                              http.StatusBadRequest,
+		`{"name": "Oscars", "visibility": "secret"}`: http.StatusBadRequest,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/lists", body))
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	// entries
+	for _, body := range []string{`{"watchlist_id": 1}`, `{"watchlist_id": 2}`, `{"watchlist_id": 3, "position": 1}`} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", listPath+"/entries", body))
+		assert.Equal(t, http.StatusCreated, resp.Code, body)
+	}
+
+	for body, code := range map[string]int{
+		`{"watchlist_id": 1}`:                http.StatusConflict,
+		`{"watchlist_id": 999}`:              http.StatusNotFound,
+		`{"watchlist_id": 1, "position": 0}`: http.StatusBadRequest,
+		`{"position": 1}`:                    http.StatusBadRequest,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", listPath+"/entries", body))
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", listPath+"/order", `{"watchlist_ids": [2, 3, 1]}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", listPath+"/order", `{"watchlist_ids": [2, 3]}`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", listPath+"/entries/3", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", listPath, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &list)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(list.Entries))
+	assert.Equal(t, 2, list.Entries[0].Watchlist.WatchlistID)
+	assert.Equal(t, 2, list.Entries[1].Position)
+
+	// sharing
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/shared/lists/"+list.ShareToken, ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PATCH", listPath, `{"visibility": "public"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/shared/lists/"+list.ShareToken, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/shared/lists", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var lists []models.List
+	err = json.Unmarshal(resp.Body.Bytes(), &lists)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(lists))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PATCH", "/api/v1/lists/999", `{"name": "Nope"}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", listPath, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", listPath, ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAPISmartLists(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/lists", `{"name": "To Watch", "pinned": true, "filter": {"statuses": ["not watched", "watching"], "sort": "title"}}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var list models.List
+	err := json.Unmarshal(resp.Body.Bytes(), &list)
+	assert.NoError(t, err)
+	assert.True(t, list.Smart)
+	assert.True(t, list.Pinned)
+	listPath := "/api/v1/lists/" + strconv.Itoa(list.ListID)
+
+	for body, code := range map[string]int{
+		`{"name": "Bad Sort", "filter": {"sort": "random"}}`:                  http.StatusBadRequest,
+		`{"name": "Bad Status", "filter": {"statuses": ["finished"]}}`:        http.StatusBadRequest,
+		`{"name": "Bad Range", "filter": {"min_rating": 4, "max_rating": 2}}`: http.StatusBadRequest,
+		`{"name": "Bad Kind", "filter": {"kinds": ["podcast"]}}`:              http.StatusBadRequest,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/lists", body))
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", listPath+"/entries?page_size=1&page=2", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var page models.ListEntriesPage
+	err = json.Unmarshal(resp.Body.Bytes(), &page)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, 1, len(page.Entries))
+	assert.Equal(t, "API Test Movie 3", page.Entries[0].Watchlist.Title)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", listPath+"/entries?page_size=500", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", listPath+"/entries", `{"watchlist_id": 1}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// shared smart lists are evaluated too
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PATCH", listPath, `{"visibility": "unlisted", "filter": {"statuses": ["watched"]}}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/shared/lists/"+list.ShareToken+"/entries", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &page)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, "API Test Movie 1", page.Entries[0].Watchlist.Title)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/lists/999/entries", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
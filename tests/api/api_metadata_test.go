@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/metadata"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMetadataProvider returns canned metadata for "Coco" and ErrNotFound otherwise
+type stubMetadataProvider struct {
+	err error
+}
+
+func (stub *stubMetadataProvider) Lookup(ctx context.Context, title string, year int) (models.Metadata, error) {
+	if stub.err != nil {
+		return models.Metadata{}, stub.err
+	}
+	if title != "Coco" {
+		return models.Metadata{}, metadata.ErrNotFound
+	}
+	return models.Metadata{
+		Provider:       "tmdb",
+		Title:          "Coco",
+		ReleaseYear:    2017,
+		Genres:         []string{"Animation", "Family"},
+		Directors:      []string{"Lee Unkrich", "Adrian Molina"},
+		RuntimeMinutes: 105,
+		PosterPath:     "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg",
+		ExternalIDs:    models.ExternalIDs{TMDbID: 354912, IMDbID: "tt2380307"},
+	}, nil
+}
+
+func setupTestMetadataAPI(t *testing.T, provider metadata.MetadataProvider) (*gin.Engine, *database.Database) {
+	app, db := newTestApp(t)
+	app.WatchListHandler.MetadataProvider = provider
+	app.MetadataHandler.MetadataProvider = provider
+
+	return newTestRouter(app), db
+}
+
+func TestAPIAddWatchListEnriched(t *testing.T) {
+	router, db := setupTestMetadataAPI(t, &stubMetadataProvider{})
+	defer db.DB.Close()
+
+	req, _ := http.NewRequest("POST", "/api/v1/watchlist/add?enrich=true", bytes.NewBufferString(`{"title": "Coco"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var added models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &added)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, added.WatchlistID)
+	assert.Equal(t, 2017, added.ReleaseYear)
+	assert.Equal(t, "Animation, Family", added.Genre)
+	assert.Equal(t, "Lee Unkrich, Adrian Molina", added.Director)
+	assert.Equal(t, "not watched", added.Status)
+	assert.Equal(t, []string{"Animation", "Family"}, added.Genres)
+	assert.Equal(t, 105, added.Runtime)
+	assert.Equal(t, "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg", added.PosterPath)
+
+	assert.Equal(t, "tt2380307", added.ExternalIDs.IMDbID)
+
+	// the provider values are stored
+	stored, err := (&repositories.WatchListModel{DB: db.DB}).GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Animation", "Family"}, stored.Genres)
+	assert.Equal(t, 105, stored.Runtime)
+	assert.Equal(t, "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg", stored.PosterPath)
+
+	// client fields win over the provider
+	// title+year and external IDs are unique, so this runs on a fresh database
+	router, db = setupTestMetadataAPI(t, &stubMetadataProvider{})
+	defer db.DB.Close()
+
+	body := `{"title": "Coco", "genre": "Musical", "status": "watched"}`
+	req, _ = http.NewRequest("POST", "/api/v1/watchlist/add?enrich=true", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &added)
+	assert.NoError(t, err)
+	assert.Equal(t, "Musical", added.Genre)
+	assert.Equal(t, []string{"Musical"}, added.Genres)
+	assert.Equal(t, "watched", added.Status)
+	assert.Equal(t, "Lee Unkrich, Adrian Molina", added.Director)
+}
+
+func TestAPIAddWatchListEnrichErrors(t *testing.T) {
+	router, db := setupTestMetadataAPI(t, &stubMetadataProvider{})
+	defer db.DB.Close()
+
+	// unknown title
+	req, _ := http.NewRequest("POST", "/api/v1/watchlist/add?enrich=true", bytes.NewBufferString(`{"title": "Unknown"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// title is still required
+	req, _ = http.NewRequest("POST", "/api/v1/watchlist/add?enrich=true", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// provider down
+	router, db = setupTestMetadataAPI(t, &stubMetadataProvider{err: errors.New("connection refused")})
+	defer db.DB.Close()
+
+	req, _ = http.NewRequest("POST", "/api/v1/watchlist/add?enrich=true", bytes.NewBufferString(`{"title": "Coco"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadGateway, resp.Code)
+
+	// enrichment not configured
+	router, db = setupTestMetadataAPI(t, nil)
+	defer db.DB.Close()
+
+	req, _ = http.NewRequest("POST", "/api/v1/watchlist/add?enrich=true", bytes.NewBufferString(`{"title": "Coco"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestAPILookupMetadata(t *testing.T) {
+	router, db := setupTestMetadataAPI(t, &stubMetadataProvider{})
+	defer db.DB.Close()
+
+	req, _ := http.NewRequest("GET", "/api/v1/metadata/lookup?title=Coco&year=2017", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var found models.Metadata
+	err := json.Unmarshal(resp.Body.Bytes(), &found)
+	assert.NoError(t, err)
+	assert.Equal(t, "tt2380307", found.ExternalIDs.IMDbID)
+
+	req, _ = http.NewRequest("GET", "/api/v1/metadata/lookup?title=Coco&year=abc", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/metadata/lookup?title=Unknown", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeProblem(t *testing.T, resp *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+	assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+
+	var body problem.Problem
+	err := json.Unmarshal(resp.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	return body
+}
+
+func TestAPIProblems(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	// a validation problem lists every field at fault by its JSON path
+	req := newJSONRequest("POST", "/api/v1/watchlist/add", `{"release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "credits": [{"name": "Lee Unkrich", "role": "grip"}]}`)
+	req.Header.Set(problem.CorrelationIDHeader, "req-42")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "req-42", resp.Header().Get(problem.CorrelationIDHeader))
+
+	body := decodeProblem(t, resp)
+	assert.Equal(t, problem.Validation.URI(), body.Type)
+	assert.Equal(t, "Validation failed", body.Title)
+	assert.Equal(t, http.StatusBadRequest, body.Status)
+	assert.Equal(t, "/api/v1/watchlist/add", body.Instance)
+	assert.Equal(t, "req-42", body.CorrelationID)
+	assert.ElementsMatch(t, []problem.FieldError{
+		{Field: "title", Rule: "required", Message: "title is required"},
+		{Field: "status", Rule: "required", Message: "status is required"},
+		{Field: "credits[0].role", Rule: "oneof", Message: "credits[0].role must be one of director, writer, actor"},
+	}, body.Errors)
+
+	// a wrong JSON type is a field error too, v2 answers validation problems with 422
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": "2017"}`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, []problem.FieldError{{Field: "release_year", Rule: "type", Message: "release_year must be a number, not a string"}}, body.Errors)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": 2017}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, problem.Validation.URI(), body.Type)
+	assert.Equal(t, http.StatusUnprocessableEntity, body.Status)
+
+	// a body which is not JSON is a bad request, a generated correlation ID is returned
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": `))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, problem.BadRequest.URI(), body.Type)
+	assert.Len(t, body.CorrelationID, 32)
+	assert.Equal(t, body.CorrelationID, resp.Header().Get(problem.CorrelationIDHeader))
+
+	// a status transition problem carries the allowed statuses as an extension member
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PUT", "/api/v2/watchlist/1", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "dropped"}`), "*"))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	var members map[string]any
+	err := json.Unmarshal(resp.Body.Bytes(), &members)
+	assert.NoError(t, err)
+	assert.Equal(t, problem.Conflict.URI(), members["type"])
+	assert.Equal(t, []any{"watching"}, members["allowed"])
+
+	// a conflict on a unique constraint does not show the driver error
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "UNIQUE")
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/nothing-here", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, problem.NotFound.URI(), body.Type)
+
+	// an internal error is only logged, the client gets the correlation ID to report it
+	db.DB.Close()
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all", ""))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, problem.Internal.URI(), body.Type)
+	assert.Equal(t, "Failed to get All WatchList", body.Detail)
+	assert.NotContains(t, resp.Body.String(), "closed")
+	assert.NotEmpty(t, body.CorrelationID)
+}
+
+func TestAPIWatchListExistsProblem(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+	insertTestAPIData(t, db)
+
+	// the same title and release year as the first entry
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": "API Test Movie 1", "release_year": 2021, "genre": "Action", "director": "Director 1", "status": "watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, problem.Conflict.URI(), decodeProblem(t, resp).Type)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PATCH", "/api/v1/watchlist/update", `{"watchlist_id": 2, "title": "API Test Movie 1", "release_year": 2021, "genre": "Comedy", "director": "Director 2", "status": "watching"}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, problem.Conflict.URI(), decodeProblem(t, resp).Type)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/999", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, problem.NotFound.URI(), decodeProblem(t, resp).Type)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIProgressAndContinueWatching(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/3/progress", `{"position_seconds": 1800, "runtime": 120}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchList models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, 1800, watchList.PositionSeconds)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/continue", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchLists []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchLists))
+	assert.Equal(t, 3, watchLists[0].WatchlistID)
+
+	// past 90% of the runtime
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/3/progress", `{"position_seconds": 6600}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchList.Status)
+
+	for _, body := range []string{`{}`, `{"position_seconds": -1}`, `{"position_seconds": 10, "runtime": 0.5}`} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/3/progress", body))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/999/progress", `{"position_seconds": 10}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
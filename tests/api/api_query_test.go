@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWatchListQuery(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist?q="+url.QueryEscape(`year:>2021 -status:"not watched"`), ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchLists []models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(watchLists))
+	assert.Equal(t, "API Test Movie 2", watchLists[0].Title)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/watched?q="+url.QueryEscape("director:director OR year:2023"), ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(watchLists))
+
+	// errors point at the column of the query
+	for q, column := range map[string]float64{
+		"status:watched (year:>2000": 27,
+		"year:>2000 status:finished": 19,
+		"year: 2000":                 6,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist?q="+url.QueryEscape(q), ""))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, q)
+
+		var body map[string]any
+		err = json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Contains(t, body["detail"], "Invalid list query")
+		assert.Equal(t, column, body["column"], q)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIMoveWatchList(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/3/move", `{"before": 1}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchList models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, watchList.Rank)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/move", `{"after": 3, "before": 1}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all?sort=rank", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchLists []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	ids := []int{}
+	for _, watchList := range watchLists {
+		ids = append(ids, watchList.WatchlistID)
+	}
+	assert.Equal(t, []int{3, 2, 1}, ids)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all?sort=-rank", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, watchLists[0].WatchlistID)
+
+	// the anchors are not next to each other any more
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/move", `{"after": 1, "before": 3}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	for body, code := range map[string]int{
+		`{}`:              http.StatusBadRequest,
+		`{"after": 1}`:    http.StatusBadRequest,
+		`{"before": 999}`: http.StatusNotFound,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/move", body))
+		assert.Equal(t, code, resp.Code, body)
+	}
+}
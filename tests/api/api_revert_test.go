@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/handlers"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRevertWatchList(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PATCH", "/api/v1/watchlist/update", `{"watchlist_id": 1, "title": "Coco", "release_year": 2017, "genre": "Animation, Family", "director": "Lee Unkrich", "status": "watching"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/1/versions", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var versions []models.WatchListVersion
+	err := json.Unmarshal(resp.Body.Bytes(), &versions)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "create", versions[0].Action)
+	assert.Equal(t, "anonymous", versions[0].Actor)
+	assert.Equal(t, "update", versions[1].Action)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/1/versions/diff?from=1&to=2", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var diff models.WatchListVersionDiff
+	err = json.Unmarshal(resp.Body.Bytes(), &diff)
+	assert.NoError(t, err)
+	assert.Equal(t, "not watched", diff.Before["status"])
+	assert.Equal(t, "watching", diff.After["status"])
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/1/versions/diff?from=1", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// the revert is a new version with the old content
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/revert?version=1", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get(handlers.UndoTokenHeader))
+
+	var reverted models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &reverted)
+	assert.NoError(t, err)
+	assert.Equal(t, "Animation", reverted.Genre)
+	assert.Equal(t, "not watched", reverted.Status)
+	assert.Equal(t, 3, reverted.Version)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/revert?version=9", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, problem.NotFound.URI(), decodeProblem(t, resp).Type)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/revert", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestAPIUndoWatchList(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/transition", `{"status": "watching"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	token := resp.Header().Get(handlers.UndoTokenHeader)
+	assert.NotEmpty(t, token)
+
+	// a failed write has nothing to undo
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/99/transition", `{"status": "watching"}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Empty(t, resp.Header().Get(handlers.UndoTokenHeader))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/undo/"+token, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get(handlers.UndoTokenHeader))
+	assert.NotEqual(t, token, resp.Header().Get(handlers.UndoTokenHeader))
+
+	var undone []models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &undone)
+	assert.NoError(t, err)
+	assert.Len(t, undone, 1)
+	assert.Equal(t, "watched", undone[0].Status)
+
+	// the entry changed since the token was made
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/undo/"+token, ""))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, problem.Conflict.URI(), decodeProblem(t, resp).Type)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/undo/unknown", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, problem.NotFound.URI(), decodeProblem(t, resp).Type)
+}
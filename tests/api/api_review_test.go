@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newJSONRequest(method, path string, body string) *http.Request {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestAPIReviews(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/review", `{"rating": 4.5, "text": "Loved it"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var review models.Review
+	err := json.Unmarshal(resp.Body.Bytes(), &review)
+	assert.NoError(t, err)
+	assert.Equal(t, 4.5, review.Rating)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/review", `{"rating": 3}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// invalid ratings
+	for _, body := range []string{`{"rating": 4.3}`, `{"rating": 0}`, `{"rating": 5.5}`, `{"text": "no rating"}`} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/review", body))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/999/review", `{"rating": 3}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/1/review", `{"rating": 2, "text": "Changed my mind", "spoiler": true}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/1/review", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &review)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, review.Rating)
+	assert.True(t, review.Spoiler)
+	assert.Equal(t, 1, len(review.History))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/2/review", `{"rating": 2}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// rating aggregates and sorting on the lists
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/3/review", `{"rating": 5}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all?sort=-rating&min_rating=1", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchlists []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchlists)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchlists))
+	assert.Equal(t, 3, watchlists[0].WatchlistID)
+	assert.Equal(t, 5.0, *watchlists[0].AverageRating)
+	assert.Equal(t, 1, watchlists[0].RatingCount)
+
+	for _, query := range []string{"sort=popularity", "min_rating=abc", "min_rating=4&max_rating=2"} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all?"+query, ""))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/1/review", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/1/review", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
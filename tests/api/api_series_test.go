@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPISeries(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add",
+		`{"title": "Chernobyl", "release_year": 2019, "genre": "Drama", "director": "Johan Renck", "status": "not watched", "added_date": "2025-06-20T00:00:00Z", "kind": "miniseries"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/4/seasons/1", `{"episode_count": 5}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var season models.Season
+	err := json.Unmarshal(resp.Body.Bytes(), &season)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(season.Episodes))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/4/seasons/1/watched", `{"from": 1, "to": 2}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchList models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, 40.0, watchList.Progress.Percent)
+	assert.Equal(t, 3, watchList.Progress.NextEpisode.EpisodeNumber)
+
+	// no body is the whole season
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/4/seasons/1/watched", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchList.Status)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/4/seasons/1/watched", `{"from": 5}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, watchList.Progress.WatchedCount)
+
+	for path, body := range map[string]string{
+		"/api/v1/watchlist/4/seasons/one/watched": "",
+		"/api/v1/watchlist/4/seasons/1/watched":   `{"from": 4, "to": 2}`,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", path, body))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, path)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/4/seasons/2/watched", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// movies keep their shape and have no seasons
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/watchlist/1/seasons/1", `{"episode_count": 5}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/1", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var movie map[string]any
+	err = json.Unmarshal(resp.Body.Bytes(), &movie)
+	assert.NoError(t, err)
+	assert.Equal(t, "movie", movie["kind"])
+	assert.NotContains(t, movie, "progress")
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/4/seasons", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/999/seasons", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/4/seasons/1", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITransitionWatchList(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/transition", `{"status": "on hold", "at": "2025-06-20T21:00:00Z"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchList models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "on hold", watchList.Status)
+	assert.Equal(t, "2025-06-20T21:00:00Z", watchList.StatusChangedAt.Format(time.RFC3339))
+
+	// watched only leads back to watching
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/transition", `{"status": "dropped"}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var conflict struct {
+		Allowed []string `json:"allowed"`
+	}
+	err = json.Unmarshal(resp.Body.Bytes(), &conflict)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"watching"}, conflict.Allowed)
+
+	for _, body := range []string{`{}`, `{"status": "finished"}`} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/transition", body))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/999/transition", `{"status": "watching"}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// the update endpoint refuses the same transition
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PATCH", "/api/v1/watchlist/update",
+		`{"watchlist_id": 1, "title": "API Test Movie 1", "release_year": 2021, "genre": "Action", "director": "Director 1", "status": "dropped"}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/handlers"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITags(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/tags", `{"name": "date night"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	for body, code := range map[string]int{
+		`{"name": "Date Night"}`: http.StatusConflict,
+		`{"name": "  "}`:         http.StatusBadRequest,
+		`{}`:                     http.StatusBadRequest,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/tags", body))
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/tags", `{"watchlist_ids": [1, 2, 3], "tags": ["date night", "with kids"]}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var result struct {
+		RowAffected int `json:"row-affected"`
+	}
+	err := json.Unmarshal(resp.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, result.RowAffected)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/tags", `{"watchlist_ids": [3], "tags": ["with kids"]}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	for _, body := range []string{`{"watchlist_ids": [], "tags": ["x"]}`, `{"watchlist_ids": [1], "tags": [""]}`} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/tags", body))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+
+	// list filtering
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all?tags=date+night,with+kids&tag_match=all", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchLists []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchLists))
+	assert.Equal(t, []string{"date night", "with kids"}, watchLists[0].Tags)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all?tags=x&tag_match=some", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// rename, merge and counts
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/tags", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tags []models.Tag
+	err = json.Unmarshal(resp.Body.Bytes(), &tags)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tags))
+	dateNight, withKids := strconv.Itoa(tags[0].TagID), strconv.Itoa(tags[1].TagID)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/tags/"+withKids, `{"name": "Date night"}`))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("PUT", "/api/v1/tags/999", `{"name": "nope"}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/tags/"+dateNight+"/merge", `{"tag_ids": [`+withKids+`]}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var tag models.Tag
+	err = json.Unmarshal(resp.Body.Bytes(), &tag)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tag.WatchListCount)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/tags", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &tags)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tags))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/tags/"+dateNight, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// a tag write is undone like the other writes of the entries
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/tags", `{"watchlist_ids": [1], "tags": ["cozy"]}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	token := resp.Header().Get(handlers.UndoTokenHeader)
+	assert.NotEmpty(t, token)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/undo/"+token, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var undone []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &undone)
+	assert.NoError(t, err)
+	assert.Len(t, undone, 1)
+	assert.Empty(t, undone[0].Tags)
+}
@@ -13,65 +13,72 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/saketV8/cine-dots/pkg/database"
 	"github.com/saketV8/cine-dots/pkg/handlers"
+	"github.com/saketV8/cine-dots/pkg/importer"
+	"github.com/saketV8/cine-dots/pkg/jobs"
 	"github.com/saketV8/cine-dots/pkg/models"
 	"github.com/saketV8/cine-dots/pkg/repositories"
-	"github.com/saketV8/cine-dots/pkg/utils"
+	"github.com/saketV8/cine-dots/pkg/router"
+	"github.com/saketV8/cine-dots/tests/testutil"
 	"github.com/stretchr/testify/assert"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// setupTestAPI initializes a test API server with a real database connection
-func setupTestAPI(t *testing.T) (*gin.Engine, *database.Database) {
+// newTestApp wires the handlers of the app on an in-memory database like main does
+// the pool is not started, tests drive it with RunNext
+func newTestApp(t *testing.T) (*router.App, *database.Database) {
 	// Use an in-memory SQLite database for testing
 	db, err := database.InitializeDatabase("sqlite3", ":memory:")
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	// Create the watchlist table in the in-memory database
-	createTableSQL := `
-    CREATE TABLE IF NOT EXISTS Watchlist (
-        watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
-        title TEXT NOT NULL,
-        release_year INTEGER NOT NULL,
-        genre TEXT NOT NULL,
-        director TEXT NOT NULL,
-        status TEXT NOT NULL,
-        added_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-    );
-    `
-	_, err = db.DB.Exec(createTableSQL)
-	if err != nil {
-		t.Fatalf("Failed to create test table: %v", err)
-	}
-
-	// Setup the router with the handlers
-	gin.SetMode(gin.TestMode)
-	r := gin.Default()
-
-	// Create app with handlers
-	watchListHandler := &handlers.WatchListHandler{
-		WatchListModel: &repositories.WatchListModel{
-			DB: db.DB,
+	// Create the watchlist tables in the in-memory database
+	testutil.CreateSchema(t, db.DB)
+
+	watchListModel := &repositories.WatchListModel{DB: db.DB}
+	jobModel := &repositories.JobModel{DB: db.DB}
+	jobPool := jobs.NewPool(jobModel, 1)
+	jobPool.Register(jobs.WatchListImportKind, jobs.NewWatchListImportHandler(importer.Stores{
+		WatchLists: watchListModel,
+		Reviews:    &repositories.ReviewModel{DB: db.DB},
+		Viewings:   &repositories.ViewingModel{DB: db.DB},
+	}, jobModel))
+
+	app := &router.App{
+		WatchListHandler: &handlers.WatchListHandler{WatchListModel: watchListModel},
+		ImportHandler:    &handlers.ImportHandler{JobModel: jobModel},
+		MetadataHandler:  &handlers.MetadataHandler{},
+		JobHandler: &handlers.JobHandler{
+			JobModel:       jobModel,
+			WatchListModel: watchListModel,
+			Pool:           jobPool,
 		},
+		GenreHandler:     &handlers.GenreHandler{GenreModel: &repositories.GenreModel{DB: db.DB}},
+		PersonHandler:    &handlers.PersonHandler{PersonModel: &repositories.PersonModel{DB: db.DB}},
+		ReviewHandler:    &handlers.ReviewHandler{ReviewModel: &repositories.ReviewModel{DB: db.DB}},
+		ViewingHandler:   &handlers.ViewingHandler{ViewingModel: &repositories.ViewingModel{DB: db.DB}},
+		SeriesHandler:    &handlers.SeriesHandler{SeriesModel: &repositories.SeriesModel{DB: db.DB}},
+		TagHandler:       &handlers.TagHandler{TagModel: &repositories.TagModel{DB: db.DB}},
+		ListHandler:      &handlers.ListHandler{ListModel: &repositories.ListModel{DB: db.DB}},
+		AuditHandler:     &handlers.AuditHandler{AuditModel: &repositories.AuditModel{DB: db.DB}},
+		IdempotencyStore: &repositories.IdempotencyModel{DB: db.DB},
+		JobPool:          jobPool,
 	}
 
-	// Setup routes
-	routerGroup := r.Group(utils.ROUTER_PREFIX)
-	v1 := routerGroup.Group(utils.ROUTER_PREFIX_VERSION)
-	{
-		v1.GET("/watchlist/all", watchListHandler.GetAllWatchListHandler)
-		v1.GET("/watchlist/watched", watchListHandler.GetWatchedListHandler)
-		v1.GET("/watchlist/watching", watchListHandler.GetWatchingListHandler)
-		v1.GET("/watchlist/notwatched", watchListHandler.GetNotWatchedListHandler)
-		v1.GET("/watchlist/:watchlist_id", watchListHandler.GetWatchListByIdHandler)
-		v1.POST("/watchlist/add", watchListHandler.AddWatchListHandler)
-		v1.DELETE("/watchlist/delete", watchListHandler.DeleteWatchListHandler)
-		v1.PATCH("/watchlist/update", watchListHandler.UpdateWatchListHandler)
-	}
+	return app, db
+}
 
-	return r, db
+// newTestRouter is the router of the server for the app, with its routes and middlewares
+func newTestRouter(app *router.App) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return router.NewRouter(app)
+}
+
+// setupTestAPI initializes a test API server with a real database connection
+func setupTestAPI(t *testing.T) (*gin.Engine, *database.Database) {
+	app, db := newTestApp(t)
+	return newTestRouter(app), db
 }
 
 // insertTestAPIData adds sample data for API testing
@@ -143,7 +150,13 @@ func TestAPIGetWatchListById(t *testing.T) {
 	req, _ = http.NewRequest("GET", "/api/v1/watchlist/999", nil)
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Test non-numeric ID
+	req, _ = http.NewRequest("GET", "/api/v1/watchlist/abc", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
 
 func TestAPIAddWatchList(t *testing.T) {
@@ -187,6 +200,18 @@ func TestAPIAddWatchList(t *testing.T) {
 	err = json.Unmarshal(resp.Body.Bytes(), &watchlists)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(watchlists))
+
+	// without an added_date the entry is added today
+	req, _ = http.NewRequest("POST", "/api/v1/watchlist/add", bytes.NewBufferString(`{"title": "API Undated Test Movie", "release_year": 2024, "genre": "Sci-Fi", "director": "API Test Director", "status": "not watched"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var undated models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &undated)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), undated.AddedDate.Format(time.DateOnly))
 }
 
 func TestAPIUpdateWatchList(t *testing.T) {
@@ -233,6 +258,15 @@ func TestAPIUpdateWatchList(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "API Updated Movie", watchlist.Title)
 	assert.Equal(t, "watching", watchlist.Status)
+
+	// Test non-existent ID
+	updateRequest.WatchlistID = 999
+	body, _ = json.Marshal(updateRequest)
+	req, _ = http.NewRequest("PATCH", "/api/v1/watchlist/update", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
 }
 
 func TestAPIDeleteWatchList(t *testing.T) {
@@ -415,7 +449,7 @@ func TestAPIFullCycle(t *testing.T) {
 	req, _ = http.NewRequest("GET", "/api/v1/watchlist/"+strconv.Itoa(watchlistID), nil)
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	assert.Equal(t, http.StatusInternalServerError, resp.Code) // Should get an error as item is deleted
+	assert.Equal(t, http.StatusNotFound, resp.Code) // Should get a 404 as item is deleted
 }
 
 func TestAPIErrorHandling(t *testing.T) {
@@ -447,5 +481,42 @@ func TestAPIErrorHandling(t *testing.T) {
 	req, _ = http.NewRequest("GET", "/api/v1/watchlist/999", nil)
 	resp = httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
-	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestAPIGetWatchListByExternalId(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	body := `{"title": "Parasite", "release_year": 2019, "genre": "Thriller", "director": "Bong Joon-ho", "status": "watched",
+	"added_date": "2024-01-01T00:00:00Z", "external_ids": {"imdb_id": "tt6751668", "tmdb_id": 496243}}`
+	req, _ := http.NewRequest("POST", "/api/v1/watchlist/add", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/watchlist/by-external/tmdb/496243", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchlist models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchlist)
+	assert.NoError(t, err)
+	assert.Equal(t, "Parasite", watchlist.Title)
+	assert.Equal(t, "tt6751668", watchlist.ExternalIDs.IMDbID)
+	assert.Equal(t, 496243, watchlist.ExternalIDs.TMDbID)
+
+	// unknown ID
+	req, _ = http.NewRequest("GET", "/api/v1/watchlist/by-external/imdb/tt0000000", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// unknown provider
+	req, _ = http.NewRequest("GET", "/api/v1/watchlist/by-external/letterboxd/parasite", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
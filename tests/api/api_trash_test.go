@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITrash(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/delete", `{"watchlist_id": 1}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// the entry leaves the list for the trash
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/all", ""))
+	var watchLists []models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchLists)
+	assert.NoError(t, err)
+	assert.Len(t, watchLists, 2)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/trash", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var trash []models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &trash)
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+	assert.Equal(t, 1, trash[0].WatchlistID)
+	assert.NotNil(t, trash[0].DeletedAt)
+
+	// the title can be added again while the deleted one is in the trash
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": "API Test Movie 1", "release_year": 2021, "genre": "Action", "director": "Director 1", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var added models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &added)
+	assert.NoError(t, err)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/restore", ""))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, problem.Conflict.URI(), decodeProblem(t, resp).Type)
+
+	// once the new one is deleted the first one comes back
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/delete", `{"watchlist_id": `+strconv.Itoa(added.WatchlistID)+`}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/restore", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var restored models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &restored)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, restored.WatchlistID)
+	assert.Nil(t, restored.DeletedAt)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/restore", ""))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, problem.NotFound.URI(), decodeProblem(t, resp).Type)
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWatchListV2(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	body := `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched",
+	"added_date": "2025-06-20T00:00:00Z", "external_ids": {"imdb_id": "tt2380307"}}`
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", body))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var watchList models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	location := resp.Header().Get("Location")
+	assert.Equal(t, "/api/v2/watchlist/1", location)
+	created := watchList
+
+	for body, code := range map[string]int{
+		`{"title": "Coco"`: http.StatusBadRequest,
+		`{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`:                                         http.StatusConflict,
+		`{"title": "Up", "release_year": 2009, "genre": "Animation", "director": "Pete Docter", "status": "finished", "added_date": "2025-06-20T00:00:00Z"}`:                                              http.StatusUnprocessableEntity,
+		`{"title": "Up", "release_year": 2009, "genre": "Animation", "status": "not watched", "added_date": "2025-06-20T00:00:00Z"}`:                                                                      http.StatusUnprocessableEntity,
+		`{"title": "Up", "release_year": 2009, "genre": "Animation", "director": "Pete Docter", "status": "not watched", "external_ids": {"imdb_id": "tt2380307"}, "added_date": "2025-06-20T00:00:00Z"}`: http.StatusConflict,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", body))
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", location, ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// the created entry is the one stored, added_date included
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-06-20", created.AddedDate.Format(time.DateOnly))
+	assert.True(t, created.AddedDate.Equal(watchList.AddedDate))
+
+	for _, path := range []string{"/api/v2/watchlist/999", "/api/v2/watchlist/abc"} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("GET", path, ""))
+		assert.Equal(t, http.StatusNotFound, resp.Code, path)
+	}
+
+	// PATCH only changes the fields sent
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PATCH", location, `{"status": "watching", "genre": "Animation, Family"}`), "*"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "Coco", watchList.Title)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, []string{"Animation", "Family"}, watchList.Genres)
+	assert.Equal(t, "tt2380307", watchList.ExternalIDs.IMDbID)
+
+	for body, code := range map[string]int{
+		`{"title": ""}`:          http.StatusUnprocessableEntity,
+		`{"status": "finished"}`: http.StatusUnprocessableEntity,
+		`[1, 2]`:                 http.StatusBadRequest,
+	} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, ifMatch(newJSONRequest("PATCH", location, body), "*"))
+		assert.Equal(t, code, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PATCH", "/api/v2/watchlist/999", `{"status": "watched"}`), "*"))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// PUT replaces the entry, the ID of the path wins over the body
+	replace := `{"watchlist_id": 7, "title": "Coco", "release_year": 2017, "genres": ["Animation"], "director": "Lee Unkrich, Adrian Molina", "status": "watched"}`
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PUT", location, replace), "*"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, watchList.WatchlistID)
+	assert.Equal(t, "watched", watchList.Status)
+	assert.Equal(t, "Lee Unkrich, Adrian Molina", watchList.Director)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PUT", location, `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "not watched"}`), "*"))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PUT", "/api/v2/watchlist/999", replace), "*"))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("DELETE", location, ""), "*"))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("DELETE", location, ""), "*"))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// v1 no longer reports deleting a missing entry as a success
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("DELETE", "/api/v1/watchlist/delete", `{"watchlist_id": 1}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+// newPatchRequest patches any version of the entry, see TestAPIWatchListETags for If-Match
+func newPatchRequest(path string, contentType string, body string) *http.Request {
+	req := ifMatch(newJSONRequest("PATCH", path, body), "*")
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+// ifMatch makes a v2 write conditional on the ETag, * matches any version
+func ifMatch(req *http.Request, tag string) *http.Request {
+	req.Header.Set("If-Match", tag)
+	return req
+}
+
+func TestAPIWatchListPatch(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	body := `{"title": "Coco", "release_year": 2017, "genres": ["Animation", "Family"], "director": "Lee Unkrich", "status": "not watched",
+	"added_date": "2025-06-20T00:00:00Z", "runtime": 105, "tags": ["with kids"], "notes": "Recommended by **Priya**"}`
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", body))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	location := resp.Header().Get("Location")
+
+	// a merge patch changes the members sent and null removes one
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newPatchRequest(location, "application/merge-patch+json", `{"status": "watching", "notes": null}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchList models.Watchlist
+	err := json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, "", watchList.Notes)
+	assert.Equal(t, "Coco", watchList.Title)
+	assert.Equal(t, 105, watchList.Runtime)
+	assert.Equal(t, []string{"Animation", "Family"}, watchList.Genres)
+	assert.Equal(t, []string{"with kids"}, watchList.Tags)
+
+	// a JSON patch applies its operations in order, the test guards against a concurrent change
+	operations := `[
+		{"op": "test", "path": "/status", "value": "watching"},
+		{"op": "add", "path": "/genres/-", "value": "Music"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "add", "path": "/tags/-", "value": "rewatch"},
+		{"op": "copy", "from": "/title", "path": "/notes"}
+	]`
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newPatchRequest(location, "application/json-patch+json; charset=utf-8", operations))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Animation", "Family", "Music"}, watchList.Genres)
+	assert.Equal(t, []string{"rewatch"}, watchList.Tags)
+	assert.Equal(t, "Coco", watchList.Notes)
+	assert.Equal(t, "watching", watchList.Status)
+
+	// the legacy genre alone replaces the genres, removing the tags clears them
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newPatchRequest(location, "application/merge-patch+json", `{"genre": "Fantasy", "tags": null}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Fantasy"}, watchList.Genres)
+	assert.Empty(t, watchList.Tags)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"unknown field", "application/merge-patch+json", `{"rating": 5}`, http.StatusUnprocessableEntity},
+		{"read-only field", "application/merge-patch+json", `{"watchlist_id": 9}`, http.StatusUnprocessableEntity},
+		{"added unknown field", "application/json-patch+json", `[{"op": "add", "path": "/rank", "value": "a0"}]`, http.StatusUnprocessableEntity},
+		{"removed required field", "application/json-patch+json", `[{"op": "remove", "path": "/title"}]`, http.StatusUnprocessableEntity},
+		{"wrong type", "application/merge-patch+json", `{"release_year": "2017"}`, http.StatusUnprocessableEntity},
+		{"invalid status", "application/merge-patch+json", `{"status": "finished"}`, http.StatusUnprocessableEntity},
+		{"missing path", "application/json-patch+json", `[{"op": "replace", "path": "/credits/5/name", "value": "x"}]`, http.StatusUnprocessableEntity},
+		{"failed test", "application/json-patch+json", `[{"op": "test", "path": "/status", "value": "not watched"}, {"op": "replace", "path": "/title", "value": "Up"}]`, http.StatusConflict},
+		{"unknown op", "application/json-patch+json", `[{"op": "merge", "path": "/title", "value": "Up"}]`, http.StatusBadRequest},
+		{"merge patch array", "application/merge-patch+json", `[{"op": "remove", "path": "/title"}]`, http.StatusBadRequest},
+		{"malformed JSON", "application/json-patch+json", `[{"op": "remove"`, http.StatusBadRequest},
+		{"unsupported format", "text/plain", `{"title": "Up"}`, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, newPatchRequest(location, tt.contentType, tt.body))
+			assert.Equal(t, tt.code, resp.Code, resp.Body.String())
+		})
+	}
+
+	// nothing of the rejected patches was applied
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", location, ""))
+	err = json.Unmarshal(resp.Body.Bytes(), &watchList)
+	assert.NoError(t, err)
+	assert.Equal(t, "Coco", watchList.Title)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, 2017, watchList.ReleaseYear)
+}
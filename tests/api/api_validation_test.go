@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWatchListValidation(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	maxYear := strconv.Itoa(time.Now().Year() + 1)
+
+	// an invalid status is refused before it reaches the database
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/add", `{"title": "  ", "release_year": 1850, "genre": "Animation", "director": "Lee Unkrich", "status": "finished", "added_date": "2025-06-20T00:00:00Z", "tags": ["family", ""]}`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	body := decodeProblem(t, resp)
+	assert.ElementsMatch(t, []problem.FieldError{
+		{Field: "title", Rule: "notblank", Message: "title can not be blank"},
+		{Field: "release_year", Rule: "releaseyear", Message: "release_year must be between 1870 and " + maxYear},
+		{Field: "status", Rule: "watchstatus", Message: "status must be one of not watched, watching, watched, on hold, dropped"},
+		{Field: "tags[1]", Rule: "notblank", Message: "tags[1] can not be blank"},
+	}, body.Errors)
+
+	// the same rules apply to v2 with 422
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": 3025, "genre": "Animation", "director": "Lee Unkrich", "status": "watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, []problem.FieldError{
+		{Field: "release_year", Rule: "releaseyear", Message: "release_year must be between 1870 and " + maxYear},
+	}, body.Errors)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v2/watchlist", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "watched", "added_date": "2025-06-20T00:00:00Z"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	// updates and patches
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ifMatch(newJSONRequest("PUT", "/api/v2/watchlist/1", `{"title": "Coco", "release_year": 2017, "genre": "Animation", "director": "Lee Unkrich", "status": "rewatching"}`), "*"))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, "watchstatus", body.Errors[0].Rule)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newPatchRequest("/api/v2/watchlist/1", "application/merge-patch+json", `{"title": ""}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, "title", body.Errors[0].Field)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/1/transition", `{"status": "finished"}`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	body = decodeProblem(t, resp)
+	assert.Equal(t, "status", body.Errors[0].Field)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIViewingsAndDiary(t *testing.T) {
+	router, db := setupTestAPI(t)
+	defer db.DB.Close()
+
+	insertTestAPIData(t, db)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/viewings", `{"watched_on": "2025-06-20T21:00:00Z", "rating": 4, "platform": "Prime Video"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/viewings", `{"watched_on": "2025-07-01T20:00:00Z", "notes": "Even better"}`))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var viewing models.Viewing
+	err := json.Unmarshal(resp.Body.Bytes(), &viewing)
+	assert.NoError(t, err)
+	assert.True(t, viewing.Rewatch)
+
+	for _, body := range []string{`{"rating": 3.3}`, `{"rating": 6}`, `{"watched_on": "yesterday"}`} {
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/2/viewings", body))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("POST", "/api/v1/watchlist/999/viewings", `{}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// watching became watched
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/2", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var watchlist models.Watchlist
+	err = json.Unmarshal(resp.Body.Bytes(), &watchlist)
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchlist.Status)
+	assert.Equal(t, 1, watchlist.RewatchCount)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/watchlist/2/viewings", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var viewings []models.Viewing
+	err = json.Unmarshal(resp.Body.Bytes(), &viewings)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(viewings))
+
+	// the last day is inclusive
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/diary?from=2025-06-01&to=2025-06-20", ""))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	err = json.Unmarshal(resp.Body.Bytes(), &viewings)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(viewings))
+	assert.Equal(t, "Prime Video", viewings[0].Platform)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newJSONRequest("GET", "/api/v1/diary?from=20-06-2025", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,287 @@
+package integration
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeFields(t *testing.T, data json.RawMessage) map[string]any {
+	t.Helper()
+	var fields map[string]any
+	err := json.Unmarshal(data, &fields)
+	assert.NoError(t, err)
+	return fields
+}
+
+func TestWatchListAudit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	audit := &repositories.AuditModel{DB: db.DB}
+	base := &repositories.WatchListModel{DB: db.DB}
+	repo := base.As(models.Actor{Name: "saket", RequestID: "req-1"})
+
+	added, err := repo.AddWatchList(models.Watchlist{Title: "Coco", ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "not watched", AddedDate: time.Now(), Tags: []string{"with kids"}})
+	assert.NoError(t, err)
+	id := strconv.Itoa(added.WatchlistID)
+
+	_, err = repo.UpdateWatchList(models.WatchListUpdateRequest{WatchlistID: added.WatchlistID, Title: "Coco", ReleaseYear: 2017, Genre: "Animation, Family", Director: "Lee Unkrich", Status: "watching"})
+	assert.NoError(t, err)
+
+	// a progress update which changes nothing is not audited
+	position := 600
+	_, err = repo.UpdateProgress(id, models.ProgressRequest{PositionSeconds: &position})
+	assert.NoError(t, err)
+	_, err = repo.UpdateProgress(id, models.ProgressRequest{PositionSeconds: &position})
+	assert.NoError(t, err)
+
+	_, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: added.WatchlistID})
+	assert.NoError(t, err)
+	_, err = repo.RestoreWatchList(id)
+	assert.NoError(t, err)
+
+	// writes without an actor are made by the system
+	_, err = base.TransitionWatchList(id, "watched", time.Now())
+	assert.NoError(t, err)
+
+	history, err := audit.GetWatchListHistory(id)
+	assert.NoError(t, err)
+	actions := []string{}
+	for _, event := range history {
+		actions = append(actions, event.Action)
+		assert.Equal(t, "watchlist", event.Entity)
+		assert.Equal(t, added.WatchlistID, event.EntityID)
+	}
+	assert.Equal(t, []string{"create", "update", "progress", "delete", "restore", "transition"}, actions)
+
+	// a create has no before, the after holds the entry
+	create := history[0]
+	assert.Equal(t, "saket", create.Actor)
+	assert.Equal(t, "req-1", create.RequestID)
+	assert.Equal(t, "null", string(create.Before))
+	after := decodeFields(t, create.After)
+	assert.Equal(t, "Coco", after["title"])
+	assert.Equal(t, []any{"with kids"}, after["tags"])
+	assert.NotContains(t, after, "version")
+
+	// an update only holds the fields which changed
+	assert.Equal(t, map[string]any{"genre": "Animation", "genres": []any{"Animation"}, "status": "not watched", "started_at": nil},
+		withoutTimes(decodeFields(t, history[1].Before)))
+	updated := decodeFields(t, history[1].After)
+	assert.Equal(t, "Animation, Family", updated["genre"])
+	assert.Equal(t, "watching", updated["status"])
+
+	deleted := decodeFields(t, history[3].After)
+	assert.NotNil(t, deleted["deleted_at"])
+	assert.Equal(t, "system", history[5].Actor)
+	assert.Empty(t, history[5].RequestID)
+
+	// the purge keeps the history, a purge has no after
+	_, err = base.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: added.WatchlistID})
+	assert.NoError(t, err)
+	_, err = base.PurgeTrash(0)
+	assert.NoError(t, err)
+
+	history, err = audit.GetWatchListHistory(id)
+	assert.NoError(t, err)
+	purge := history[len(history)-1]
+	assert.Equal(t, "purge", purge.Action)
+	assert.Equal(t, "null", string(purge.After))
+	assert.Equal(t, "Coco", decodeFields(t, purge.Before)["title"])
+
+	// the events can not be changed
+	_, err = db.DB.Exec(`UPDATE audit_events SET actor = 'someone';`)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = db.DB.Exec(`DELETE FROM audit_events;`)
+	assert.ErrorContains(t, err, "append-only")
+
+	// an entry without any event
+	insertTestData(t, db.DB)
+	history, err = audit.GetWatchListHistory("3")
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+	_, err = audit.GetWatchListHistory("99")
+	assert.Error(t, err)
+}
+
+// withoutTimes drops the timestamps set by a write
+func withoutTimes(fields map[string]any) map[string]any {
+	for _, field := range []string{"status_changed_at", "progress_updated_at"} {
+		delete(fields, field)
+	}
+	return fields
+}
+
+func TestAuditEventsQuery(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	audit := &repositories.AuditModel{DB: db.DB}
+	base := &repositories.WatchListModel{DB: db.DB}
+
+	for i, actor := range []string{"saket", "anonymous", "saket"} {
+		repo := base.As(models.Actor{Name: actor, RequestID: "req-" + strconv.Itoa(i)})
+		_, err := repo.AddWatchList(models.Watchlist{Title: "Movie " + strconv.Itoa(i), ReleaseYear: 2000 + i, Genre: "Drama", Director: "Someone", Status: "not watched"})
+		assert.NoError(t, err)
+	}
+	_, err := base.As(models.Actor{Name: "saket"}).MoveWatchList("1", models.WatchListMoveRequest{After: anchor(3)})
+	assert.NoError(t, err)
+
+	events, err := audit.GetAuditEvents(models.AuditQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+	assert.Equal(t, "move", events[0].Action)
+	assert.Contains(t, decodeFields(t, events[0].Before), "rank")
+
+	events, err = audit.GetAuditEvents(models.AuditQuery{Actor: "saket", Action: "create"})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, 3, events[0].EntityID)
+
+	events, err = audit.GetAuditEvents(models.AuditQuery{RequestID: "req-1"})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "anonymous", events[0].Actor)
+
+	// paging with before_id
+	events, err = audit.GetAuditEvents(models.AuditQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	events, err = audit.GetAuditEvents(models.AuditQuery{Limit: 2, BeforeID: events[1].EventID})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, 1, events[1].EntityID)
+
+	events, err = audit.GetAuditEvents(models.AuditQuery{Since: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	events, err = audit.GetAuditEvents(models.AuditQuery{Until: time.Now().Add(time.Hour), EntityID: 2})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+// lastEvent is the newest event of an entry
+func lastEvent(t *testing.T, audit *repositories.AuditModel, id int) models.AuditEvent {
+	t.Helper()
+	history, err := audit.GetWatchListHistory(strconv.Itoa(id))
+	assert.NoError(t, err)
+	if !assert.NotEmpty(t, history) {
+		return models.AuditEvent{}
+	}
+	return history[len(history)-1]
+}
+
+func TestRelatedWritesAudit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+	insertTestData(t, db.DB)
+
+	audit := &repositories.AuditModel{DB: db.DB}
+	actor := models.Actor{Name: "saket", RequestID: "req-1"}
+
+	// the writes of what an entry shows are events of the entry, they hold the fields of the entry which changed
+	reviews := (&repositories.ReviewModel{DB: db.DB}).As(actor)
+	_, err := reviews.AddReview("1", models.ReviewRequest{Rating: 4})
+	assert.NoError(t, err)
+	event := lastEvent(t, audit, 1)
+	assert.Equal(t, "review", event.Action)
+	assert.Equal(t, "saket", event.Actor)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.Equal(t, map[string]any{"average_rating": 4.0, "rating_count": 1.0}, decodeFields(t, event.After))
+
+	_, err = reviews.UpdateReview("1", models.ReviewRequest{Rating: 3.5})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"average_rating": 3.5}, decodeFields(t, lastEvent(t, audit, 1).After))
+
+	_, err = reviews.DeleteReview("1")
+	assert.NoError(t, err)
+	event = lastEvent(t, audit, 1)
+	assert.Equal(t, "review", event.Action)
+	assert.Equal(t, map[string]any{"average_rating": nil, "rating_count": 0.0}, decodeFields(t, event.After))
+
+	viewings := (&repositories.ViewingModel{DB: db.DB}).As(actor)
+	_, err = viewings.AddViewing("3", models.ViewingRequest{})
+	assert.NoError(t, err)
+	event = lastEvent(t, audit, 3)
+	assert.Equal(t, "viewing", event.Action)
+	assert.Equal(t, "watched", decodeFields(t, event.After)["status"])
+	assert.Equal(t, 1.0, decodeFields(t, event.After)["viewing_count"])
+
+	added, err := (&repositories.WatchListModel{DB: db.DB}).AddWatchList(models.Watchlist{Title: "Dark", ReleaseYear: 2017, Genre: "Drama", Director: "Baran bo Odar", Status: "not watched", Kind: "series"})
+	assert.NoError(t, err)
+	id := strconv.Itoa(added.WatchlistID)
+
+	series := (&repositories.SeriesModel{DB: db.DB}).As(actor)
+	_, err = series.SaveSeason(id, 1, models.SeasonRequest{EpisodeCount: 2})
+	assert.NoError(t, err)
+	event = lastEvent(t, audit, added.WatchlistID)
+	assert.Equal(t, "season", event.Action)
+	assert.Equal(t, 2.0, decodeFields(t, event.After)["progress"].(map[string]any)["episode_count"])
+
+	_, err = series.MarkEpisodes(id, 1, models.EpisodeRangeRequest{From: 1, To: 1}, true)
+	assert.NoError(t, err)
+	event = lastEvent(t, audit, added.WatchlistID)
+	assert.Equal(t, "episodes", event.Action)
+	assert.Equal(t, "watching", decodeFields(t, event.After)["status"])
+
+	_, err = series.MarkEpisodes(id, 1, models.EpisodeRangeRequest{From: 1}, false)
+	assert.NoError(t, err)
+	event = lastEvent(t, audit, added.WatchlistID)
+	assert.Equal(t, "episodes", event.Action)
+	assert.Equal(t, 0.0, decodeFields(t, event.After)["progress"].(map[string]any)["watched_count"])
+
+	_, err = series.DeleteSeason(id, 1)
+	assert.NoError(t, err)
+	event = lastEvent(t, audit, added.WatchlistID)
+	assert.Equal(t, "season", event.Action)
+	assert.Equal(t, 0.0, decodeFields(t, event.After)["progress"].(map[string]any)["episode_count"])
+
+	// a tag write is an event of every entry it changes, unknown entries are skipped
+	tags := (&repositories.TagModel{DB: db.DB}).As(actor)
+	_, err = tags.TagWatchLists([]int{1, 2, 99}, []string{"cozy", "rainy day"})
+	assert.NoError(t, err)
+	for _, id := range []int{1, 2} {
+		event = lastEvent(t, audit, id)
+		assert.Equal(t, "tag", event.Action)
+		assert.Equal(t, []any{"cozy", "rainy day"}, decodeFields(t, event.After)["tags"])
+	}
+
+	_, err = tags.UntagWatchLists([]int{2}, []string{"rainy day"})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"cozy"}, decodeFields(t, lastEvent(t, audit, 2).After)["tags"])
+
+	all, err := tags.GetTags()
+	assert.NoError(t, err)
+	cozy, rainy := strconv.Itoa(all[0].TagID), all[1].TagID
+
+	_, err = tags.RenameTag(cozy, "Cozy")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"Cozy"}, decodeFields(t, lastEvent(t, audit, 2).After)["tags"])
+
+	_, err = tags.MergeTags(cozy, []int{rainy})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"Cozy"}, decodeFields(t, lastEvent(t, audit, 1).After)["tags"])
+
+	_, err = tags.DeleteTag(cozy)
+	assert.NoError(t, err)
+	for _, id := range []int{1, 2} {
+		event = lastEvent(t, audit, id)
+		assert.Equal(t, "tag", event.Action)
+		assert.Empty(t, decodeFields(t, event.After)["tags"])
+	}
+
+	// a write which changes nothing an entry shows is not an event
+	events, err := audit.GetAuditEvents(models.AuditQuery{})
+	assert.NoError(t, err)
+	_, err = tags.UntagWatchLists([]int{1, 2}, []string{"unknown"})
+	assert.NoError(t, err)
+	after, err := audit.GetAuditEvents(models.AuditQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, after, len(events))
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func countWatchLists(t *testing.T, db *sql.DB) int {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE deleted_at IS NULL;`).Scan(&count)
+	assert.NoError(t, err)
+	return count
+}
+
+func TestBatchWatchList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB, MaxBatchSize: 4}
+
+	create := func(title string) models.WatchListBatchWrite {
+		return models.WatchListBatchWrite{Op: "create", Create: models.Watchlist{Title: title, ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "not watched", AddedDate: time.Now()}}
+	}
+	update := models.WatchListBatchWrite{Op: "update", Update: models.WatchListUpdateRequest{WatchlistID: 2, Title: "Test Movie 2", ReleaseYear: 2022, Genre: "Comedy", Director: "Director 2", Status: "watched"}}
+	deleteMissing := models.WatchListBatchWrite{Op: "delete", Delete: models.WatchListDeleteRequest{WatchlistID: 99}}
+
+	// an atomic batch writes nothing when an operation fails
+	_, _, err := repo.BatchWatchList([]models.WatchListBatchWrite{create("Coco"), update, deleteMissing}, true)
+	var batchError *repositories.BatchError
+	assert.True(t, errors.As(err, &batchError))
+	assert.Equal(t, 2, batchError.Index)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, 3, countWatchLists(t, db.DB))
+
+	watchList, err := repo.GetWatchListById("2")
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, 1, watchList.Version)
+
+	// otherwise the failing operations are rolled back alone
+	duplicate := create("Coco")
+	watchLists, errs, err := repo.BatchWatchList([]models.WatchListBatchWrite{create("Coco"), duplicate, update, deleteMissing}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], repositories.ErrWatchListExists)
+	assert.NoError(t, errs[2])
+	assert.ErrorIs(t, errs[3], sql.ErrNoRows)
+
+	assert.Equal(t, "Coco", watchLists[0].Title)
+	assert.Equal(t, 4, watchLists[0].WatchlistID)
+	assert.Equal(t, "watched", watchLists[2].Status)
+	assert.Equal(t, 2, watchLists[2].Version)
+	assert.Equal(t, 4, countWatchLists(t, db.DB))
+
+	// a write which could not be read fails without running, an atomic batch does not start
+	unreadable := models.WatchListBatchWrite{Op: "create", Err: errors.New("malformed")}
+	_, _, err = repo.BatchWatchList([]models.WatchListBatchWrite{create("Up"), unreadable}, true)
+	assert.True(t, errors.As(err, &batchError))
+	assert.Equal(t, 1, batchError.Index)
+	assert.Equal(t, 4, countWatchLists(t, db.DB))
+
+	deleteFirst := models.WatchListBatchWrite{Op: "delete", Delete: models.WatchListDeleteRequest{WatchlistID: 1, Version: 1}}
+	deleteStale := models.WatchListBatchWrite{Op: "delete", Delete: models.WatchListDeleteRequest{WatchlistID: 3, Version: 5}}
+	_, errs, err = repo.BatchWatchList([]models.WatchListBatchWrite{unreadable, deleteFirst, deleteStale}, false)
+	assert.NoError(t, err)
+	assert.EqualError(t, errs[0], "malformed")
+	assert.NoError(t, errs[1])
+	assert.ErrorIs(t, errs[2], repositories.ErrVersionMismatch)
+	assert.Equal(t, 3, countWatchLists(t, db.DB))
+
+	// the size of a batch is checked first
+	_, _, err = repo.BatchWatchList(nil, true)
+	assert.ErrorIs(t, err, repositories.ErrInvalidBatch)
+
+	_, _, err = repo.BatchWatchList([]models.WatchListBatchWrite{create("A"), create("B"), create("C"), create("D"), create("E")}, false)
+	assert.ErrorIs(t, err, repositories.ErrInvalidBatch)
+	assert.EqualError(t, err, "invalid batch: a batch has 1 to 4 operations")
+	assert.Equal(t, 3, countWatchLists(t, db.DB))
+}
@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchListGenresAndCredits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.WatchListModel{
+		DB: db.DB,
+	}
+
+	// legacy strings are split
+	matrix, err := repo.AddWatchList(models.Watchlist{
+		Title:       "The Matrix",
+		ReleaseYear: 1999,
+		Genre:       "Action, Science Fiction, action",
+		Director:    "Lana Wachowski, Lilly Wachowski",
+		Status:      "watched",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Action", "Science Fiction"}, matrix.Genres)
+	assert.Equal(t, "Action, Science Fiction", matrix.Genre)
+	assert.Equal(t, 2, len(matrix.Credits))
+	assert.Equal(t, "Lilly Wachowski", matrix.Credits[1].Name)
+	assert.NotEqual(t, 0, matrix.Credits[1].PersonID)
+
+	// arrays fill the legacy strings
+	cloudAtlas, err := repo.AddWatchList(models.Watchlist{
+		Title:       "Cloud Atlas",
+		ReleaseYear: 2012,
+		Genres:      []string{"Drama", "science fiction"},
+		Credits: []models.Credit{
+			{Name: "Lana Wachowski", Role: "director"},
+			{Name: "Tom Tykwer", Role: "director"},
+			{Name: "Lana Wachowski", Role: "writer"},
+			{Name: "Tom Hanks", Role: "actor"},
+		},
+		Status: "not watched",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Drama, science fiction", cloudAtlas.Genre)
+	assert.Equal(t, "Lana Wachowski, Tom Tykwer", cloudAtlas.Director)
+
+	found, err := repo.GetWatchListById(strconv.Itoa(cloudAtlas.WatchlistID))
+	assert.NoError(t, err)
+	// names are shared case-insensitively, the first spelling is kept
+	assert.Equal(t, []string{"Drama", "Science Fiction"}, found.Genres)
+	assert.Equal(t, 4, len(found.Credits))
+	assert.Equal(t, matrix.Credits[0].PersonID, found.Credits[0].PersonID)
+
+	// the legacy director string only replaces the directors
+	rowsAffected, err := repo.UpdateWatchList(models.WatchListUpdateRequest{
+		WatchlistID: cloudAtlas.WatchlistID,
+		Title:       "Cloud Atlas",
+		ReleaseYear: 2012,
+		Genre:       "Drama",
+		Director:    "Tom Tykwer",
+		Status:      "watched",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowsAffected)
+
+	found, err = repo.GetWatchListById(strconv.Itoa(cloudAtlas.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Drama"}, found.Genres)
+	assert.Equal(t, "Tom Tykwer", found.Director)
+	assert.Equal(t, 3, len(found.Credits))
+
+	genreRepo := &repositories.GenreModel{DB: db.DB}
+	genres, err := genreRepo.GetGenres()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(genres))
+	assert.Equal(t, "Action", genres[0].Name)
+	assert.Equal(t, 1, genres[0].WatchListCount)
+	assert.Equal(t, "Drama", genres[1].Name)
+
+	dramas, err := genreRepo.GetWatchListByGenre(strconv.Itoa(genres[1].GenreID))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dramas))
+	assert.Equal(t, "Cloud Atlas", dramas[0].Title)
+
+	_, err = genreRepo.GetWatchListByGenre("999")
+	assert.Error(t, err)
+
+	personRepo := &repositories.PersonModel{DB: db.DB}
+	lana := strconv.Itoa(matrix.Credits[0].PersonID)
+
+	person, err := personRepo.GetPersonById(lana)
+	assert.NoError(t, err)
+	assert.Equal(t, "Lana Wachowski", person.Name)
+
+	// still credited as writer on Cloud Atlas
+	credited, err := personRepo.GetWatchListByPerson(lana, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(credited))
+
+	directed, err := personRepo.GetWatchListByPerson(lana, "director")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(directed))
+	assert.Equal(t, "The Matrix", directed[0].Title)
+
+	// delete removes the relations with the entry
+	_, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: matrix.WatchlistID})
+	assert.NoError(t, err)
+
+	directed, err = personRepo.GetWatchListByPerson(lana, "director")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(directed))
+}
@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.IdempotencyModel{DB: db.DB}
+	scope := "POST /api/v1/watchlist/add "
+
+	// the first request claims the key, a retry while it runs is told so
+	_, found, err := repo.BeginIdempotentRequest(scope, "key-1", "fingerprint-1", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = repo.BeginIdempotentRequest(scope, "key-1", "fingerprint-1", time.Hour)
+	assert.ErrorIs(t, err, repositories.ErrIdempotencyKeyInProgress)
+
+	// the stored response is returned to a retry with the same request
+	stored := models.IdempotentResponse{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}, "Etag": {`"1"`}},
+		Body:   []byte(`{"watchlist_id":1}`),
+	}
+	err = repo.CompleteIdempotentRequest(scope, "key-1", stored)
+	assert.NoError(t, err)
+
+	replayed, found, err := repo.BeginIdempotentRequest(scope, "key-1", "fingerprint-1", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, stored, replayed)
+
+	// the key belongs to its request
+	_, _, err = repo.BeginIdempotentRequest(scope, "key-1", "fingerprint-2", time.Hour)
+	assert.ErrorIs(t, err, repositories.ErrIdempotencyKeyReused)
+
+	// the same key sent to another route or by another user is another key
+	_, found, err = repo.BeginIdempotentRequest("POST /api/v1/watchlist/add saket", "key-1", "fingerprint-2", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// a released key runs again, a completed one is kept
+	_, _, err = repo.BeginIdempotentRequest(scope, "key-2", "fingerprint-2", time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.ReleaseIdempotencyKey(scope, "key-2"))
+	assert.NoError(t, repo.ReleaseIdempotencyKey(scope, "key-1"))
+
+	_, found, err = repo.BeginIdempotentRequest(scope, "key-2", "fingerprint-3", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = repo.BeginIdempotentRequest(scope, "key-1", "fingerprint-1", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	// an expired key is claimed again by any request and purged
+	_, _, err = repo.BeginIdempotentRequest(scope, "key-3", "fingerprint-3", -time.Second)
+	assert.NoError(t, err)
+
+	_, found, err = repo.BeginIdempotentRequest(scope, "key-3", "fingerprint-4", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = repo.BeginIdempotentRequest(scope, "key-4", "fingerprint-4", -time.Second)
+	assert.NoError(t, err)
+
+	purged, err := repo.PurgeIdempotencyKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	var count int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM idempotency_keys;`).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
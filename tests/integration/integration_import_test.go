@@ -0,0 +1,209 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/importer"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// readTraktFixture loads one of the files in tests/testdata/trakt
+func readTraktFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "trakt", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// importStores are the repositories of the test database an import writes to
+func importStores(db *sql.DB) importer.Stores {
+	return importer.Stores{
+		WatchLists: &repositories.WatchListModel{DB: db},
+		Reviews:    &repositories.ReviewModel{DB: db},
+		Viewings:   &repositories.ViewingModel{DB: db},
+	}
+}
+
+// runImportJob queues the items as a "watchlist.import" job and runs it like the worker pool does
+func runImportJob(t *testing.T, db *sql.DB, items []models.ImportItem) models.ImportJob {
+	t.Helper()
+	jobModel := &repositories.JobModel{DB: db}
+	pool := jobs.NewPool(jobModel, 1)
+	pool.Register(jobs.WatchListImportKind, jobs.NewWatchListImportHandler(importStores(db), jobModel))
+
+	payload, err := json.Marshal(models.ImportPayload{Source: "trakt", Items: items})
+	assert.NoError(t, err)
+	queued, err := jobModel.EnqueueJob(jobs.WatchListImportKind, payload, 0)
+	assert.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	job, err := jobModel.GetJobById(strconv.Itoa(queued.JobID))
+	assert.NoError(t, err)
+	importJob, err := importer.ImportJob(job)
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", importJob.State)
+	return importJob
+}
+
+func TestParseTraktExport(t *testing.T) {
+	items, err := importer.ParseTraktExport(
+		readTraktFixture(t, "history.json"),
+		readTraktFixture(t, "watchlist.json"),
+		readTraktFixture(t, "ratings.json"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(items))
+
+	// Inception shows up in every file but is returned once
+	assert.Equal(t, "Inception", items[0].Title)
+	assert.Equal(t, 2010, items[0].ReleaseYear)
+	assert.Equal(t, "watched", items[0].Status)
+	assert.Equal(t, "tt1375666", items[0].IMDbID)
+	assert.Equal(t, 27205, items[0].TMDbID)
+	assert.Equal(t, 9, items[0].Rating)
+	// watched twice
+	if assert.Len(t, items[0].WatchDates, 2) {
+		assert.Equal(t, 2024, items[0].WatchDates[0].Year())
+		assert.Equal(t, 2025, items[0].WatchDates[1].Year())
+	}
+
+	// episodes are mapped to their show
+	assert.Equal(t, "Stranger Things", items[1].Title)
+	assert.Equal(t, "watched", items[1].Status)
+
+	assert.Equal(t, "Dune: Part Two", items[2].Title)
+	assert.Equal(t, "not watched", items[2].Status)
+
+	assert.Equal(t, "Parasite", items[3].Title)
+	assert.Equal(t, "watched", items[3].Status)
+}
+
+func TestParseTraktExportInvalid(t *testing.T) {
+	_, err := importer.ParseTraktExport(nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = importer.ParseTraktExport([]byte(`{"not": "a list"}`), nil, nil)
+	assert.Error(t, err)
+}
+
+func TestTraktImportSkipsDuplicates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.WatchListModel{
+		DB: db.DB,
+	}
+
+	// already tracked, with a different case
+	_, err := repo.AddWatchList(models.Watchlist{
+		Title:       "parasite",
+		ReleaseYear: 2019,
+		Genre:       "Thriller",
+		Director:    "Bong Joon Ho",
+		Status:      "not watched",
+	})
+	assert.NoError(t, err)
+
+	items, err := importer.ParseTraktExport(
+		readTraktFixture(t, "history.json"),
+		readTraktFixture(t, "watchlist.json"),
+		readTraktFixture(t, "ratings.json"),
+	)
+	assert.NoError(t, err)
+
+	job := runImportJob(t, db.DB, items)
+	assert.Equal(t, 4, job.Total)
+	assert.Equal(t, 4, job.Processed)
+	assert.Equal(t, 3, job.Imported)
+	assert.Equal(t, 1, job.Duplicates)
+	assert.Equal(t, 0, job.Failed)
+	assert.NotNil(t, job.FinishedAt)
+
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(watchlists))
+
+	// the rating is a review in half stars and each watch a viewing
+	inception, found, err := repo.FindDuplicateWatchList("Inception", 2010, models.ExternalIDs{})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	inceptionID := strconv.Itoa(inception.WatchlistID)
+
+	review, err := (&repositories.ReviewModel{DB: db.DB}).GetReview(inceptionID)
+	assert.NoError(t, err)
+	assert.Equal(t, 4.5, review.Rating)
+
+	viewings, err := (&repositories.ViewingModel{DB: db.DB}).GetViewings(inceptionID)
+	assert.NoError(t, err)
+	if assert.Len(t, viewings, 2) {
+		assert.Equal(t, "2024-11-02", viewings[0].WatchedOn.Format(time.DateOnly))
+		assert.Equal(t, "2025-01-12", viewings[1].WatchedOn.Format(time.DateOnly))
+		assert.True(t, viewings[1].Rewatch)
+	}
+
+	// the watchlist file has neither
+	dune, found, err := repo.FindDuplicateWatchList("Dune: Part Two", 2024, models.ExternalIDs{})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	_, err = (&repositories.ReviewModel{DB: db.DB}).GetReview(strconv.Itoa(dune.WatchlistID))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// running the same import again only finds duplicates
+	job = runImportJob(t, db.DB, items)
+	assert.Equal(t, 0, job.Imported)
+	assert.Equal(t, 4, job.Duplicates)
+}
+
+func TestImportJobResumes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+	jobModel := &repositories.JobModel{DB: db.DB}
+	pool := jobs.NewPool(jobModel, 1)
+	pool.Register(jobs.WatchListImportKind, jobs.NewWatchListImportHandler(importStores(db.DB), jobModel))
+
+	payload, err := json.Marshal(models.ImportPayload{Source: "trakt", Items: []models.ImportItem{
+		{Title: "Coco", ReleaseYear: 2017, Status: "watched"},
+		{Title: "Up", ReleaseYear: 2009, Status: "watched"},
+	}})
+	assert.NoError(t, err)
+	queued, err := jobModel.EnqueueJob(jobs.WatchListImportKind, payload, 0)
+	assert.NoError(t, err)
+
+	// a server stopped after the first item, the requeued job goes on with the second one
+	err = jobModel.SaveJobResult(queued.JobID, json.RawMessage(`{"processed": 1, "imported": 1, "errors": []}`))
+	assert.NoError(t, err)
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	job, err := jobModel.GetJobById(strconv.Itoa(queued.JobID))
+	assert.NoError(t, err)
+	importJob, err := importer.ImportJob(job)
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", importJob.State)
+	assert.Equal(t, 2, importJob.Processed)
+	assert.Equal(t, 2, importJob.Imported)
+
+	watchLists, err := repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	if assert.Len(t, watchLists, 1) {
+		assert.Equal(t, "Up", watchLists[0].Title)
+	}
+}
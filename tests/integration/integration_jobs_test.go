@@ -0,0 +1,386 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/jobs"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupJobsTable returns the job repository of the test database
+// the pool runs on other goroutines, so every query must share the single in-memory connection
+func setupJobsTable(t *testing.T, db *database.Database) *repositories.JobModel {
+	db.DB.SetMaxOpenConns(1)
+
+	return &repositories.JobModel{DB: db.DB}
+}
+
+func getJob(t *testing.T, repo *repositories.JobModel, jobID int) models.Job {
+	job, err := repo.GetJobById(strconv.Itoa(jobID))
+	if err != nil {
+		t.Fatalf("Failed to get job %d: %v", jobID, err)
+	}
+	return job
+}
+
+func TestJobPoolRunsJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+	pool := jobs.NewPool(repo, 1)
+
+	var received models.WatchListRefreshPayload
+	pool.Register("test.echo", func(ctx context.Context, job models.Job) error {
+		return json.Unmarshal(job.Payload, &received)
+	})
+
+	job, err := repo.EnqueueJob("test.echo", json.RawMessage(`{"watchlist_id": 7}`), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", job.State)
+	assert.Equal(t, 5, job.MaxAttempts)
+
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 7, received.WatchlistID)
+
+	job = getJob(t, repo, job.JobID)
+	assert.Equal(t, "succeeded", job.State)
+	assert.Equal(t, 1, job.Attempts)
+	assert.NotNil(t, job.FinishedAt)
+
+	// queue is empty now
+	ran, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, ran)
+}
+
+func TestJobPoolRetriesThenDeadLetters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+	pool := jobs.NewPool(repo, 1)
+	pool.Register("test.fail", func(ctx context.Context, job models.Job) error {
+		return errors.New("provider unavailable")
+	})
+
+	job, err := repo.EnqueueJob("test.fail", nil, 2)
+	assert.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	job = getJob(t, repo, job.JobID)
+	assert.Equal(t, "queued", job.State)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "provider unavailable", job.LastError)
+	assert.True(t, job.RunAt.After(time.Now()))
+
+	// not due yet because of the backoff
+	ran, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, ran)
+
+	_, err = db.DB.Exec(`UPDATE jobs SET run_at = ? WHERE job_id = ?;`, time.Now().Add(-time.Second).UTC(), job.JobID)
+	assert.NoError(t, err)
+
+	ran, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	job = getJob(t, repo, job.JobID)
+	assert.Equal(t, "dead", job.State)
+	assert.Equal(t, 2, job.Attempts)
+	assert.NotNil(t, job.FinishedAt)
+
+	deadJobs, err := repo.GetJobs(models.JobQuery{State: "dead"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deadJobs))
+}
+
+func TestJobPoolBackoff(t *testing.T) {
+	pool := jobs.NewPool(nil, 1)
+	pool.BaseBackoff = time.Second
+	pool.MaxBackoff = 5 * time.Second
+
+	assert.Equal(t, time.Second, pool.Backoff(1))
+	assert.Equal(t, 2*time.Second, pool.Backoff(2))
+	assert.Equal(t, 4*time.Second, pool.Backoff(3))
+	assert.Equal(t, 5*time.Second, pool.Backoff(4))
+}
+
+func TestJobPoolUnknownKindAndPanics(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+	pool := jobs.NewPool(repo, 1)
+	pool.Register("test.panic", func(ctx context.Context, job models.Job) error {
+		panic("boom")
+	})
+
+	unknown, err := repo.EnqueueJob("test.unknown", nil, 3)
+	assert.NoError(t, err)
+	panicking, err := repo.EnqueueJob("test.panic", nil, 1)
+	assert.NoError(t, err)
+
+	_, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	_, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+
+	unknown = getJob(t, repo, unknown.JobID)
+	assert.Equal(t, "dead", unknown.State)
+	assert.Contains(t, unknown.LastError, "no handler registered")
+
+	panicking = getJob(t, repo, panicking.JobID)
+	assert.Equal(t, "dead", panicking.State)
+	assert.Contains(t, panicking.LastError, "boom")
+}
+
+func TestJobPoolCancel(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+	pool := jobs.NewPool(repo, 1)
+	pool.PollInterval = 10 * time.Millisecond
+
+	started := make(chan int, 1)
+	pool.Register("test.block", func(ctx context.Context, job models.Job) error {
+		started <- job.JobID
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// a queued job is never picked up once cancelled
+	queued, err := repo.EnqueueJob("test.block", nil, 0)
+	assert.NoError(t, err)
+	rowAffected, err := repo.CancelJob(strconv.Itoa(queued.JobID))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	running, err := repo.EnqueueJob("test.block", nil, 0)
+	assert.NoError(t, err)
+
+	err = pool.Start(context.Background())
+	assert.NoError(t, err)
+	defer pool.Stop()
+
+	select {
+	case jobID := <-started:
+		assert.Equal(t, running.JobID, jobID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not start")
+	}
+
+	rowAffected, err = repo.CancelJob(strconv.Itoa(running.JobID))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+	pool.Cancel(running.JobID)
+
+	// the handler error does not turn the cancelled job into a retry
+	assert.Eventually(t, func() bool {
+		return getJob(t, repo, running.JobID).State == "cancelled"
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "cancelled", getJob(t, repo, running.JobID).State)
+	assert.Equal(t, "cancelled", getJob(t, repo, queued.JobID).State)
+
+	// finished jobs can not be cancelled
+	rowAffected, err = repo.CancelJob(strconv.Itoa(running.JobID))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rowAffected)
+}
+
+func TestJobPoolRequeuesInterruptedJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+
+	job, err := repo.EnqueueJob("test.echo", nil, 0)
+	assert.NoError(t, err)
+
+	// simulate a server stopped mid-job
+	_, found, err := repo.ClaimNextJob(time.Now())
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "running", getJob(t, repo, job.JobID).State)
+
+	done := make(chan struct{})
+	pool := jobs.NewPool(repo, 2)
+	pool.PollInterval = 10 * time.Millisecond
+	pool.Register("test.echo", func(ctx context.Context, job models.Job) error {
+		close(done)
+		return nil
+	})
+
+	err = pool.Start(context.Background())
+	assert.NoError(t, err)
+	defer pool.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("interrupted job was not requeued")
+	}
+
+	assert.Eventually(t, func() bool {
+		return getJob(t, repo, job.JobID).State == "succeeded"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, getJob(t, repo, job.JobID).Attempts)
+}
+
+// stubProvider returns the same metadata for every title
+type stubProvider struct{}
+
+func (stubProvider) Lookup(ctx context.Context, title string, year int) (models.Metadata, error) {
+	return models.Metadata{
+		Provider:       "tmdb",
+		Title:          title,
+		ReleaseYear:    2021,
+		Genres:         []string{"Action", "Thriller"},
+		Directors:      []string{"Refreshed Director"},
+		RuntimeMinutes: 136,
+		PosterPath:     "/poster.jpg",
+	}, nil
+}
+
+func TestWatchListRefreshJob(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := setupJobsTable(t, db)
+	watchListRepo := &repositories.WatchListModel{DB: db.DB}
+
+	pool := jobs.NewPool(repo, 1)
+	pool.Register(jobs.WatchListRefreshKind, jobs.NewWatchListRefreshHandler(watchListRepo, stubProvider{}))
+
+	job, err := repo.EnqueueJob(jobs.WatchListRefreshKind, json.RawMessage(`{"watchlist_id": 1}`), 0)
+	assert.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, "succeeded", getJob(t, repo, job.JobID).State)
+
+	refreshed, err := watchListRepo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Movie 1", refreshed.Title)
+	assert.Equal(t, "Action, Thriller", refreshed.Genre)
+	assert.Equal(t, "Refreshed Director", refreshed.Director)
+	assert.Equal(t, "watched", refreshed.Status)
+	assert.Equal(t, 136, refreshed.Runtime)
+	assert.Equal(t, "/poster.jpg", refreshed.PosterPath)
+
+	// missing entries fail and are retried
+	job, err = repo.EnqueueJob(jobs.WatchListRefreshKind, json.RawMessage(`{"watchlist_id": 999}`), 0)
+	assert.NoError(t, err)
+
+	_, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", getJob(t, repo, job.JobID).State)
+}
+
+func TestJobPoolSchedulesJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+	pool := jobs.NewPool(repo, 1)
+	pool.PollInterval = 10 * time.Millisecond
+
+	ran := make(chan struct{}, 10)
+	pool.Register("test.tick", func(ctx context.Context, job models.Job) error {
+		ran <- struct{}{}
+		return nil
+	})
+	pool.Every("test.tick", 20*time.Millisecond)
+
+	err := pool.Start(context.Background())
+	assert.NoError(t, err)
+	defer pool.Stop()
+
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the scheduled job did not run")
+	}
+
+	scheduled, err := repo.GetJobs(models.JobQuery{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, scheduled)
+	assert.Equal(t, "test.tick", scheduled[0].Kind)
+}
+
+func TestJobPurgeAndPages(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := setupJobsTable(t, db)
+	pool := jobs.NewPool(repo, 1)
+	pool.Register("test.ok", func(ctx context.Context, job models.Job) error { return nil })
+	pool.Register(jobs.JobPurgeKind, jobs.NewJobPurgeHandler(repo, time.Hour))
+
+	succeeded, err := repo.EnqueueJob("test.ok", nil, 0)
+	assert.NoError(t, err)
+	ran, err := pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	cancelled, err := repo.EnqueueJob("test.ok", nil, 0)
+	assert.NoError(t, err)
+	_, err = repo.CancelJob(strconv.Itoa(cancelled.JobID))
+	assert.NoError(t, err)
+	dead, err := repo.EnqueueJob("test.ok", nil, 0)
+	assert.NoError(t, err)
+	_, err = db.DB.Exec(`UPDATE jobs SET state = 'dead', finished_at = ? WHERE job_id = ?;`, time.Now().UTC(), dead.JobID)
+	assert.NoError(t, err)
+	queued, err := repo.EnqueueJob("test.ok", nil, 0)
+	assert.NoError(t, err)
+	_, err = db.DB.Exec(`UPDATE jobs SET run_at = ? WHERE job_id = ?;`, time.Now().Add(time.Hour).UTC(), queued.JobID)
+	assert.NoError(t, err)
+
+	// the page is newest first
+	page, err := repo.GetJobs(models.JobQuery{Limit: 2, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, dead.JobID, page[0].JobID)
+		assert.Equal(t, cancelled.JobID, page[1].JobID)
+	}
+
+	// the jobs finished within the retention are kept
+	purged, err := repo.PurgeJobs(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	// the succeeded and cancelled jobs are purged, dead and queued ones stay
+	_, err = db.DB.Exec(`UPDATE jobs SET finished_at = ? WHERE finished_at IS NOT NULL;`, time.Now().Add(-2*time.Hour).UTC())
+	assert.NoError(t, err)
+	_, err = repo.EnqueueJob(jobs.JobPurgeKind, nil, 0)
+	assert.NoError(t, err)
+	ran, err = pool.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ran)
+
+	_, err = repo.GetJobById(strconv.Itoa(succeeded.JobID))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.GetJobById(strconv.Itoa(cancelled.JobID))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, "dead", getJob(t, repo, dead.JobID).State)
+	assert.Equal(t, "queued", getJob(t, repo, queued.JobID).State)
+}
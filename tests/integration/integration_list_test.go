@@ -0,0 +1,215 @@
+package integration
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func listOrder(list models.List) []int {
+	ids := []int{}
+	for i, entry := range list.Entries {
+		if entry.Position != i+1 {
+			return nil
+		}
+		ids = append(ids, entry.Watchlist.WatchlistID)
+	}
+	return ids
+}
+
+func TestLists(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	watchListRepo := &repositories.WatchListModel{DB: db.DB}
+	repo := &repositories.ListModel{DB: db.DB}
+
+	list, err := repo.AddList(models.ListRequest{Name: " Horror October ", Description: "One scary movie a night"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Horror October", list.Name)
+	assert.Equal(t, "private", list.Visibility)
+	assert.NotEmpty(t, list.ShareToken)
+	listID := strconv.Itoa(list.ListID)
+
+	_, err = repo.AddList(models.ListRequest{Name: "horror october"})
+	assert.ErrorIs(t, err, repositories.ErrListExists)
+
+	// entries go to the end unless a position is given
+	for _, entry := range []models.ListEntryRequest{{WatchlistID: 1}, {WatchlistID: 2}, {WatchlistID: 3, Position: position(1)}} {
+		list, err = repo.AddListEntry(listID, entry)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []int{3, 1, 2}, listOrder(list))
+	assert.Equal(t, 3, list.EntryCount)
+
+	_, err = repo.AddListEntry(listID, models.ListEntryRequest{WatchlistID: 1})
+	assert.ErrorIs(t, err, repositories.ErrListEntryExists)
+
+	_, err = repo.AddListEntry(listID, models.ListEntryRequest{WatchlistID: 999})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = repo.AddListEntry("999", models.ListEntryRequest{WatchlistID: 1})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// an entry lives in several lists without being copied
+	other, err := repo.AddList(models.ListRequest{Name: "Oscar Best Pictures", Visibility: "public"})
+	assert.NoError(t, err)
+	other, err = repo.AddListEntry(strconv.Itoa(other.ListID), models.ListEntryRequest{WatchlistID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, listOrder(other))
+
+	// reorder
+	list, err = repo.ReorderList(listID, []int{2, 3, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 1}, listOrder(list))
+
+	for _, ids := range [][]int{{2, 3}, {2, 3, 3}, {2, 3, 999}} {
+		_, err = repo.ReorderList(listID, ids)
+		assert.ErrorIs(t, err, repositories.ErrListOrder, ids)
+	}
+
+	// removing an entry closes the gap
+	rowAffected, err := repo.RemoveListEntry(listID, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	list, err = repo.GetListById(listID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, listOrder(list))
+
+	// deleting a watchlist entry removes it from every list
+	_, err = watchListRepo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 2})
+	assert.NoError(t, err)
+
+	list, err = repo.GetListById(listID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, listOrder(list))
+
+	// visibility
+	_, err = repo.GetSharedList(list.ShareToken)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	unlisted := "unlisted"
+	list, err = repo.UpdateList(listID, models.ListUpdateRequest{Visibility: &unlisted})
+	assert.NoError(t, err)
+	assert.Equal(t, "One scary movie a night", list.Description)
+
+	shared, err := repo.GetSharedList(list.ShareToken)
+	assert.NoError(t, err)
+	assert.Equal(t, list.ListID, shared.ListID)
+
+	public, err := repo.GetPublicLists()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(public))
+	assert.Equal(t, "Oscar Best Pictures", public[0].Name)
+
+	name := "oscar best pictures"
+	_, err = repo.UpdateList(listID, models.ListUpdateRequest{Name: &name})
+	assert.ErrorIs(t, err, repositories.ErrListExists)
+
+	_, err = repo.UpdateList("999", models.ListUpdateRequest{Visibility: &unlisted})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// deleting a list keeps its entries
+	rowAffected, err = repo.DeleteList(listID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	_, err = watchListRepo.GetWatchListById("1")
+	assert.NoError(t, err)
+
+	lists, err := repo.GetLists()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(lists))
+}
+
+func TestSmartLists(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	tagRepo := &repositories.TagModel{DB: db.DB}
+	repo := &repositories.ListModel{DB: db.DB}
+
+	_, err := tagRepo.TagWatchLists([]int{2, 3}, []string{"date night"})
+	assert.NoError(t, err)
+
+	// not watched or watching since 2022, newest first
+	list, err := repo.AddList(models.ListRequest{Name: "Up Next", Pinned: true, Filter: &models.SmartFilter{
+		Statuses: []string{"not watched", "watching"},
+		MinYear:  2022,
+		Sort:     "-release_year",
+	}})
+	assert.NoError(t, err)
+	assert.True(t, list.Smart)
+	assert.Equal(t, 2, list.EntryCount)
+	listID := strconv.Itoa(list.ListID)
+
+	page, err := repo.GetListEntries(listID, models.PageQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, 20, page.PageSize)
+	assert.Equal(t, 3, page.Entries[0].Watchlist.WatchlistID)
+	assert.Equal(t, 2, page.Entries[1].Position)
+	assert.Nil(t, page.Entries[0].AddedAt)
+
+	// the filter is evaluated on every read
+	_, err = db.DB.Exec(`UPDATE Watchlist SET status = 'watched' WHERE watchlist_id = 3;`)
+	assert.NoError(t, err)
+
+	page, err = repo.GetListEntries(listID, models.PageQuery{Page: 1, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, 2, page.Entries[0].Watchlist.WatchlistID)
+
+	// tags and title, paginated
+	tagged, err := repo.AddList(models.ListRequest{Name: "Date Night", Filter: &models.SmartFilter{Tags: []string{"Date Night"}, Title: "movie", Sort: "title"}})
+	assert.NoError(t, err)
+
+	page, err = repo.GetListEntries(strconv.Itoa(tagged.ListID), models.PageQuery{Page: 2, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, 1, len(page.Entries))
+	assert.Equal(t, 3, page.Entries[0].Watchlist.WatchlistID)
+	assert.Equal(t, 2, page.Entries[0].Position)
+
+	// LIKE wildcards are matched literally
+	literal, err := repo.AddList(models.ListRequest{Name: "Percent", Filter: &models.SmartFilter{Title: "%"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, literal.EntryCount)
+
+	// pinned lists come first
+	_, err = repo.AddList(models.ListRequest{Name: "A Static List"})
+	assert.NoError(t, err)
+
+	lists, err := repo.GetLists()
+	assert.NoError(t, err)
+	assert.Equal(t, "Up Next", lists[0].Name)
+	assert.Equal(t, "A Static List", lists[1].Name)
+
+	// smart lists have no manual entries and static lists no filter
+	_, err = repo.AddListEntry(listID, models.ListEntryRequest{WatchlistID: 1})
+	assert.ErrorIs(t, err, repositories.ErrListKind)
+
+	_, err = repo.UpdateList(strconv.Itoa(lists[1].ListID), models.ListUpdateRequest{Filter: &models.SmartFilter{MinYear: 2000}})
+	assert.ErrorIs(t, err, repositories.ErrListKind)
+
+	_, err = repo.AddList(models.ListRequest{Name: "Broken", Filter: &models.SmartFilter{MinYear: 2020, MaxYear: 2010}})
+	assert.ErrorIs(t, err, repositories.ErrInvalidFilter)
+
+	_, err = repo.AddList(models.ListRequest{Name: "Broken", Filter: &models.SmartFilter{Statuses: []string{"finished"}}})
+	assert.ErrorIs(t, err, repositories.ErrInvalidFilter)
+
+	unpinned := false
+	list, err = repo.UpdateList(listID, models.ListUpdateRequest{Pinned: &unpinned, Filter: &models.SmartFilter{Statuses: []string{"watched"}}})
+	assert.NoError(t, err)
+	assert.False(t, list.Pinned)
+	assert.Equal(t, 2, list.EntryCount)
+}
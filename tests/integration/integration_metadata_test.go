@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/metadata"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeTMDbServer stands in for api.themoviedb.org and counts the requests it serves
+func newFakeTMDbServer(t *testing.T, requests *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		assert.Equal(t, "test-key", r.URL.Query().Get("api_key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.EqualFold(r.URL.Query().Get("query"), "coco") {
+			_, _ = w.Write([]byte(`{"page": 1, "results": []}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"page": 1, "results": [{"id": 354912, "title": "Coco"}]}`))
+	})
+	mux.HandleFunc("/movie/354912", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		assert.Equal(t, "credits,external_ids", r.URL.Query().Get("append_to_response"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": 354912,
+			"title": "Coco",
+			"release_date": "2017-10-27",
+			"runtime": 105,
+			"poster_path": "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg",
+			"genres": [{"id": 16, "name": "Animation"}, {"id": 10751, "name": "Family"}],
+			"credits": {"crew": [
+				{"name": "Lee Unkrich", "job": "Director"},
+				{"name": "Adrian Molina", "job": "Screenplay"}
+			]},
+			"external_ids": {"imdb_id": "tt2380307", "wikidata_id": "Q27188178"}
+		}`))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+// setupMetadataCache returns the metadata cache repository of the test database
+func setupMetadataCache(t *testing.T, db *database.Database) *repositories.MetadataCacheModel {
+	return &repositories.MetadataCacheModel{DB: db.DB}
+}
+
+func TestTMDbProviderLookup(t *testing.T) {
+	var requests int32
+	server := newFakeTMDbServer(t, &requests)
+	defer server.Close()
+
+	provider := metadata.NewTMDbProvider("test-key", nil)
+	provider.BaseURL = server.URL
+	provider.HTTPClient = server.Client()
+
+	found, err := provider.Lookup(context.Background(), "coco", 2017)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "tmdb", found.Provider)
+	assert.Equal(t, "Coco", found.Title)
+	assert.Equal(t, 2017, found.ReleaseYear)
+	assert.Equal(t, []string{"Animation", "Family"}, found.Genres)
+	assert.Equal(t, []string{"Lee Unkrich"}, found.Directors)
+	assert.Equal(t, 105, found.RuntimeMinutes)
+	assert.Equal(t, "/gGEsBPAijhVUFoiNpgZXqRVWJt2.jpg", found.PosterPath)
+	assert.Equal(t, 354912, found.ExternalIDs.TMDbID)
+	assert.Equal(t, "tt2380307", found.ExternalIDs.IMDbID)
+	assert.Equal(t, "Q27188178", found.ExternalIDs.WikidataID)
+
+	_, err = provider.Lookup(context.Background(), "does not exist", 0)
+	assert.ErrorIs(t, err, metadata.ErrNotFound)
+}
+
+func TestTMDbProviderCachesResponses(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	var requests int32
+	server := newFakeTMDbServer(t, &requests)
+	defer server.Close()
+
+	provider := metadata.NewTMDbProvider("test-key", setupMetadataCache(t, db))
+	provider.BaseURL = server.URL
+	provider.HTTPClient = server.Client()
+
+	first, err := provider.Lookup(context.Background(), "Coco", 2017)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// same title, different case, is served from SQLite
+	second, err := provider.Lookup(context.Background(), "COCO", 2017)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, first, second)
+
+	// not found responses are not cached
+	_, err = provider.Lookup(context.Background(), "does not exist", 0)
+	assert.ErrorIs(t, err, metadata.ErrNotFound)
+	_, err = provider.Lookup(context.Background(), "does not exist", 0)
+	assert.ErrorIs(t, err, metadata.ErrNotFound)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+}
+
+func TestMetadataCacheExpiry(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	cache := setupMetadataCache(t, db)
+
+	_, found, err := cache.GetMetadata("tmdb:coco:2017", 0)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = db.DB.Exec(`INSERT INTO metadata_cache (cache_key, provider, response, fetched_at) VALUES (?, ?, ?, ?);`,
+		"tmdb:coco:2017", "tmdb", `{"provider": "tmdb", "title": "Coco"}`, time.Now().Add(-48*time.Hour).UTC())
+	assert.NoError(t, err)
+
+	cached, found, err := cache.GetMetadata("tmdb:coco:2017", 0)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Coco", cached.Title)
+
+	_, found, err = cache.GetMetadata("tmdb:coco:2017", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/saketV8/cine-dots/tests/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrationsBackfill runs the migrations over their sample data, the table rebuilds and backfills keep it
+func TestMigrationsBackfill(t *testing.T) {
+	db, err := database.InitializeDatabase("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.DB.Close()
+
+	testutil.Migrate(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+	watchLists, err := repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, watchLists, 5)
+
+	matrix, err := repo.GetWatchListByExternalId("imdb", "tt0133093")
+	assert.NoError(t, err)
+	assert.Equal(t, "The Matrix", matrix.Title)
+	assert.Equal(t, []string{"Action"}, matrix.Genres)
+	assert.Len(t, matrix.Credits, 2)
+	assert.Equal(t, "Lana Wachowski, Lilly Wachowski", matrix.Director)
+	assert.NotNil(t, matrix.StartedAt)
+	assert.Nil(t, matrix.FinishedAt)
+	assert.Nil(t, matrix.DeletedAt)
+
+	inception, err := repo.GetWatchListByExternalId("imdb", "tt1375666")
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", inception.Status)
+	assert.NotNil(t, inception.FinishedAt)
+
+	// the migrations after the first one can be rolled back and applied again
+	err = goose.DownTo(db.DB, testutil.MigrationsDir(), 20250620083647)
+	assert.NoError(t, err)
+	testutil.Migrate(t, db.DB)
+
+	watchLists, err = repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, watchLists, 5)
+}
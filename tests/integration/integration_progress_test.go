@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func position(seconds int) *int {
+	return &seconds
+}
+
+func TestWatchListProgress(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB, CompletionThreshold: 0.95}
+
+	// the first position starts the entry
+	watchList, err := repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(1200), Runtime: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.Equal(t, 1200, watchList.PositionSeconds)
+	assert.Equal(t, 100, watchList.Runtime)
+	assert.NotNil(t, watchList.ProgressUpdatedAt)
+	updatedAt := *watchList.ProgressUpdatedAt
+
+	// replays and older positions change nothing
+	watchList, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(1200)})
+	assert.NoError(t, err)
+	assert.True(t, watchList.ProgressUpdatedAt.Equal(updatedAt))
+
+	watchList, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(600)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1200, watchList.PositionSeconds)
+
+	// unless reset
+	watchList, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(600), Reset: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 600, watchList.PositionSeconds)
+
+	// 95% of 100 minutes is 5700 seconds
+	watchList, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(5600)})
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+
+	watchList, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(5700)})
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchList.Status)
+	assert.NotNil(t, watchList.FinishedAt)
+
+	// a reset on a watched entry is a rewatch
+	watchList, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: position(60), Reset: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+
+	// without a runtime the entry is never finished automatically
+	watchList, err = repo.UpdateProgress("2", models.ProgressRequest{PositionSeconds: position(90000)})
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+
+	_, err = repo.UpdateProgress("999", models.ProgressRequest{PositionSeconds: position(1)})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// continue watching is the watching list, latest progress first
+	watchLists, err := repo.GetWatchingList(models.WatchListQuery{Sort: "-progress_updated_at"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchLists))
+	assert.Equal(t, "Test Movie 2", watchLists[0].Title)
+	assert.Equal(t, "Test Movie 3", watchLists[1].Title)
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/query"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func watchListIds(watchLists []models.Watchlist) []int {
+	ids := []int{}
+	for _, watchList := range watchLists {
+		ids = append(ids, watchList.WatchlistID)
+	}
+	return ids
+}
+
+func TestWatchListQuery(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	for _, watchList := range []models.Watchlist{
+		{Title: "The Matrix", ReleaseYear: 1999, Genres: []string{"Action", "Science Fiction"}, Director: "Lana Wachowski", Status: "watched", Tags: []string{"classics"}},
+		{Title: "Heat", ReleaseYear: 1995, Genres: []string{"Action", "Crime"}, Director: "Michael Mann", Status: "not watched", Runtime: 170},
+		{Title: "Arrival", ReleaseYear: 2016, Genres: []string{"Science Fiction"}, Director: "Denis Villeneuve", Status: "watched", Notes: "50% linguistics"},
+		{Title: "Dune: Part One", ReleaseYear: 2021, Genres: []string{"Science Fiction"}, Director: "Denis Villeneuve", Status: "dropped", Kind: "movie"},
+	} {
+		_, err := repo.AddWatchList(watchList)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		q        string
+		expected []int
+	}{
+		{"genre:action year:>1996 status:watched", []int{1}},
+		{"genre:action", []int{1, 2}},
+		{`genre:"science fiction" -status:dropped`, []int{1, 3}},
+		{"year:1995..2000", []int{1, 2}},
+		{"year:2000..", []int{3, 4}},
+		{"director:villeneuve OR tag:classics", []int{1, 3, 4}},
+		{"NOT (genre:crime OR director:villeneuve)", []int{1}},
+		{`title:"dune: part"`, []int{4}},
+		{"title:=heat", []int{2}},
+		{"runtime:>=120", []int{2}},
+		{"-runtime:>=120", []int{1, 3, 4}},
+		{"mann", []int{2}},
+		{"notes:50%", []int{3}},
+		{"notes:5_", []int{}},
+		{`status:"not watched" kind:movie`, []int{2}},
+		{"rating:>3", []int{}},
+		{"", []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			watchLists, err := repo.GetAllWatchList(models.WatchListQuery{Q: tt.q})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, watchListIds(watchLists))
+		})
+	}
+
+	// q is combined with the other filters and the status of the endpoint
+	watchLists, err := repo.GetWatchedList(models.WatchListQuery{Q: `genre:"science fiction"`, Sort: "-release_year"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 1}, watchListIds(watchLists))
+
+	_, err = repo.GetAllWatchList(models.WatchListQuery{Q: "genre:action year:>"})
+	var queryErr *query.Error
+	assert.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, 20, queryErr.Column)
+
+	_, err = repo.GetAllWatchList(models.WatchListQuery{Q: "year:>2000 colour:red"})
+	assert.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, 12, queryErr.Column)
+}
@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/database"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/rank"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/saketV8/cine-dots/tests/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func rankOrder(t *testing.T, repo *repositories.WatchListModel) []int {
+	watchLists, err := repo.GetAllWatchList(models.WatchListQuery{Sort: "rank"})
+	assert.NoError(t, err)
+
+	ids := []int{}
+	for _, watchList := range watchLists {
+		ids = append(ids, watchList.WatchlistID)
+	}
+	return ids
+}
+
+func anchor(id int) *int {
+	return &id
+}
+
+func TestMoveWatchList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	// adding an entry ranks the entries from before the ranks in ID order, the new one goes last
+	added, err := repo.AddWatchList(models.Watchlist{Title: "Coco", ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "not watched"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, added.Rank)
+	assert.Equal(t, []int{1, 2, 3, 4}, rankOrder(t, repo))
+
+	moved, err := repo.MoveWatchList("4", models.WatchListMoveRequest{Before: anchor(1)})
+	assert.NoError(t, err)
+	assert.Less(t, moved.Rank, added.Rank)
+	assert.Equal(t, []int{4, 1, 2, 3}, rankOrder(t, repo))
+
+	// the rank is not part of the content, a move keeps the version
+	assert.Equal(t, added.Version, moved.Version)
+
+	_, err = repo.MoveWatchList("3", models.WatchListMoveRequest{After: anchor(4), Before: anchor(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 3, 1, 2}, rankOrder(t, repo))
+
+	// only the moved entry changes its key
+	_, err = repo.MoveWatchList("2", models.WatchListMoveRequest{Before: anchor(3)})
+	assert.NoError(t, err)
+
+	entry, err := repo.GetWatchListById("4")
+	assert.NoError(t, err)
+	assert.Equal(t, moved.Rank, entry.Rank)
+	assert.Equal(t, []int{4, 2, 3, 1}, rankOrder(t, repo))
+
+	// anchors which are not next to each other mean the order of the client is stale
+	_, err = repo.MoveWatchList("1", models.WatchListMoveRequest{After: anchor(4), Before: anchor(3)})
+	assert.ErrorIs(t, err, repositories.ErrRankConflict)
+
+	_, err = repo.MoveWatchList("1", models.WatchListMoveRequest{After: anchor(1)})
+	assert.ErrorIs(t, err, repositories.ErrInvalidMove)
+
+	_, err = repo.MoveWatchList("1", models.WatchListMoveRequest{After: anchor(999)})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = repo.MoveWatchList("999", models.WatchListMoveRequest{After: anchor(1)})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// keys grow when entries keep going to the same place, rebalancing shortens them
+	for i := 0; i < 50; i++ {
+		from, to := "1", 2
+		if i%2 == 1 {
+			from, to = "2", 1
+		}
+		_, err = repo.MoveWatchList(from, models.WatchListMoveRequest{Before: anchor(to)})
+		assert.NoError(t, err)
+	}
+
+	versions := map[int]int{}
+	watchLists, err := repo.GetAllWatchList(models.WatchListQuery{Sort: "rank"})
+	assert.NoError(t, err)
+	for _, watchList := range watchLists {
+		versions[watchList.WatchlistID] = watchList.Version
+	}
+
+	order := rankOrder(t, repo)
+	rebalanced, err := repo.RebalanceRanks()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, rebalanced)
+	assert.Equal(t, order, rankOrder(t, repo))
+
+	// the keys are shorter, the versions are the same
+	watchLists, err = repo.GetAllWatchList(models.WatchListQuery{Sort: "rank"})
+	assert.NoError(t, err)
+	for _, watchList := range watchLists {
+		assert.LessOrEqual(t, len(watchList.Rank), 2)
+		assert.NoError(t, rank.Validate(watchList.Rank))
+		assert.Equal(t, versions[watchList.WatchlistID], watchList.Version)
+	}
+}
+
+func TestMoveWatchListConcurrently(t *testing.T) {
+	// a file database, so the moves really run on several connections
+	db, err := database.InitializeDatabase("sqlite3", "file:"+filepath.Join(t.TempDir(), "rank.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.DB.Close()
+
+	testutil.CreateSchema(t, db.DB)
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	for i := 1; i <= 20; i++ {
+		_, err := repo.AddWatchList(models.Watchlist{Title: "Movie " + strconv.Itoa(i), ReleaseYear: 2000 + i, Genre: "Drama", Director: "Someone", Status: "not watched"})
+		assert.NoError(t, err)
+	}
+
+	// every client moves its own entries to the front
+	var wg sync.WaitGroup
+	for client := 0; client < 4; client++ {
+		wg.Add(1)
+		go func(client int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				id := 1 + (client*5+i)%20
+				front := rankOrder(t, repo)[0]
+				if front == id {
+					continue
+				}
+				_, err := repo.MoveWatchList(strconv.Itoa(id), models.WatchListMoveRequest{Before: anchor(front)})
+				if err != nil {
+					assert.ErrorIs(t, err, repositories.ErrRankConflict)
+				}
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	// no entry was lost or shares its place
+	watchLists, err := repo.GetAllWatchList(models.WatchListQuery{Sort: "rank"})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, len(watchLists))
+
+	keys := map[string]bool{}
+	for i, watchList := range watchLists {
+		assert.False(t, keys[watchList.Rank], watchList.Rank)
+		keys[watchList.Rank] = true
+		if i > 0 {
+			assert.Less(t, watchLists[i-1].Rank, watchList.Rank)
+		}
+	}
+}
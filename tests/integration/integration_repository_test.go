@@ -2,6 +2,7 @@ package integration
 
 import (
 	"database/sql"
+	"strconv"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/saketV8/cine-dots/pkg/database"
 	"github.com/saketV8/cine-dots/pkg/models"
 	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/saketV8/cine-dots/tests/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,21 +21,7 @@ func setupTestDB(t *testing.T) *database.Database {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	createTableSQL := `
-    CREATE TABLE IF NOT EXISTS Watchlist (
-        watchlist_id INTEGER PRIMARY KEY AUTOINCREMENT,
-        title TEXT NOT NULL,
-        release_year INTEGER NOT NULL,
-        genre TEXT NOT NULL,
-        director TEXT NOT NULL,
-        status TEXT NOT NULL,
-        added_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-    );
-    `
-	_, err = db.DB.Exec(createTableSQL)
-	if err != nil {
-		t.Fatalf("Failed to create test table: %v", err)
-	}
+	testutil.CreateSchema(t, db.DB)
 
 	return db
 }
@@ -65,7 +53,7 @@ func TestGetAllWatchList(t *testing.T) {
 		DB: db.DB,
 	}
 
-	watchlists, err := repo.GetAllWatchList()
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(watchlists))
@@ -84,7 +72,7 @@ func TestGetWatchedList(t *testing.T) {
 		DB: db.DB,
 	}
 
-	watchlists, err := repo.GetWatchedList()
+	watchlists, err := repo.GetWatchedList(models.WatchListQuery{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(watchlists))
@@ -102,7 +90,7 @@ func TestGetWatchingList(t *testing.T) {
 		DB: db.DB,
 	}
 
-	watchlists, err := repo.GetWatchingList()
+	watchlists, err := repo.GetWatchingList(models.WatchListQuery{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(watchlists))
@@ -120,7 +108,7 @@ func TestGetNotWatchedList(t *testing.T) {
 		DB: db.DB,
 	}
 
-	watchlists, err := repo.GetNotWatchedList()
+	watchlists, err := repo.GetNotWatchedList(models.WatchListQuery{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(watchlists))
@@ -171,10 +159,25 @@ func TestAddWatchList(t *testing.T) {
 	assert.NotEqual(t, 0, added.WatchlistID)
 	assert.Equal(t, "New Test Movie", added.Title)
 
-	watchlists, err := repo.GetAllWatchList()
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(watchlists))
 	assert.Equal(t, "New Test Movie", watchlists[0].Title)
+	assert.True(t, added.AddedDate.Equal(watchlists[0].AddedDate))
+
+	// the added_date is stored, without one the entry is added today
+	older, err := repo.AddWatchList(models.Watchlist{Title: "Older Test Movie", ReleaseYear: 1999, Status: "not watched", AddedDate: time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	stored, err := repo.GetWatchListById(strconv.Itoa(older.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-06-20", stored.AddedDate.Format(time.DateOnly))
+
+	undated, err := repo.AddWatchList(models.Watchlist{Title: "Undated Test Movie", ReleaseYear: 2000, Status: "not watched"})
+	assert.NoError(t, err)
+	stored, err = repo.GetWatchListById(strconv.Itoa(undated.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), stored.AddedDate.Format(time.DateOnly))
+	assert.True(t, undated.AddedDate.Equal(stored.AddedDate))
 }
 
 func TestUpdateWatchList(t *testing.T) {
@@ -212,6 +215,7 @@ func TestUpdateWatchList(t *testing.T) {
 	nonExistentUpdate := models.WatchListUpdateRequest{
 		WatchlistID: 999,
 		Title:       "This should not update",
+		ReleaseYear: 2025,
 		Status:      "watched",
 	}
 
@@ -239,7 +243,7 @@ func TestDeleteWatchList(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, rowsAffected)
 
-	watchlists, err := repo.GetAllWatchList()
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(watchlists))
 
@@ -251,3 +255,94 @@ func TestDeleteWatchList(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, rowsAffected)
 }
+
+func TestWatchListExternalIds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.WatchListModel{
+		DB: db.DB,
+	}
+
+	added, err := repo.AddWatchList(models.Watchlist{
+		Title:       "Dune",
+		ReleaseYear: 2021,
+		Genre:       "Sci-Fi",
+		Director:    "Denis Villeneuve",
+		Status:      "watched",
+		ExternalIDs: models.ExternalIDs{IMDbID: "tt1160419", TMDbID: 438631},
+	})
+	assert.NoError(t, err)
+
+	found, err := repo.GetWatchListByExternalId("imdb", "tt1160419")
+	assert.NoError(t, err)
+	assert.Equal(t, added.WatchlistID, found.WatchlistID)
+	assert.Equal(t, 438631, found.ExternalIDs.TMDbID)
+
+	_, err = repo.GetWatchListByExternalId("imdb", "tt0000000")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// remakes share the title but not the release year
+	remake, err := repo.AddWatchList(models.Watchlist{
+		Title:       "Dune",
+		ReleaseYear: 1984,
+		Genre:       "Sci-Fi",
+		Director:    "David Lynch",
+		Status:      "not watched",
+		ExternalIDs: models.ExternalIDs{IMDbID: "tt0087182"},
+	})
+	assert.NoError(t, err)
+
+	// the same title and year is still rejected
+	_, err = repo.AddWatchList(models.Watchlist{Title: "Dune", ReleaseYear: 2021, Genre: "Sci-Fi", Director: "Denis Villeneuve", Status: "watched"})
+	assert.Error(t, err)
+
+	// an external ID belongs to a single entry
+	_, err = repo.AddWatchList(models.Watchlist{
+		Title:       "Dune: Part Two",
+		ReleaseYear: 2024,
+		Genre:       "Sci-Fi",
+		Director:    "Denis Villeneuve",
+		Status:      "not watched",
+		ExternalIDs: models.ExternalIDs{IMDbID: "tt1160419"},
+	})
+	assert.Error(t, err)
+
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchlists))
+
+	duplicate, isDuplicate, err := repo.FindDuplicateWatchList("dune", 1984, models.ExternalIDs{})
+	assert.NoError(t, err)
+	assert.True(t, isDuplicate)
+	assert.Equal(t, remake.WatchlistID, duplicate.WatchlistID)
+
+	// update only replaces the IDs which are sent
+	rowsAffected, err := repo.UpdateWatchList(models.WatchListUpdateRequest{
+		WatchlistID: remake.WatchlistID,
+		Title:       "Dune",
+		ReleaseYear: 1984,
+		Genre:       "Sci-Fi",
+		Director:    "David Lynch",
+		Status:      "watched",
+		ExternalIDs: &models.ExternalIDs{TMDbID: 841},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowsAffected)
+
+	updated, err := repo.GetWatchListById(strconv.Itoa(remake.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, "tt0087182", updated.ExternalIDs.IMDbID)
+	assert.Equal(t, 841, updated.ExternalIDs.TMDbID)
+
+	// purging a deleted entry removes its IDs
+	_, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: added.WatchlistID})
+	assert.NoError(t, err)
+	_, err = repo.PurgeTrash(0)
+	assert.NoError(t, err)
+
+	var count int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM watchlist_external_ids WHERE watchlist_id = ?;`, added.WatchlistID).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,230 @@
+package integration
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchListVersionSnapshots(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+	insertTestData(t, db.DB)
+
+	repo := (&repositories.WatchListModel{DB: db.DB}).As(models.Actor{Name: "saket"})
+
+	// entries from before the versions have none until their first write
+	versions, err := repo.GetWatchListVersions("2")
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	_, err = repo.UpdateWatchList(models.WatchListUpdateRequest{WatchlistID: 2, Title: "Test Movie 2", ReleaseYear: 2022, Genre: "Comedy, Romance", Director: "Director 2", Status: "watched", Tags: []string{"date night"}})
+	assert.NoError(t, err)
+
+	// the first write also keeps the content before it
+	versions, err = repo.GetWatchListVersions("2")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "", versions[0].Action)
+	assert.Equal(t, "Comedy", versions[0].Entry.Genre)
+	assert.Equal(t, 2, versions[1].Version)
+	assert.Equal(t, "update", versions[1].Action)
+	assert.Equal(t, "saket", versions[1].Actor)
+	assert.Equal(t, "Comedy, Romance", versions[1].Entry.Genre)
+	assert.Equal(t, []string{"date night"}, versions[1].Entry.Tags)
+
+	diff, err := repo.DiffWatchListVersions("2", 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, diff.WatchlistID)
+	assert.Equal(t, "watching", diff.Before["status"])
+	assert.Equal(t, "watched", diff.After["status"])
+	assert.Equal(t, []any{"date night"}, diff.After["tags"])
+	assert.NotContains(t, diff.Before, "title")
+
+	_, err = repo.DiffWatchListVersions("2", 1, 9)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = repo.GetWatchListVersions("99")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRevertWatchList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+	insertTestData(t, db.DB)
+
+	repo := (&repositories.WatchListModel{DB: db.DB}).As(models.Actor{Name: "saket"})
+
+	_, err := repo.UpdateWatchList(models.WatchListUpdateRequest{WatchlistID: 3, Title: "Test Movie 3 (Director's Cut)", ReleaseYear: 2023, Genre: "Drama", Director: "Director 3", Status: "watching", Tags: []string{"long"}})
+	assert.NoError(t, err)
+	_, err = repo.MoveWatchList("3", models.WatchListMoveRequest{Before: anchor(1)})
+	assert.NoError(t, err)
+	moved, err := repo.GetWatchListById("3")
+	assert.NoError(t, err)
+
+	// the old content comes back as a new version, the place in the order is kept
+	reverted, err := repo.RevertWatchList("3", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Movie 3", reverted.Title)
+	assert.Equal(t, "not watched", reverted.Status)
+	assert.Empty(t, reverted.Tags)
+	assert.Equal(t, moved.Version+1, reverted.Version)
+	assert.Equal(t, moved.Rank, reverted.Rank)
+
+	versions, err := repo.GetWatchListVersions("3")
+	assert.NoError(t, err)
+	last := versions[len(versions)-1]
+	assert.Equal(t, reverted.Version, last.Version)
+	assert.Equal(t, "revert", last.Action)
+
+	// reverting to the same content writes nothing
+	again, err := repo.RevertWatchList("3", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, reverted.Version, again.Version)
+
+	_, err = repo.RevertWatchList("3", 42)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// another entry took the title since
+	_, err = repo.UpdateWatchList(models.WatchListUpdateRequest{WatchlistID: 1, Title: "Test Movie 3 (Director's Cut)", ReleaseYear: 2023, Genre: "Action", Director: "Director 1", Status: "watched"})
+	assert.NoError(t, err)
+	_, err = repo.RevertWatchList("3", 2)
+	assert.ErrorIs(t, err, repositories.ErrWatchListExists)
+
+	// entries in the trash are not reverted
+	_, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 3})
+	assert.NoError(t, err)
+	_, err = repo.RevertWatchList("3", 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUndoWatchList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+	insertTestData(t, db.DB)
+
+	model := &repositories.WatchListModel{DB: db.DB}
+	as := func(token string) repositories.WatchListModelInterface {
+		return model.As(models.Actor{Name: "saket", UndoToken: token})
+	}
+
+	// an update is undone with its token
+	_, err := as("update-1").UpdateWatchList(models.WatchListUpdateRequest{WatchlistID: 1, Title: "Test Movie 1", ReleaseYear: 2021, Genre: "Action, Thriller", Director: "Director 1", Status: "watched"})
+	assert.NoError(t, err)
+	undone, err := as("undo-1").UndoWatchList("update-1")
+	assert.NoError(t, err)
+	assert.Len(t, undone, 1)
+	assert.Equal(t, "Action", undone[0].Genre)
+
+	// the token can not undo the entry again once the undo wrote it
+	_, err = as("undo-2").UndoWatchList("update-1")
+	assert.ErrorIs(t, err, repositories.ErrUndoConflict)
+
+	// the undo has its own token
+	undone, err = as("undo-3").UndoWatchList("undo-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Action, Thriller", undone[0].Genre)
+
+	// a created entry goes to the trash
+	_, err = as("create-1").AddWatchList(models.Watchlist{Title: "Coco", ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "not watched"})
+	assert.NoError(t, err)
+	undone, err = as("undo-4").UndoWatchList("create-1")
+	assert.NoError(t, err)
+	assert.NotNil(t, undone[0].DeletedAt)
+	assert.Equal(t, 3, countWatchLists(t, db.DB))
+
+	// a deleted entry comes back
+	_, err = as("delete-1").DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 2})
+	assert.NoError(t, err)
+	undone, err = as("undo-5").UndoWatchList("delete-1")
+	assert.NoError(t, err)
+	assert.Nil(t, undone[0].DeletedAt)
+	assert.Equal(t, 3, countWatchLists(t, db.DB))
+
+	// the writes of a batch are undone together
+	_, _, err = as("batch-1").BatchWatchList([]models.WatchListBatchWrite{
+		{Op: "delete", Delete: models.WatchListDeleteRequest{WatchlistID: 3}},
+		{Op: "create", Create: models.Watchlist{Title: "Up", ReleaseYear: 2009, Genre: "Animation", Director: "Pete Docter", Status: "not watched"}},
+	}, true)
+	assert.NoError(t, err)
+	undone, err = as("undo-6").UndoWatchList("batch-1")
+	assert.NoError(t, err)
+	assert.Len(t, undone, 2)
+	assert.Equal(t, 3, countWatchLists(t, db.DB))
+	_, err = model.GetWatchListById("3")
+	assert.NoError(t, err)
+
+	_, err = as("undo-7").UndoWatchList("unknown")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the token expires after the undo TTL
+	expiring := &repositories.WatchListModel{DB: db.DB, UndoTTL: time.Millisecond}
+	_, err = expiring.As(models.Actor{UndoToken: "update-2"}).TransitionWatchList("1", "watching", time.Now())
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = expiring.UndoWatchList("update-2")
+	assert.ErrorIs(t, err, repositories.ErrUndoExpired)
+}
+
+func TestPurgeDeletesVersions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+	_, err := repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 1})
+	assert.NoError(t, err)
+	_, err = repo.PurgeTrash(0)
+	assert.NoError(t, err)
+
+	var count int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM watchlist_versions WHERE watchlist_id = 1;`).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestRelatedWritesVersions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+	insertTestData(t, db.DB)
+
+	model := &repositories.WatchListModel{DB: db.DB}
+	actor := func(token string) models.Actor {
+		return models.Actor{Name: "saket", UndoToken: token}
+	}
+
+	// every version of an entry is kept, the writes of its review and tags and a write which changed nothing too
+	_, err := (&repositories.ReviewModel{DB: db.DB}).As(actor("review-1")).AddReview("1", models.ReviewRequest{Rating: 4})
+	assert.NoError(t, err)
+	_, err = (&repositories.TagModel{DB: db.DB}).As(actor("tag-1")).TagWatchLists([]int{1}, []string{"cozy"})
+	assert.NoError(t, err)
+	_, err = model.As(actor("update-1")).UpdateWatchList(models.WatchListUpdateRequest{WatchlistID: 1, Title: "Test Movie 1", ReleaseYear: 2021, Genre: "Action", Director: "Director 1", Status: "watched"})
+	assert.NoError(t, err)
+
+	entry, err := model.GetWatchListById("1")
+	assert.NoError(t, err)
+	versions, err := model.GetWatchListVersions("1")
+	assert.NoError(t, err)
+	for i, version := range versions {
+		assert.Equal(t, i+1, version.Version)
+	}
+	assert.Equal(t, entry.Version, versions[len(versions)-1].Version)
+	assert.Equal(t, "review", versions[1].Action)
+	assert.Equal(t, "tag", versions[2].Action)
+
+	// the write of the tags is undone with its token, a later write of the entry is a conflict
+	_, err = (&repositories.TagModel{DB: db.DB}).As(actor("tag-2")).TagWatchLists([]int{2}, []string{"rainy day"})
+	assert.NoError(t, err)
+	undone, err := model.As(actor("undo-1")).UndoWatchList("tag-2")
+	assert.NoError(t, err)
+	assert.Len(t, undone, 1)
+	assert.Empty(t, undone[0].Tags)
+
+	_, err = model.As(actor("undo-2")).UndoWatchList("tag-1")
+	assert.ErrorIs(t, err, repositories.ErrUndoConflict)
+}
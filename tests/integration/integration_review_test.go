@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.ReviewModel{DB: db.DB}
+
+	review, err := repo.AddReview("1", models.ReviewRequest{Rating: 4.5, Text: "Loved it"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, review.WatchlistID)
+	assert.Equal(t, 4.5, review.Rating)
+	assert.Equal(t, 0, len(review.History))
+
+	_, err = repo.AddReview("1", models.ReviewRequest{Rating: 3})
+	assert.ErrorIs(t, err, repositories.ErrReviewExists)
+
+	_, err = repo.AddReview("999", models.ReviewRequest{Rating: 3})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// only half stars are stored
+	_, err = repo.AddReview("2", models.ReviewRequest{Rating: 4.3})
+	assert.Error(t, err)
+
+	review, err = repo.UpdateReview("1", models.ReviewRequest{Rating: 3.5, Text: "Less good the second time", Spoiler: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.5, review.Rating)
+	assert.True(t, review.Spoiler)
+	assert.Equal(t, 1, len(review.History))
+	assert.Equal(t, 4.5, review.History[0].Rating)
+	assert.Equal(t, "Loved it", review.History[0].Text)
+
+	_, err = repo.UpdateReview("2", models.ReviewRequest{Rating: 3})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	rowsAffected, err := repo.DeleteReview("1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowsAffected)
+
+	_, err = repo.GetReview("1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	var revisions int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM review_revisions;`).Scan(&revisions)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, revisions)
+}
+
+func TestWatchListSortAndFilterByRating(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	reviewRepo := &repositories.ReviewModel{DB: db.DB}
+	_, err := reviewRepo.AddReview("1", models.ReviewRequest{Rating: 2})
+	assert.NoError(t, err)
+	_, err = reviewRepo.AddReview("3", models.ReviewRequest{Rating: 5})
+	assert.NoError(t, err)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{Sort: "-rating"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(watchlists))
+	assert.Equal(t, "Test Movie 3", watchlists[0].Title)
+	assert.Equal(t, 5.0, *watchlists[0].AverageRating)
+	assert.Equal(t, 1, watchlists[0].RatingCount)
+	assert.Equal(t, "Test Movie 1", watchlists[1].Title)
+	// unrated entries come last
+	assert.Equal(t, "Test Movie 2", watchlists[2].Title)
+	assert.Nil(t, watchlists[2].AverageRating)
+	assert.Equal(t, 0, watchlists[2].RatingCount)
+
+	watchlists, err = repo.GetAllWatchList(models.WatchListQuery{Sort: "rating"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Movie 1", watchlists[0].Title)
+	assert.Equal(t, "Test Movie 2", watchlists[2].Title)
+
+	watchlists, err = repo.GetAllWatchList(models.WatchListQuery{MinRating: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(watchlists))
+	assert.Equal(t, "Test Movie 3", watchlists[0].Title)
+
+	watchlists, err = repo.GetWatchedList(models.WatchListQuery{MaxRating: 2.5})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(watchlists))
+	assert.Equal(t, "Test Movie 1", watchlists[0].Title)
+
+	watchlists, err = repo.GetAllWatchList(models.WatchListQuery{Sort: "-title"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Movie 3", watchlists[0].Title)
+}
@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesSeasonsAndProgress(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	watchListRepo := &repositories.WatchListModel{DB: db.DB}
+	repo := &repositories.SeriesModel{DB: db.DB}
+
+	series, err := watchListRepo.AddWatchList(models.Watchlist{
+		Title:       "Breaking Bad",
+		ReleaseYear: 2008,
+		Genre:       "Drama",
+		Director:    "Vince Gilligan",
+		Status:      "not watched",
+		Kind:        "series",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "series", series.Kind)
+	id := strconv.Itoa(series.WatchlistID)
+
+	season, err := repo.SaveSeason(id, 1, models.SeasonRequest{
+		Title:        "Season 1",
+		EpisodeCount: 7,
+		Episodes:     []models.EpisodeRequest{{EpisodeNumber: 1, Title: "Pilot"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, len(season.Episodes))
+	assert.Equal(t, "Pilot", season.Episodes[0].Title)
+
+	_, err = repo.SaveSeason(id, 2, models.SeasonRequest{EpisodeCount: 3})
+	assert.NoError(t, err)
+
+	// movies have no seasons and no progress
+	_, err = repo.SaveSeason("1", 1, models.SeasonRequest{EpisodeCount: 1})
+	assert.ErrorIs(t, err, repositories.ErrNotEpisodic)
+	movie, err := watchListRepo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "movie", movie.Kind)
+	assert.Nil(t, movie.Progress)
+
+	watched := time.Date(2025, 1, 10, 21, 0, 0, 0, time.UTC)
+	watchList, err := repo.MarkEpisodes(id, 1, models.EpisodeRangeRequest{From: 1, To: 4, At: &watched}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.True(t, watchList.StartedAt.Equal(watched))
+	assert.Equal(t, 10, watchList.Progress.EpisodeCount)
+	assert.Equal(t, 4, watchList.Progress.WatchedCount)
+	assert.Equal(t, 40.0, watchList.Progress.Percent)
+	assert.Equal(t, 1, watchList.Progress.NextEpisode.SeasonNumber)
+	assert.Equal(t, 5, watchList.Progress.NextEpisode.EpisodeNumber)
+
+	// extending a season keeps the watched episodes
+	season, err = repo.SaveSeason(id, 1, models.SeasonRequest{EpisodeCount: 8})
+	assert.NoError(t, err)
+	assert.Equal(t, "Season 1", season.Title)
+	assert.Equal(t, 8, len(season.Episodes))
+	assert.True(t, season.Episodes[3].WatchedAt.Equal(watched))
+	assert.Nil(t, season.Episodes[4].WatchedAt)
+
+	// the whole season, then the last one finishes the series
+	_, err = repo.MarkEpisodes(id, 1, models.EpisodeRangeRequest{}, true)
+	assert.NoError(t, err)
+	watchList, err = repo.MarkEpisodes(id, 2, models.EpisodeRangeRequest{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchList.Status)
+	assert.Equal(t, 100.0, watchList.Progress.Percent)
+	assert.Nil(t, watchList.Progress.NextEpisode)
+
+	watchList, err = repo.MarkEpisodes(id, 2, models.EpisodeRangeRequest{From: 3}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, watchList.Progress.WatchedCount)
+	assert.Equal(t, 3, watchList.Progress.NextEpisode.EpisodeNumber)
+
+	_, err = repo.MarkEpisodes(id, 9, models.EpisodeRangeRequest{}, true)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	seasons, err := repo.GetSeasons(id)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(seasons))
+	assert.Equal(t, 2, seasons[1].SeasonNumber)
+
+	rowAffected, err := repo.DeleteSeason(id, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	watchList, err = watchListRepo.GetWatchListById(id)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, watchList.Progress.EpisodeCount)
+
+	// purging the deleted entry removes its seasons and episodes
+	_, err = watchListRepo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: series.WatchlistID})
+	assert.NoError(t, err)
+	_, err = watchListRepo.PurgeTrash(0)
+	assert.NoError(t, err)
+
+	var episodeCount int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM episodes;`).Scan(&episodeCount)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, episodeCount)
+
+	_, err = repo.GetSeasons(id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchListStatusLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	started := time.Date(2025, 1, 10, 21, 0, 0, 0, time.UTC)
+	paused := time.Date(2025, 1, 12, 20, 0, 0, 0, time.UTC)
+	resumed := time.Date(2025, 2, 1, 20, 0, 0, 0, time.UTC)
+	finished := time.Date(2025, 2, 2, 22, 0, 0, 0, time.UTC)
+
+	watchList, err := repo.TransitionWatchList("3", "watching", started)
+	assert.NoError(t, err)
+	assert.Equal(t, "watching", watchList.Status)
+	assert.True(t, watchList.StartedAt.Equal(started))
+	assert.Nil(t, watchList.FinishedAt)
+	assert.True(t, watchList.StatusChangedAt.Equal(started))
+
+	// resuming from on hold keeps the start
+	_, err = repo.TransitionWatchList("3", "on hold", paused)
+	assert.NoError(t, err)
+	watchList, err = repo.TransitionWatchList("3", "watching", resumed)
+	assert.NoError(t, err)
+	assert.True(t, watchList.StartedAt.Equal(started))
+	assert.True(t, watchList.StatusChangedAt.Equal(resumed))
+
+	watchList, err = repo.TransitionWatchList("3", "watched", finished)
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchList.Status)
+	assert.True(t, watchList.StartedAt.Equal(started))
+	assert.True(t, watchList.FinishedAt.Equal(finished))
+
+	// staying on the same status changes nothing
+	watchList, err = repo.TransitionWatchList("3", "watched", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, watchList.StatusChangedAt.Equal(finished))
+
+	// watched only leads to a rewatch
+	_, err = repo.TransitionWatchList("3", "dropped", time.Now())
+	var transitionError *repositories.StatusTransitionError
+	assert.ErrorAs(t, err, &transitionError)
+	assert.Equal(t, "watched", transitionError.From)
+	assert.Equal(t, "dropped", transitionError.To)
+
+	// a rewatch starts over
+	watchList, err = repo.TransitionWatchList("3", "watching", resumed.AddDate(0, 1, 0))
+	assert.NoError(t, err)
+	assert.True(t, watchList.StartedAt.Equal(resumed.AddDate(0, 1, 0)))
+	assert.Nil(t, watchList.FinishedAt)
+
+	_, err = repo.TransitionWatchList("3", "finished", time.Now())
+	assert.ErrorIs(t, err, repositories.ErrInvalidStatus)
+
+	_, err = repo.TransitionWatchList("999", "watching", time.Now())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestWatchListStatusOnAddAndUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	added, err := repo.AddWatchList(models.Watchlist{
+		Title:       "Coco",
+		ReleaseYear: 2017,
+		Genre:       "Animation",
+		Director:    "Lee Unkrich",
+		Status:      "watched",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, added.StartedAt)
+	assert.NotNil(t, added.FinishedAt)
+	assert.NotNil(t, added.StatusChangedAt)
+
+	_, err = repo.AddWatchList(models.Watchlist{Title: "Up", ReleaseYear: 2009, Genre: "Animation", Director: "Pete Docter", Status: "finished"})
+	assert.ErrorIs(t, err, repositories.ErrInvalidStatus)
+
+	update := models.WatchListUpdateRequest{
+		WatchlistID: added.WatchlistID,
+		Title:       "Coco",
+		ReleaseYear: 2017,
+		Genre:       "Animation",
+		Director:    "Lee Unkrich",
+		Status:      "on hold",
+	}
+
+	// the update goes through the lifecycle too and changes nothing when refused
+	_, err = repo.UpdateWatchList(update)
+	var transitionError *repositories.StatusTransitionError
+	assert.ErrorAs(t, err, &transitionError)
+
+	update.Status = "watching"
+	update.Title = "Coco (2017)"
+	rowAffected, err := repo.UpdateWatchList(update)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	updated, err := repo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Coco (2017)", updated.Title)
+	assert.Equal(t, "watching", updated.Status)
+	assert.Nil(t, updated.FinishedAt)
+}
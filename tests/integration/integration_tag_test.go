@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagsAndNotes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	watchListRepo := &repositories.WatchListModel{DB: db.DB}
+	repo := &repositories.TagModel{DB: db.DB}
+
+	added, err := watchListRepo.AddWatchList(models.Watchlist{
+		Title:       "Coco",
+		ReleaseYear: 2017,
+		Genre:       "Animation",
+		Director:    "Lee Unkrich",
+		Status:      "not watched",
+		Tags:        []string{"with kids", "Date Night", "with kids "},
+		Notes:       "Recommended by **Priya**",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"with kids", "Date Night"}, added.Tags)
+
+	watchList, err := watchListRepo.GetWatchListById(strconv.Itoa(added.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Date Night", "with kids"}, watchList.Tags)
+	assert.Equal(t, "Recommended by **Priya**", watchList.Notes)
+
+	// bulk, names match case-insensitively and unknown entries are skipped
+	rowAffected, err := repo.TagWatchLists([]int{1, 2, 999}, []string{"date night", "rainy day"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, rowAffected)
+
+	rowAffected, err = repo.UntagWatchLists([]int{2}, []string{"Rainy Day"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	tags, err := repo.GetTags()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(tags))
+	assert.Equal(t, "Date Night", tags[0].Name)
+	assert.Equal(t, 3, tags[0].WatchListCount)
+	assert.Equal(t, "rainy day", tags[1].Name)
+	assert.Equal(t, 1, tags[1].WatchListCount)
+
+	// any and all filters
+	watchLists, err := watchListRepo.GetAllWatchList(models.WatchListQuery{Tags: "rainy day, with kids"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(watchLists))
+
+	watchLists, err = watchListRepo.GetAllWatchList(models.WatchListQuery{Tags: "date night,with kids", TagMatch: "all"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(watchLists))
+	assert.Equal(t, "Coco", watchLists[0].Title)
+
+	// rename and merge
+	_, err = repo.AddTag("DATE NIGHT")
+	assert.ErrorIs(t, err, repositories.ErrTagExists)
+
+	_, err = repo.RenameTag(strconv.Itoa(tags[1].TagID), "with kids")
+	assert.ErrorIs(t, err, repositories.ErrTagExists)
+
+	renamed, err := repo.RenameTag(strconv.Itoa(tags[1].TagID), "Rainy Day")
+	assert.NoError(t, err)
+	assert.Equal(t, "Rainy Day", renamed.Name)
+
+	_, err = repo.RenameTag("999", "nope")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	merged, err := repo.MergeTags(strconv.Itoa(tags[0].TagID), []int{tags[1].TagID, tags[2].TagID, tags[0].TagID})
+	assert.NoError(t, err)
+	assert.Equal(t, "Date Night", merged.Name)
+	assert.Equal(t, 3, merged.WatchListCount)
+
+	tags, err = repo.GetTags()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tags))
+
+	// updates keep the tags and notes unless sent
+	update := models.WatchListUpdateRequest{
+		WatchlistID: added.WatchlistID,
+		Title:       "Coco",
+		ReleaseYear: 2017,
+		Genre:       "Animation",
+		Director:    "Lee Unkrich",
+		Status:      "not watched",
+	}
+	_, err = watchListRepo.UpdateWatchList(update)
+	assert.NoError(t, err)
+
+	watchList, err = watchListRepo.GetWatchListById(strconv.Itoa(added.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Date Night"}, watchList.Tags)
+	assert.Equal(t, "Recommended by **Priya**", watchList.Notes)
+
+	notes := ""
+	update.Notes = &notes
+	update.Tags = []string{}
+	_, err = watchListRepo.UpdateWatchList(update)
+	assert.NoError(t, err)
+
+	watchList, err = watchListRepo.GetWatchListById(strconv.Itoa(added.WatchlistID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, watchList.Tags)
+	assert.Equal(t, "", watchList.Notes)
+
+	rowAffected, err = repo.DeleteTag(strconv.Itoa(tags[0].TagID))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowAffected)
+
+	watchLists, err = watchListRepo.GetAllWatchList(models.WatchListQuery{Tags: "date night"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(watchLists))
+}
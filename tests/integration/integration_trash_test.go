@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchListTrash(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	added, err := repo.AddWatchList(models.Watchlist{
+		Title: "Coco", ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "watched", AddedDate: time.Now(),
+		Tags:        []string{"with kids"},
+		ExternalIDs: models.ExternalIDs{IMDbID: "tt2380307"},
+	})
+	assert.NoError(t, err)
+	id := strconv.Itoa(added.WatchlistID)
+
+	// a deleted entry is hidden from every read but kept in the trash
+	rowsAffected, err := repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: added.WatchlistID})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowsAffected)
+
+	_, err = repo.GetWatchListById(id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.GetWatchListByExternalId("imdb", "tt2380307")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	all, err := repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	rowsAffected, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: added.WatchlistID})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rowsAffected)
+
+	trash, err := repo.GetTrash()
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+	assert.Equal(t, added.WatchlistID, trash[0].WatchlistID)
+	assert.NotNil(t, trash[0].DeletedAt)
+	assert.Equal(t, added.Version+1, trash[0].Version)
+
+	// it comes back with its relations
+	restored, err := repo.RestoreWatchList(id)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, []string{"with kids"}, restored.Tags)
+	assert.Equal(t, "tt2380307", restored.ExternalIDs.IMDbID)
+	assert.NotEmpty(t, restored.Rank)
+
+	_, err = repo.RestoreWatchList(id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a trashed title can be added again, then the trashed one can not be restored over it
+	_, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: added.WatchlistID})
+	assert.NoError(t, err)
+	again, err := repo.AddWatchList(models.Watchlist{
+		Title: "Coco", ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "not watched", AddedDate: time.Now(),
+		ExternalIDs: models.ExternalIDs{IMDbID: "tt2380307"},
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, added.WatchlistID, again.WatchlistID)
+
+	_, err = repo.RestoreWatchList(id)
+	assert.ErrorIs(t, err, repositories.ErrWatchListExists)
+
+	found, err := repo.GetWatchListByExternalId("imdb", "tt2380307")
+	assert.NoError(t, err)
+	assert.Equal(t, again.WatchlistID, found.WatchlistID)
+
+	// the purge only deletes the entries older than the retention
+	purged, err := repo.PurgeTrash(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	purged, err = repo.PurgeTrash(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	var count int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM Watchlist WHERE watchlist_id = ?;`, added.WatchlistID).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM watchlist_tags WHERE watchlist_id = ?;`, added.WatchlistID).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	trash, err = repo.GetTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+}
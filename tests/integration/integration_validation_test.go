@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchListValidation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{
+		DB: db.DB,
+	}
+
+	tests := []struct {
+		name      string
+		watchList models.Watchlist
+	}{
+		{"Year Before Cinema", models.Watchlist{Title: "Up", ReleaseYear: 1869, Genre: "Animation", Status: "not watched"}},
+		{"Year Far Ahead", models.Watchlist{Title: "Up", ReleaseYear: 3025, Genre: "Animation", Status: "not watched"}},
+		{"Missing Year", models.Watchlist{Title: "Up", Genre: "Animation", Status: "not watched"}},
+		{"Blank Title", models.Watchlist{Title: "   ", ReleaseYear: 2009, Genre: "Animation", Status: "not watched"}},
+		{"Title Too Long", models.Watchlist{Title: strings.Repeat("a", 301), ReleaseYear: 2009, Genre: "Animation", Status: "not watched"}},
+		{"Blank Tag", models.Watchlist{Title: "Up", ReleaseYear: 2009, Genre: "Animation", Status: "not watched", Tags: []string{" "}}},
+		{"Blank Credit", models.Watchlist{Title: "Up", ReleaseYear: 2009, Genre: "Animation", Status: "not watched", Credits: []models.Credit{{Name: "", Role: "director"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.AddWatchList(tt.watchList)
+			assert.ErrorIs(t, err, repositories.ErrInvalidWatchList)
+		})
+	}
+
+	// nothing was written
+	watchlists, err := repo.GetAllWatchList(models.WatchListQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(watchlists))
+
+	// the status check comes first and keeps its own error
+	_, err = repo.AddWatchList(models.Watchlist{Title: "Up", ReleaseYear: 0, Status: "finished"})
+	assert.ErrorIs(t, err, repositories.ErrInvalidStatus)
+
+	_, err = repo.UpdateWatchList(models.WatchListUpdateRequest{
+		WatchlistID: 1,
+		Title:       "Test Movie 1",
+		ReleaseYear: 20230,
+		Genre:       "Action",
+		Status:      "not watched",
+	})
+	assert.ErrorIs(t, err, repositories.ErrInvalidWatchList)
+
+	watchList, err := repo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2021, watchList.ReleaseYear)
+}
+
+func TestImportValidation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	items := []models.ImportItem{
+		{Title: "Coco", ReleaseYear: 2017, Status: "watched"},
+		{Title: "Up", ReleaseYear: 0, Status: "watched"},
+		{Title: " ", ReleaseYear: 2009, Status: "watched"},
+	}
+
+	job := runImportJob(t, db.DB, items)
+	assert.Equal(t, 1, job.Imported)
+	assert.Equal(t, 2, job.Failed)
+	if assert.Len(t, job.Errors, 2) {
+		assert.Equal(t, "Invalid WatchList Data: release_year is required", job.Errors[0].Details)
+		assert.Equal(t, "Invalid WatchList Data: title can not be blank", job.Errors[1].Details)
+	}
+}
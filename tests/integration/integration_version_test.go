@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchListVersions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+	tagRepo := &repositories.TagModel{DB: db.DB}
+	reviewRepo := &repositories.ReviewModel{DB: db.DB}
+
+	version := func(watchlist_id string) int {
+		watchList, err := repo.GetWatchListById(watchlist_id)
+		assert.NoError(t, err)
+		return watchList.Version
+	}
+
+	added, err := repo.AddWatchList(models.Watchlist{Title: "Coco", ReleaseYear: 2017, Genre: "Animation", Director: "Lee Unkrich", Status: "not watched"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, added.Version)
+	assert.Equal(t, 1, version("4"))
+	assert.Equal(t, 1, version("1"))
+
+	first, second, third := version("1"), version("2"), version("3")
+
+	// every write of an entry counts once
+	update := models.WatchListUpdateRequest{WatchlistID: 1, Title: "Test Movie 1", ReleaseYear: 2021, Genre: "Action, Thriller", Director: "Director 1", Status: "watched", Tags: []string{"heist"}}
+	_, err = repo.UpdateWatchList(update)
+	assert.NoError(t, err)
+	assert.Equal(t, first+1, version("1"))
+
+	_, err = repo.TransitionWatchList("2", "watched", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, second+1, version("2"))
+
+	_, err = reviewRepo.AddReview("2", models.ReviewRequest{Rating: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, second+2, version("2"))
+
+	_, err = tagRepo.TagWatchLists([]int{2, 3}, []string{"heist"})
+	assert.NoError(t, err)
+	assert.Equal(t, second+3, version("2"))
+	assert.Equal(t, third+1, version("3"))
+
+	// renaming a tag changes what its entries show
+	_, err = tagRepo.RenameTag("1", "Heist")
+	assert.NoError(t, err)
+	assert.Equal(t, first+2, version("1"))
+	assert.Equal(t, second+4, version("2"))
+	assert.Equal(t, third+2, version("3"))
+	assert.Equal(t, 1, version("4"))
+
+	// a replayed progress update changes nothing
+	position := 600
+	_, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: &position})
+	assert.NoError(t, err)
+	_, err = repo.UpdateProgress("3", models.ProgressRequest{PositionSeconds: &position})
+	assert.NoError(t, err)
+	assert.Equal(t, third+3, version("3"))
+}
+
+func TestWatchListVersionMismatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	update := models.WatchListUpdateRequest{WatchlistID: 1, Title: "Test Movie 1", ReleaseYear: 2021, Genre: "Action", Director: "Director 1", Status: "watched", Version: 1}
+	rowsAffected, err := repo.UpdateWatchList(update)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowsAffected)
+
+	// the same version again is stale, nothing is written
+	update.Title = "Lost Update"
+	_, err = repo.UpdateWatchList(update)
+	assert.ErrorIs(t, err, repositories.ErrVersionMismatch)
+
+	watchList, err := repo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Movie 1", watchList.Title)
+	assert.Equal(t, 2, watchList.Version)
+
+	_, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 1, Version: 1})
+	assert.ErrorIs(t, err, repositories.ErrVersionMismatch)
+
+	rowsAffected, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 1, Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rowsAffected)
+
+	// a missing entry is not a mismatch, there is nothing to write
+	rowsAffected, err = repo.DeleteWatchList(models.WatchListDeleteRequest{WatchlistID: 1, Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rowsAffected)
+}
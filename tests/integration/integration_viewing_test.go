@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewingsAndDiary(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	insertTestData(t, db.DB)
+
+	repo := &repositories.ViewingModel{DB: db.DB}
+	watchListRepo := &repositories.WatchListModel{DB: db.DB}
+
+	first := time.Date(2025, 1, 10, 21, 0, 0, 0, time.UTC)
+	second := time.Date(2025, 3, 2, 20, 30, 0, 0, time.UTC)
+	rating := 4.5
+
+	// logged out of order, the older viewing is still the first watch
+	viewing, err := repo.AddViewing("3", models.ViewingRequest{WatchedOn: &second, Rating: &rating, Platform: "Netflix"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Movie 3", viewing.Title)
+	assert.False(t, viewing.Rewatch)
+
+	_, err = repo.AddViewing("3", models.ViewingRequest{WatchedOn: &first, Location: "Cinema"})
+	assert.NoError(t, err)
+
+	viewings, err := repo.GetViewings("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(viewings))
+	assert.True(t, viewings[0].WatchedOn.Equal(first))
+	assert.False(t, viewings[0].Rewatch)
+	assert.Nil(t, viewings[0].Rating)
+	assert.True(t, viewings[1].Rewatch)
+	assert.Equal(t, 4.5, *viewings[1].Rating)
+
+	// the entry is watched now and counts the rewatch
+	watchList, err := watchListRepo.GetWatchListById("3")
+	assert.NoError(t, err)
+	assert.Equal(t, "watched", watchList.Status)
+	assert.Equal(t, 2, watchList.ViewingCount)
+	assert.Equal(t, 1, watchList.RewatchCount)
+
+	_, err = repo.AddViewing("999", models.ViewingRequest{})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// defaults to now
+	_, err = repo.AddViewing("1", models.ViewingRequest{})
+	assert.NoError(t, err)
+
+	diary, err := repo.GetDiary(time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(diary))
+	assert.Equal(t, "Test Movie 1", diary[0].Title)
+
+	diary, err = repo.GetDiary(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(diary))
+	assert.Equal(t, "Cinema", diary[0].Location)
+
+	diary, err = repo.GetDiary(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diary))
+}
+
+func TestUpdateWatchListKeepsAddedDate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.DB.Close()
+
+	added := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err := db.DB.Exec(`INSERT INTO Watchlist (title, release_year, genre, director, status, added_date) VALUES ('Old Entry', 2000, 'Drama', 'Someone', 'not watched', ?);`, added)
+	assert.NoError(t, err)
+
+	repo := &repositories.WatchListModel{DB: db.DB}
+
+	update := models.WatchListUpdateRequest{
+		WatchlistID: 1,
+		Title:       "Old Entry",
+		ReleaseYear: 2000,
+		Genre:       "Drama",
+		Director:    "Someone",
+		Status:      "watching",
+	}
+	_, err = repo.UpdateWatchList(update)
+	assert.NoError(t, err)
+
+	watchList, err := repo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.True(t, watchList.AddedDate.Equal(added))
+
+	// an explicit added_date is still applied
+	corrected := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	update.AddedDate = &corrected
+	_, err = repo.UpdateWatchList(update)
+	assert.NoError(t, err)
+
+	watchList, err = repo.GetWatchListById("1")
+	assert.NoError(t, err)
+	assert.True(t, watchList.AddedDate.Equal(corrected))
+}
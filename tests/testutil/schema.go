@@ -0,0 +1,82 @@
+package testutil
+
+import (
+	"database/sql"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+)
+
+// the goose dialect and logger are global
+var setupGoose sync.Once
+
+// MigrationsDir is the /migrations directory of the repository
+func MigrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "migrations")
+}
+
+// Migrate runs every migration on the test database like migrations/migration.go does, with the sample data
+func Migrate(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	setupGoose.Do(func() {
+		goose.SetLogger(goose.NopLogger())
+		err := goose.SetDialect("sqlite3")
+		if err != nil {
+			t.Fatalf("Failed to set Goose dialect: %v", err)
+		}
+	})
+
+	err := goose.Up(db, MigrationsDir())
+	if err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+}
+
+// CreateSchema runs every migration on the test database
+// then removes the sample data the migrations insert so the tests start with empty tables
+func CreateSchema(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	Migrate(t, db)
+
+	err := removeSampleData(db)
+	if err != nil {
+		t.Fatalf("Failed to remove the sample data: %v", err)
+	}
+}
+
+// removeSampleData empties the tables and resets their IDs, the goose version table is kept
+func removeSampleData(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT IN ('goose_db_version', 'sqlite_sequence') AND name NOT LIKE 'sqlite_%';`)
+	if err != nil {
+		return err
+	}
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		err = rows.Scan(&table)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		_, err = db.Exec(`DELETE FROM "` + table + `";`)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(`DELETE FROM sqlite_sequence;`)
+	return err
+}
@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/etag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagMatch(t *testing.T) {
+	assert.Equal(t, `"3"`, etag.Strong(3))
+	assert.Equal(t, `W/"3-ab"`, etag.Weak("3-ab"))
+
+	tests := []struct {
+		header string
+		tag    string
+		strong bool
+		weak   bool
+	}{
+		{`"3"`, `"3"`, true, true},
+		{`"2"`, `"3"`, false, false},
+		{`"1", "3"`, `"3"`, true, true},
+		{`*`, `"3"`, true, true},
+		{`W/"3"`, `"3"`, false, true},
+		{`"3"`, `W/"3"`, false, true},
+		{`W/"3-ab"`, `W/"3-ab"`, false, true},
+		{` "3" `, `"3"`, true, true},
+		{`3`, `"3"`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header+" "+tt.tag, func(t *testing.T) {
+			assert.Equal(t, tt.strong, etag.MatchStrong(tt.header, tt.tag))
+			assert.Equal(t, tt.weak, etag.MatchWeak(tt.header, tt.tag))
+		})
+	}
+}
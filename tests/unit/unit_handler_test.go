@@ -12,36 +12,50 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/saketV8/cine-dots/pkg/handlers"
+	"github.com/saketV8/cine-dots/pkg/middleware"
 	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/saketV8/cine-dots/pkg/repositories"
 	"github.com/stretchr/testify/assert"
 )
 
 // mockWatchListRepository is an in-memory mock that implements WatchListRepository.
 // It simulates a database by returning data or errors based on test scenarios.
 type mockWatchListRepository struct {
-	getAllFunc        func() ([]models.Watchlist, error)
-	getWatchedFunc    func() ([]models.Watchlist, error)
-	getWatchingFunc   func() ([]models.Watchlist, error)
-	getNotWatchedFunc func() ([]models.Watchlist, error)
-	getByIDFunc       func(string) (models.Watchlist, error)
-	addFunc           func(models.Watchlist) (models.Watchlist, error)
-	deleteFunc        func(models.WatchListDeleteRequest) (int, error)
-	updateFunc        func(models.WatchListUpdateRequest) (int, error)
+	getAllFunc          func() ([]models.Watchlist, error)
+	getWatchedFunc      func() ([]models.Watchlist, error)
+	getWatchingFunc     func() ([]models.Watchlist, error)
+	getNotWatchedFunc   func() ([]models.Watchlist, error)
+	getByIDFunc         func(string) (models.Watchlist, error)
+	getByExternalIDFunc func(string, string) (models.Watchlist, error)
+	addFunc             func(models.Watchlist) (models.Watchlist, error)
+	deleteFunc          func(models.WatchListDeleteRequest) (int, error)
+	updateFunc          func(models.WatchListUpdateRequest) (int, error)
+	transitionFunc      func(string, string, time.Time) (models.Watchlist, error)
+	progressFunc        func(string, models.ProgressRequest) (models.Watchlist, error)
+	moveFunc            func(string, models.WatchListMoveRequest) (models.Watchlist, error)
+	batchFunc           func([]models.WatchListBatchWrite, bool) ([]models.Watchlist, []error, error)
+	trashFunc           func() ([]models.Watchlist, error)
+	restoreFunc         func(string) (models.Watchlist, error)
+	versionsFunc        func(string) ([]models.WatchListVersion, error)
+	diffFunc            func(string, int, int) (models.WatchListVersionDiff, error)
+	revertFunc          func(string, int) (models.Watchlist, error)
+	undoFunc            func(string) ([]models.Watchlist, error)
 }
 
-func (m *mockWatchListRepository) GetAllWatchList() ([]models.Watchlist, error) {
+func (m *mockWatchListRepository) GetAllWatchList(query models.WatchListQuery) ([]models.Watchlist, error) {
 	return m.getAllFunc()
 }
 
-func (m *mockWatchListRepository) GetWatchedList() ([]models.Watchlist, error) {
+func (m *mockWatchListRepository) GetWatchedList(query models.WatchListQuery) ([]models.Watchlist, error) {
 	return m.getWatchedFunc()
 }
 
-func (m *mockWatchListRepository) GetWatchingList() ([]models.Watchlist, error) {
+func (m *mockWatchListRepository) GetWatchingList(query models.WatchListQuery) ([]models.Watchlist, error) {
 	return m.getWatchingFunc()
 }
 
-func (m *mockWatchListRepository) GetNotWatchedList() ([]models.Watchlist, error) {
+func (m *mockWatchListRepository) GetNotWatchedList(query models.WatchListQuery) ([]models.Watchlist, error) {
 	return m.getNotWatchedFunc()
 }
 
@@ -49,6 +63,10 @@ func (m *mockWatchListRepository) GetWatchListById(id string) (models.Watchlist,
 	return m.getByIDFunc(id)
 }
 
+func (m *mockWatchListRepository) GetWatchListByExternalId(provider string, id string) (models.Watchlist, error) {
+	return m.getByExternalIDFunc(provider, id)
+}
+
 func (m *mockWatchListRepository) AddWatchList(w models.Watchlist) (models.Watchlist, error) {
 	return m.addFunc(w)
 }
@@ -61,9 +79,54 @@ func (m *mockWatchListRepository) UpdateWatchList(req models.WatchListUpdateRequ
 	return m.updateFunc(req)
 }
 
+func (m *mockWatchListRepository) TransitionWatchList(id string, status string, at time.Time) (models.Watchlist, error) {
+	return m.transitionFunc(id, status, at)
+}
+
+func (m *mockWatchListRepository) UpdateProgress(id string, progress models.ProgressRequest) (models.Watchlist, error) {
+	return m.progressFunc(id, progress)
+}
+
+func (m *mockWatchListRepository) MoveWatchList(id string, move models.WatchListMoveRequest) (models.Watchlist, error) {
+	return m.moveFunc(id, move)
+}
+
+func (m *mockWatchListRepository) BatchWatchList(writes []models.WatchListBatchWrite, atomic bool) ([]models.Watchlist, []error, error) {
+	return m.batchFunc(writes, atomic)
+}
+
+func (m *mockWatchListRepository) GetTrash() ([]models.Watchlist, error) {
+	return m.trashFunc()
+}
+
+func (m *mockWatchListRepository) RestoreWatchList(id string) (models.Watchlist, error) {
+	return m.restoreFunc(id)
+}
+
+func (m *mockWatchListRepository) GetWatchListVersions(id string) ([]models.WatchListVersion, error) {
+	return m.versionsFunc(id)
+}
+
+func (m *mockWatchListRepository) DiffWatchListVersions(id string, from int, to int) (models.WatchListVersionDiff, error) {
+	return m.diffFunc(id, from, to)
+}
+
+func (m *mockWatchListRepository) RevertWatchList(id string, version int) (models.Watchlist, error) {
+	return m.revertFunc(id, version)
+}
+
+func (m *mockWatchListRepository) UndoWatchList(token string) ([]models.Watchlist, error) {
+	return m.undoFunc(token)
+}
+
+func (m *mockWatchListRepository) As(actor models.Actor) repositories.WatchListModelInterface {
+	return m
+}
+
 func setupTestRouter(handler *handlers.WatchListHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.CorrelationIDMiddleware(), middleware.ProblemMiddleware())
 
 	api := router.Group("/api/v1")
 	{
@@ -125,7 +188,8 @@ func TestGetAllWatchListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var data []models.Watchlist
 				_ = json.Unmarshal(resp.Body.Bytes(), &data)
@@ -178,7 +242,8 @@ func TestGetWatchedListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var data []models.Watchlist
 				_ = json.Unmarshal(resp.Body.Bytes(), &data)
@@ -231,7 +296,8 @@ func TestGetWatchingListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var data []models.Watchlist
 				_ = json.Unmarshal(resp.Body.Bytes(), &data)
@@ -284,7 +350,8 @@ func TestGetNotWatchedListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var data []models.Watchlist
 				_ = json.Unmarshal(resp.Body.Bytes(), &data)
@@ -338,7 +405,8 @@ func TestGetWatchListByIdHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var data models.Watchlist
 				_ = json.Unmarshal(resp.Body.Bytes(), &data)
@@ -419,7 +487,8 @@ func TestAddWatchListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var data models.Watchlist
 				_ = json.Unmarshal(resp.Body.Bytes(), &data)
@@ -460,6 +529,17 @@ func TestDeleteWatchListHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectError:    true,
 		},
+		{
+			name: "Not found - nothing deleted",
+			input: models.WatchListDeleteRequest{
+				WatchlistID: 404,
+			},
+			mockFunc: func(req models.WatchListDeleteRequest) (int, error) {
+				return 0, nil
+			},
+			expectedStatus: http.StatusNotFound,
+			expectError:    true,
+		},
 		{
 			name: "Database error",
 			input: models.WatchListDeleteRequest{
@@ -490,7 +570,8 @@ func TestDeleteWatchListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var success map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &success)
@@ -568,7 +649,8 @@ func TestUpdateWatchListHandler(t *testing.T) {
 			if tt.expectError {
 				var errResp map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &errResp)
-				assert.Contains(t, errResp, "error")
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, float64(tt.expectedStatus), errResp["status"])
 			} else {
 				var success map[string]interface{}
 				_ = json.Unmarshal(resp.Body.Bytes(), &success)
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemFrom(t *testing.T) {
+	notFound := problem.New(problem.NotFound, "WatchList not found")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected problem.Type
+		detail   string
+	}{
+		{"problem", notFound, problem.NotFound, "WatchList not found"},
+		{"wrapped problem", fmt.Errorf("loading: %w", notFound), problem.NotFound, "WatchList not found"},
+		{"missing row", fmt.Errorf("scan: %w", sql.ErrNoRows), problem.NotFound, "the resource does not exist"},
+		{"unknown", errors.New("disk I/O error"), problem.Internal, "an unexpected error occurred"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problem.From(tt.err)
+			assert.Equal(t, tt.expected, p.Type)
+			assert.Equal(t, tt.detail, p.Detail)
+		})
+	}
+}
+
+func TestProblemInvalid(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	p := problem.Invalid("Malformed JSON", syntaxErr)
+	assert.Equal(t, problem.BadRequest, p.Type)
+
+	p = problem.Invalid("Invalid move", errors.New("before or after is required"))
+	assert.Equal(t, problem.Validation, p.Type)
+	assert.Equal(t, "Invalid move: before or after is required", p.Detail)
+	assert.Nil(t, p.Fields)
+
+	p = problem.Invalid("Invalid WatchList Data", nil).WithStatus(http.StatusUnprocessableEntity)
+	assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+	assert.Equal(t, http.StatusBadRequest, p.Type.Status())
+}
+
+func TestProblemMarshal(t *testing.T) {
+	data, err := json.Marshal(problem.Problem{
+		Type:       problem.Conflict.URI(),
+		Title:      problem.Conflict.Title(),
+		Status:     http.StatusConflict,
+		Instance:   "/api/v1/watchlist/7/transition",
+		Extensions: map[string]any{"allowed": []string{"watching"}, "status": "ignored"},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "https://github.com/saketV8/cine-dots/blob/main/docs/problems.md#conflict",
+		"title": "Conflict",
+		"status": 409,
+		"instance": "/api/v1/watchlist/7/transition",
+		"correlation_id": "",
+		"allowed": ["watching"]
+	}`, string(data))
+}
@@ -0,0 +1,200 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/saketV8/cine-dots/pkg/query"
+	"github.com/stretchr/testify/assert"
+)
+
+var testQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"title":  {Kind: query.TextField, Column: "title"},
+		"status": {Kind: query.EnumField, Column: "status", Values: []string{"not watched", "watched"}},
+		"year":   {Kind: query.NumberField, Column: "year"},
+		"genre":  {Kind: query.MatchField, Condition: "id IN (SELECT id FROM genres WHERE name = ?)"},
+	},
+	Text: []string{"title", "director"},
+}
+
+func TestQueryParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"genre:action year:>2000 status:watched", "genre:action AND year:>2000 AND status:watched"},
+		{"Genre:Action", "genre:Action"},
+		{"year:1990..1999", "year:1990..1999"},
+		{"year:..1999 year:>=1950", "year:..1999 AND year:>=1950"},
+		{`status:"not watched"`, `status:"not watched"`},
+		{`"space opera" nolan`, `"space opera" AND nolan`},
+		{"a OR b c", "a OR b AND c"},
+		{"(a OR b) c", "(a OR b) AND c"},
+		{"a (b c)", "a AND (b AND c)"},
+		{"-status:dropped", "NOT status:dropped"},
+		{"NOT (a OR b)", "NOT (a OR b)"},
+		{"- a", `"-" AND a`},
+		{"or and", "or AND and"},
+		{`title:"say \"hi\""`, `title:"say \"hi\""`},
+		{"year:-5", `year:"-5"`},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := query.Parse(tt.input)
+			assert.NoError(t, err)
+			if tt.expected == "" {
+				assert.Nil(t, node)
+				return
+			}
+			assert.Equal(t, tt.expected, node.String())
+		})
+	}
+}
+
+func TestQueryParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		column  int
+		message string
+	}{
+		{"status:", 8, "a value is missing after status:"},
+		{"year:>= 2000", 8, "a value is missing after year:>="},
+		{`title:"star`, 7, "unterminated quoted text"},
+		{"(a OR b", 8, `expected ")" to close the "(" at column 1`},
+		{"a)", 2, `")" has no matching "("`},
+		{"a OR", 5, "unexpected end of query"},
+		{"AND a", 1, "expected a search term before AND"},
+		{":action", 1, "a field name is missing"},
+		{"()", 2, "empty parentheses"},
+		{"year:..", 6, "a range needs a start, an end or both"},
+		{"a\\b", 2, "a backslash can only be used inside quoted text"},
+		{"ü status:", 10, "a value is missing"},
+		{strings.Repeat("(", query.MaxDepth+1) + "a", query.MaxDepth + 1, "nested more than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := query.Parse(tt.input)
+
+			var queryErr *query.Error
+			if !assert.True(t, errors.As(err, &queryErr)) {
+				return
+			}
+			assert.Equal(t, tt.column, queryErr.Column)
+			assert.Contains(t, queryErr.Message, tt.message)
+		})
+	}
+}
+
+func TestQueryCompile(t *testing.T) {
+	tests := []struct {
+		input     string
+		condition string
+		args      []any
+	}{
+		{"year:>2000", "year > ?", []any{2000.0}},
+		{"year:2000", "year = ?", []any{2000.0}},
+		{"year:1990..1999", "(year >= ? AND year <= ?)", []any{1990.0, 1999.0}},
+		{`status:"NOT WATCHED"`, "status = ?", []any{"not watched"}},
+		{"title:50%", `title LIKE ? ESCAPE '\'`, []any{`%50\%%`}},
+		{"title:=Heat", "title = ? COLLATE NOCASE", []any{"Heat"}},
+		{"genre:action OR -year:<2000", "(id IN (SELECT id FROM genres WHERE name = ?) OR NOT IFNULL(year < ?, 0))", []any{"action", 2000.0}},
+		{"heat", `(title LIKE ? ESCAPE '\' OR director LIKE ? ESCAPE '\')`, []any{"%heat%", "%heat%"}},
+		{`year:1 OR "'; DROP TABLE Watchlist; --"`, `(year = ? OR (title LIKE ? ESCAPE '\' OR director LIKE ? ESCAPE '\'))`,
+			[]any{1.0, "%'; DROP TABLE Watchlist; --%", "%'; DROP TABLE Watchlist; --%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := query.Parse(tt.input)
+			assert.NoError(t, err)
+
+			condition, args, err := query.Compile(node, testQuerySchema)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.condition, condition)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestQueryCompileErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		column  int
+		message string
+	}{
+		{"rating:5", 1, `unknown field "rating", the fields are genre, status, title, year`},
+		{"a year:abc", 8, `year takes a number, "abc" is not one`},
+		{"year:NaN", 6, "takes a number"},
+		{"year:1990..x", 12, `"x" is not one`},
+		{"status:finished", 8, "status must be one of not watched, watched"},
+		{"title:>a", 7, "title can not be compared with >"},
+		{"genre:1..2", 7, "genre does not take a range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := query.Parse(tt.input)
+			assert.NoError(t, err)
+
+			_, _, err = query.Compile(node, testQuerySchema)
+
+			var queryErr *query.Error
+			if !assert.True(t, errors.As(err, &queryErr)) {
+				return
+			}
+			assert.Equal(t, tt.column, queryErr.Column)
+			assert.Contains(t, queryErr.Message, tt.message)
+		})
+	}
+}
+
+// FuzzQueryParse checks that any input parses or fails with a column inside the query,
+// that a parsed query reads back as the same tree and that every value is bound as an argument
+func FuzzQueryParse(f *testing.F) {
+	for _, seed := range []string{
+		"genre:action year:>2000 status:watched",
+		`(title:"star wars" OR year:1977..1983) -status:dropped`,
+		`NOT "a \"b\" c" OR d:=e AND f:<=-1.5`,
+		"year:..5 x:>..5 -(-a) ((b))",
+		`a:"" "" :b (c`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		node, err := query.Parse(input)
+		if err != nil {
+			var queryErr *query.Error
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("%q: error %v is not a *query.Error", input, err)
+			}
+			if queryErr.Column < 1 || queryErr.Column > utf8.RuneCountInString(input)+1 {
+				t.Fatalf("%q: column %d is outside of the query", input, queryErr.Column)
+			}
+			return
+		}
+		if node == nil {
+			return
+		}
+
+		printed := node.String()
+		reparsed, err := query.Parse(printed)
+		if err != nil {
+			t.Fatalf("%q printed as %q does not parse: %v", input, printed, err)
+		}
+		if reparsed.String() != printed {
+			t.Fatalf("%q printed as %q reads back as %q", input, printed, reparsed.String())
+		}
+
+		condition, args, err := query.Compile(node, testQuerySchema)
+		if err == nil && strings.Count(condition, "?") != len(args) {
+			t.Fatalf("%q compiled to %q with %d arguments", input, condition, len(args))
+		}
+	})
+}
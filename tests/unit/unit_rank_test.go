@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/saketV8/cine-dots/pkg/rank"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{"first key", "", "", "a0"},
+		{"append", "a0", "", "a1"},
+		{"append after the last digit", "az", "", "b00"},
+		{"prepend", "", "a0", "Zz"},
+		{"between integers", "a0", "a2", "a1"},
+		{"between consecutive integers", "a0", "a1", "a0V"},
+		{"between fractions", "a0V", "a1", "a0l"},
+		{"before a fraction", "a0", "a0V", "a0G"},
+		{"between a key and its fraction", "a1", "a1V", "a1G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := rank.Between(tt.a, tt.b)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, key)
+		})
+	}
+}
+
+func TestRankBetweenErrors(t *testing.T) {
+	_, err := rank.Between("a1", "a0")
+	assert.ErrorIs(t, err, rank.ErrOrder)
+
+	_, err = rank.Between("a1", "a1")
+	assert.ErrorIs(t, err, rank.ErrOrder)
+
+	for _, key := range []string{"a", "a00", "a1-", "!0", "A" + "00000000000000000000000000"} {
+		_, err = rank.Between(key, "")
+		assert.ErrorIs(t, err, rank.ErrInvalidKey, key)
+	}
+}
+
+func TestRankRandomInserts(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	keys := []string{}
+
+	for i := 0; i < 2000; i++ {
+		// insert anywhere, the start and the end included
+		at := random.Intn(len(keys) + 1)
+		before, after := "", ""
+		if at > 0 {
+			before = keys[at-1]
+		}
+		if at < len(keys) {
+			after = keys[at]
+		}
+
+		key, err := rank.Between(before, after)
+		assert.NoError(t, err)
+		assert.NoError(t, rank.Validate(key))
+		if before != "" {
+			assert.Less(t, before, key)
+		}
+		if after != "" {
+			assert.Less(t, key, after)
+		}
+
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+
+	assert.True(t, sort.StringsAreSorted(keys))
+}
+
+func TestRankAppendsStayShort(t *testing.T) {
+	key := ""
+	for i := 0; i < 10000; i++ {
+		next, err := rank.Between(key, "")
+		assert.NoError(t, err)
+		key = next
+	}
+	assert.LessOrEqual(t, len(key), 4)
+}
@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/saketV8/cine-dots/pkg/models"
+	"github.com/saketV8/cine-dots/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationRules(t *testing.T) {
+	valid := models.Watchlist{
+		Title:       "Coco",
+		ReleaseYear: 2017,
+		Genre:       "Animation",
+		Director:    "Lee Unkrich",
+		Status:      "watched",
+		AddedDate:   time.Now(),
+	}
+	assert.NoError(t, validation.Struct(valid))
+
+	nextYear := time.Now().Year() + 1
+	tests := []struct {
+		name    string
+		edit    func(watchList *models.Watchlist)
+		message string
+	}{
+		{"Status", func(w *models.Watchlist) { w.Status = "finished" }, "status must be one of not watched, watching, watched, on hold, dropped"},
+		{"First Year", func(w *models.Watchlist) { w.ReleaseYear = validation.MinReleaseYear }, ""},
+		{"Next Year", func(w *models.Watchlist) { w.ReleaseYear = nextYear }, ""},
+		{"Year Too Old", func(w *models.Watchlist) { w.ReleaseYear = 1869 }, "release_year must be between 1870 and " + strconv.Itoa(nextYear)},
+		{"Year Too New", func(w *models.Watchlist) { w.ReleaseYear = nextYear + 1 }, "release_year must be between 1870 and " + strconv.Itoa(nextYear)},
+		{"Blank Title", func(w *models.Watchlist) { w.Title = " \t\n" }, "title can not be blank"},
+		{"Long Title", func(w *models.Watchlist) { w.Title = strings.Repeat("a", 301) }, "title must be at most 300 characters"},
+		{"Too Many Tags", func(w *models.Watchlist) { w.Tags = make([]string, 51) }, "tags must be at most 50 items"},
+		{"Blank Genre", func(w *models.Watchlist) { w.Genres = []string{"Animation", ""} }, "genres[1] can not be blank"},
+		{"Credit", func(w *models.Watchlist) { w.Credits = []models.Credit{{Name: " ", Role: "director"}} }, "credits[0].name can not be blank"},
+		{"Genre Or Genres", func(w *models.Watchlist) { w.Genre = "" }, "genre is required without genres"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchList := valid
+			tt.edit(&watchList)
+			err := validation.Struct(watchList)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.message)
+
+			// the failed rules can still be read field by field
+			var validationErrors validator.ValidationErrors
+			assert.True(t, errors.As(err, &validationErrors))
+		})
+	}
+}
+
+func TestValidationExcept(t *testing.T) {
+	// the importer has no added_date, genre nor director
+	item := models.Watchlist{Title: "Coco", ReleaseYear: 2017, Status: "watched"}
+	assert.Error(t, validation.Struct(item))
+	assert.NoError(t, validation.Except(item, "AddedDate", "Genre", "Director"))
+
+	item.ExternalIDs.IMDbID = strings.Repeat("1", 21)
+	assert.EqualError(t, validation.Except(item, "AddedDate", "Genre", "Director"), "external_ids.imdb_id must be at most 20 characters")
+
+	// several failed rules are listed in the order of the fields
+	item = models.Watchlist{Title: "", ReleaseYear: 1000, Status: "watched"}
+	assert.EqualError(t, validation.Except(item, "AddedDate", "Genre", "Director"), "title is required, release_year must be between 1870 and "+strconv.Itoa(time.Now().Year()+1))
+}